@@ -14,6 +14,7 @@ var (
 )
 
 // Defaultable allows a configuration struct to set its own default values.
+// Load also calls it on nested structs, before their parent.
 type Defaultable interface {
 	SetDefaults()
 }
@@ -29,33 +30,32 @@ type Source interface {
 }
 
 // Load loads configuration from multiple sources and validates the result.
-// This is simpler than using a Loader struct for this straightforward operation.
-//
-//nolint:wrapcheck // Errors are already wrapped in sources.
+// SetDefaults is called first on every nested struct and non-nil pointer to struct implementing Defaultable,
+// then on the target. Nil pointers tagged `alloc:"true"` are allocated to receive their defaults.
+// Fields tagged `required:"true"` must be set by one of the sources or by SetDefaults,
+// and the constraints of validate tags must hold; see ValidateTags. Validate is called afterwards on every
+// nested struct implementing Validatable and finally on the target, prefixing failures with the field path.
+// Later sources replace slices and maps of earlier ones, unless a field selects another MergeStrategy.
+// Sources wrapped by Priority are reordered accordingly and sources wrapped by When may be skipped.
+// Use a Loader to customize conversion and struct tags.
 func Load(target Validatable, sources ...Source) error {
-	err := validatePointerToStruct(target)
-	if err != nil {
-		return err
-	}
-
-	// Apply defaults if the target implements Defaultable
-	if defaultable, ok := target.(Defaultable); ok {
-		defaultable.SetDefaults()
-	}
+	return (&Loader{}).Load(target, sources...)
+}
 
-	for _, s := range sources {
-		err = s.Load(target)
-		if err != nil {
-			return err
-		}
-	}
+// LoadNew allocates a configuration of type T, which must implement Validatable through its pointer,
+// and loads it like Load, e.g. cfg, err := config.LoadNew[Config](config.EnvSource{Prefix: "APP"}).
+func LoadNew[T any, P interface {
+	*T
+	Validatable
+}](sources ...Source) (*T, error) {
+	target := P(new(T))
 
-	err = target.Validate()
+	err := Load(target, sources...)
 	if err != nil {
-		return fmt.Errorf("%w: %w", ErrValidation, err)
+		return nil, err
 	}
 
-	return nil
+	return target, nil
 }
 
 // validatePointerToStruct ensures the target is a non-nil pointer to a struct.
@@ -1,8 +1,10 @@
 package config_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spacecafe/go-parts/pkg/config"
@@ -92,3 +94,161 @@ func TestLoad(t *testing.T) {
 	require.NoError(t, err)
 	assert.EqualExportedValues(t, &MockConfig{Name: "test-app", Port: 9090}, target)
 }
+
+func TestLoadNew(t *testing.T) {
+	t.Setenv("APP_NAME", "test-app")
+
+	target, err := config.LoadNew[MockConfig](config.EnvSource{Prefix: "APP"})
+	require.NoError(t, err)
+	assert.Equal(t, &MockConfig{Name: "test-app"}, target)
+
+	target, err = config.LoadNew[MockConfig](config.JSONSource{Path: "non-existent"})
+	require.ErrorIs(t, err, config.ErrConfigNotFound)
+	assert.Nil(t, target)
+}
+
+type RequiredConfig struct {
+	RequiredTLS
+
+	Database *RequiredDatabase
+	Name     string `required:"true"`
+	Level    string `env:"LOG_LEVEL" required:"true"`
+	Port     int
+}
+
+type RequiredDatabase struct {
+	Host string `required:"true"`
+}
+
+type RequiredTLS struct {
+	KeyFile string `required:"true"`
+}
+
+func (c *RequiredConfig) Validate() error {
+	return nil
+}
+
+func TestLoad_Required(t *testing.T) {
+	t.Setenv("APP_NAME", "test-app")
+
+	err := config.Load(&RequiredConfig{Database: &RequiredDatabase{}}, config.EnvSource{Prefix: "APP"})
+	require.ErrorIs(t, err, config.ErrValidation)
+	require.ErrorIs(t, err, config.ErrRequired)
+	assert.NotContains(t, err.Error(), "Name")
+	assert.Contains(t, err.Error(), "Level (env APP_LOG_LEVEL)")
+	assert.Contains(t, err.Error(), "Database.Host (env APP_DATABASE_HOST)")
+	assert.Contains(t, err.Error(), "RequiredTLS.KeyFile (env APP_KEY_FILE)")
+
+	t.Setenv("APP_LOG_LEVEL", "debug")
+	t.Setenv("APP_KEY_FILE", "key.pem")
+
+	// Required fields behind nil pointers are not checked.
+	err = config.Load(&RequiredConfig{}, config.EnvSource{Prefix: "APP"})
+	require.NoError(t, err)
+}
+
+type DefaultsConfig struct {
+	Admin    *DefaultsServer `alloc:"true"`
+	Metrics  *DefaultsServer
+	Shutdown *DefaultsShutdown
+	HTTP     DefaultsServer
+}
+
+type DefaultsServer struct {
+	Host string
+	Port int
+}
+
+type DefaultsShutdown struct {
+	Force bool
+}
+
+func (c *DefaultsConfig) SetDefaults() {
+	// Parents run after their children and may override their defaults.
+	c.HTTP.Port = 80
+}
+
+func (c *DefaultsConfig) Validate() error {
+	return nil
+}
+
+func (c *DefaultsServer) SetDefaults() {
+	c.Host = "localhost"
+	c.Port = 8080
+}
+
+func (c *DefaultsShutdown) SetDefaults() {
+	c.Force = true
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	t.Parallel()
+
+	target := &DefaultsConfig{Shutdown: &DefaultsShutdown{}}
+
+	err := config.Load(target)
+	require.NoError(t, err)
+	assert.Equal(t, &DefaultsConfig{
+		HTTP:     DefaultsServer{Host: "localhost", Port: 80},
+		Admin:    &DefaultsServer{Host: "localhost", Port: 8080},
+		Shutdown: &DefaultsShutdown{Force: true},
+	}, target)
+}
+
+var (
+	errInvalidPort = errors.New("invalid port")
+	errInvalidName = errors.New("invalid name")
+)
+
+type NestedConfig struct {
+	Admin   *NestedServer
+	Metrics *NestedServer
+
+	NestedName
+
+	HTTP NestedServer
+}
+
+type NestedName struct {
+	Name string
+}
+
+type NestedServer struct {
+	Port int
+}
+
+func (c *NestedConfig) Validate() error {
+	return c.NestedName.Validate()
+}
+
+func (c *NestedName) Validate() error {
+	if c.Name == "" {
+		return errInvalidName
+	}
+
+	return nil
+}
+
+func (c *NestedServer) Validate() error {
+	if c.Port <= 0 {
+		return errInvalidPort
+	}
+
+	return nil
+}
+
+func TestLoad_NestedValidate(t *testing.T) {
+	t.Parallel()
+
+	err := config.Load(&NestedConfig{Admin: &NestedServer{}})
+	require.ErrorIs(t, err, config.ErrValidation)
+	require.ErrorIs(t, err, errInvalidPort)
+	require.ErrorIs(t, err, errInvalidName)
+	assert.Contains(t, err.Error(), "HTTP: invalid port")
+	assert.Contains(t, err.Error(), "Admin: invalid port")
+	assert.NotContains(t, err.Error(), "Metrics")
+	assert.Equal(t, 1, strings.Count(err.Error(), errInvalidName.Error()), "embedded Validate is called once")
+
+	err = config.Load(&NestedConfig{NestedName: NestedName{Name: "app"}, HTTP: NestedServer{Port: 80}})
+	require.NoError(t, err)
+}
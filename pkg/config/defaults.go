@@ -0,0 +1,41 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// applyDefaults calls SetDefaults on the target and on every nested struct and non-nil pointer to struct
+// implementing Defaultable. Nested fields are initialized first, so a parent can override the defaults
+// of its children. Nil pointers tagged `alloc:"true"` are allocated before their defaults are applied.
+func applyDefaults(valueOf reflect.Value) {
+	typeOf := valueOf.Type()
+
+	for i := range valueOf.NumField() {
+		field := valueOf.Field(i)
+		fieldType := typeOf.Field(i)
+
+		if !fieldType.IsExported() || !isStructOrStructPtr(field.Type()) {
+			continue
+		}
+
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				alloc, _ := strconv.ParseBool(fieldType.Tag.Get("alloc"))
+				if !alloc || !field.CanSet() {
+					continue
+				}
+
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+
+			field = field.Elem()
+		}
+
+		applyDefaults(field)
+	}
+
+	if defaultable, ok := valueOf.Addr().Interface().(Defaultable); ok {
+		defaultable.SetDefaults()
+	}
+}
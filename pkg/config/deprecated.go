@@ -0,0 +1,74 @@
+package config
+
+import (
+	"reflect"
+)
+
+// deprecation describes a deprecated field found by migrateDeprecated.
+type deprecation struct {
+	field reflect.Value
+
+	// parent is the struct holding the field and its replacement.
+	parent reflect.Value
+
+	// path is the dotted path of the parent.
+	path string
+	name string
+	hint string
+}
+
+// migrateDeprecated warns about every non-zero field tagged `deprecated:"..."` after all sources were loaded.
+// If the tag names a field of the same struct and type, e.g. `deprecated:"Timeout"` on a renamed field,
+// the value is moved to that field unless it is set itself. Otherwise, the tag is logged as a hint.
+func (l *Loader) migrateDeprecated(valueOf reflect.Value, path string) {
+	typeOf := valueOf.Type()
+
+	for i := range valueOf.NumField() {
+		field := valueOf.Field(i)
+		fieldType := typeOf.Field(i)
+
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		if hint, ok := fieldType.Tag.Lookup("deprecated"); ok && !field.IsZero() {
+			l.migrateField(deprecation{field: field, parent: valueOf, path: path, name: fieldType.Name, hint: hint})
+		}
+
+		if field.Kind() == reflect.Ptr && !field.IsNil() {
+			field = field.Elem()
+		}
+
+		if field.Kind() == reflect.Struct {
+			l.migrateDeprecated(field, joinPath(path, fieldType.Name))
+		}
+	}
+}
+
+// migrateField moves the value of a deprecated field to its replacement in the parent and logs a warning.
+func (l *Loader) migrateField(deprecated deprecation) {
+	path := joinPath(deprecated.path, deprecated.name)
+
+	replacement := deprecated.parent.FieldByName(deprecated.hint)
+	if !replacement.IsValid() || !replacement.CanSet() || replacement.Type() != deprecated.field.Type() {
+		l.logger().Warn("deprecated configuration field", "field", path, "hint", deprecated.hint)
+
+		return
+	}
+
+	if replacement.IsZero() {
+		replacement.Set(deprecated.field)
+	}
+
+	l.logger().Warn("deprecated configuration field, use the replacement instead",
+		"field", path, "replacement", joinPath(deprecated.path, deprecated.hint))
+}
+
+// joinPath appends the name to the dotted path.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
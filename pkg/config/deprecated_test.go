@@ -0,0 +1,81 @@
+package config_test
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DeprecatedConfig struct {
+	Server   DeprecatedServer `json:"server"`
+	Name     string           `json:"name"`
+	Nickname string           `json:"nickname" deprecated:"Name"`
+	Timeout  time.Duration    `json:"timeout"  env_alias:"OLD_TIMEOUT, LEGACY_TIMEOUT"`
+	Delay    time.Duration    `json:"delay"    deprecated:"Timeout"`
+	Legacy   bool             `json:"legacy"   deprecated:"will be removed in v2"`
+}
+
+type DeprecatedServer struct {
+	Host    string `json:"host"`
+	Address string `json:"address" deprecated:"Host"`
+}
+
+func (c *DeprecatedConfig) Validate() error {
+	return nil
+}
+
+func TestLoad_Deprecated(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{
+		"name": "new",
+		"nickname": "old",
+		"delay": 5000000000,
+		"legacy": true,
+		"server": {"address": "db:5432"}
+	}`), 0o600))
+
+	t.Setenv("APP_LEGACY_TIMEOUT", "1s")
+
+	var logs bytes.Buffer
+
+	loader := &config.Loader{Log: slog.New(slog.NewTextHandler(&logs, nil))}
+
+	target := &DeprecatedConfig{}
+	err := loader.Load(target, config.JSONSource{Path: file})
+	require.NoError(t, err)
+	assert.Equal(t, &DeprecatedConfig{
+		Server:   DeprecatedServer{Host: "db:5432", Address: "db:5432"},
+		Timeout:  5 * time.Second,
+		Delay:    5 * time.Second,
+		Name:     "new",
+		Nickname: "old",
+		Legacy:   true,
+	}, target)
+	assert.Contains(t, logs.String(), "field=Delay replacement=Timeout")
+	assert.Contains(t, logs.String(), "field=Nickname replacement=Name")
+	assert.Contains(t, logs.String(), "field=Server.Address replacement=Server.Host")
+	assert.Contains(t, logs.String(), `field=Legacy hint="will be removed in v2"`)
+
+	// Aliases of environment variables are read if the variable itself is unset.
+	logs.Reset()
+
+	target = &DeprecatedConfig{}
+	err = loader.Load(target, config.EnvSource{Prefix: "APP", Strict: true})
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, target.Timeout)
+	assert.Contains(t, logs.String(), "variable=APP_LEGACY_TIMEOUT replacement=APP_TIMEOUT")
+
+	t.Setenv("APP_TIMEOUT", "2s")
+
+	target = &DeprecatedConfig{}
+	err = loader.Load(target, config.EnvSource{Prefix: "APP"})
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, target.Timeout)
+}
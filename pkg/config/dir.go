@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	_ Source       = (*DirSource)(nil)
+	_ loaderSource = (*DirSource)(nil)
+
+	// fileFormats maps file extensions to the sources loading them. Optional formats register
+	// themselves from the files behind their build tags.
+	//
+	//nolint:gochecknoglobals // Registry of file formats, extended only during initialization.
+	fileFormats = map[string]func(path string) Source{
+		".json": func(path string) Source { return JSONSource{Path: path} },
+	}
+)
+
+// DirSource loads all files of a directory in lexicographic order, conf.d style,
+// so later fragments override earlier ones, e.g. 10-defaults.json and 50-site.yaml.
+// The format is detected by the file extension: .json, and .yaml, .yml or .toml if built with their tags.
+// Slices and maps are combined according to their merge tags, see MergeStrategy.
+type DirSource struct {
+	// Path is the directory holding the fragments.
+	Path string
+
+	// Glob selects the files by name, e.g. "*.yaml". Matching files in an unsupported format are an error.
+	// Default is every file in a supported format.
+	Glob string
+}
+
+func (s DirSource) Load(target any) error {
+	return s.loadWith(target, &Loader{})
+}
+
+func (s DirSource) loadWith(target any, loader *Loader) error {
+	err := validatePointerToStruct(target)
+	if err != nil {
+		return err
+	}
+
+	// The entries are sorted by file name.
+	entries, err := os.ReadDir(s.Path)
+	if err != nil {
+		return fmt.Errorf("%w: read config directory: %w", ErrConfigNotFound, err)
+	}
+
+	var sources []Source
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		if s.Glob != "" {
+			matched, err := filepath.Match(s.Glob, entry.Name())
+			if err != nil {
+				return fmt.Errorf("%w: invalid glob %q: %w", ErrInvalidConfig, s.Glob, err)
+			}
+
+			if !matched {
+				continue
+			}
+		}
+
+		format, ok := fileFormats[strings.ToLower(filepath.Ext(entry.Name()))]
+		if !ok {
+			if s.Glob == "" {
+				continue
+			}
+
+			return fmt.Errorf("%w: unsupported format of config file %s", ErrInvalidConfig, entry.Name())
+		}
+
+		sources = append(sources, format(filepath.Join(s.Path, entry.Name())))
+	}
+
+	return loader.loadMerged(target, loader.Merge, sources)
+}
@@ -0,0 +1,79 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirSource_Load(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"20-site.json":     `{"port": 9090}`,
+		"10-defaults.json": `{"name": "default", "port": 8080}`,
+		"README.md":        `# not a config`,
+		"30-broken.txt":    `broken`,
+	}
+
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "40-nested.json"), 0o700))
+
+	tests := []struct {
+		want    *MockConfig
+		wantErr error
+		name    string
+		source  config.DirSource
+	}{
+		{
+			name:   "all supported files in order",
+			source: config.DirSource{Path: dir},
+			want:   &MockConfig{Name: "default", Port: 9090},
+		},
+		{
+			name:   "files matching the glob",
+			source: config.DirSource{Path: dir, Glob: "10-*"},
+			want:   &MockConfig{Name: "default", Port: 8080},
+		},
+		{
+			name:    "unsupported format matching the glob",
+			source:  config.DirSource{Path: dir, Glob: "30-*"},
+			wantErr: config.ErrInvalidConfig,
+		},
+		{
+			name:    "invalid glob",
+			source:  config.DirSource{Path: dir, Glob: "["},
+			wantErr: config.ErrInvalidConfig,
+		},
+		{
+			name:    "directory not found",
+			source:  config.DirSource{Path: filepath.Join(dir, "non-existent")},
+			wantErr: config.ErrConfigNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			target := &MockConfig{}
+
+			err := tt.source.Load(target)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, target)
+		})
+	}
+}
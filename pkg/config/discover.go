@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	_ Source       = (*DiscoveredSource)(nil)
+	_ loaderSource = (*DiscoveredSource)(nil)
+)
+
+// DiscoveredSource loads the first configuration file that exists among candidate paths.
+// The format is detected by the file extension like with DirSource.
+type DiscoveredSource struct {
+	// path is the candidate loaded by the last Load, see Path.
+	path string
+
+	// Paths are the candidate files in priority order.
+	Paths []string
+
+	// Optional lets Load succeed without loading anything if no candidate exists.
+	Optional bool
+
+	mutex sync.RWMutex
+}
+
+// DiscoverSource returns a DiscoveredSource searching the standard locations of the application, see DiscoveryPaths.
+func DiscoverSource(appName string) *DiscoveredSource {
+	return &DiscoveredSource{Paths: DiscoveryPaths(appName)}
+}
+
+// DiscoveryPaths returns the standard locations of configuration files of the application in priority order:
+// ./<app>.<ext> in the working directory, <config>/<app>/config.<ext> in the user configuration directory,
+// which is $XDG_CONFIG_HOME or ~/.config on Linux, and /etc/<app>/config.<ext>.
+// Every location is tried with the extensions of all supported formats in lexicographic order.
+func DiscoveryPaths(appName string) []string {
+	directories := [][2]string{{".", appName}}
+
+	if configDir, err := os.UserConfigDir(); err == nil {
+		directories = append(directories, [2]string{filepath.Join(configDir, appName), "config"})
+	}
+
+	directories = append(directories, [2]string{filepath.Join("/etc", appName), "config"})
+
+	extensions := slices.Sorted(maps.Keys(fileFormats))
+	paths := make([]string, 0, len(directories)*len(extensions))
+
+	for _, directory := range directories {
+		for _, extension := range extensions {
+			paths = append(paths, filepath.Join(directory[0], directory[1]+extension))
+		}
+	}
+
+	return paths
+}
+
+func (s *DiscoveredSource) Load(target any) error {
+	return s.loadWith(target, &Loader{})
+}
+
+// Path returns the file chosen by the last Load, or an empty string if none was found.
+func (s *DiscoveredSource) Path() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.path
+}
+
+// find returns the first candidate that is a regular file and the source loading it.
+func (s *DiscoveredSource) find() (string, Source, error) {
+	for _, path := range s.Paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		format, ok := fileFormats[strings.ToLower(filepath.Ext(path))]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: unsupported format of config file %s", ErrInvalidConfig, path)
+		}
+
+		return path, format(path), nil
+	}
+
+	if s.Optional {
+		return "", nil, nil
+	}
+
+	return "", nil, fmt.Errorf("%w: none of %s", ErrConfigNotFound, strings.Join(s.Paths, ", "))
+}
+
+//nolint:wrapcheck // Errors are already wrapped in sources.
+func (s *DiscoveredSource) loadWith(target any, loader *Loader) error {
+	err := validatePointerToStruct(target)
+	if err != nil {
+		return err
+	}
+
+	path, source, err := s.find()
+
+	s.mutex.Lock()
+	s.path = path
+	s.mutex.Unlock()
+
+	if err != nil || source == nil {
+		return err
+	}
+
+	return loader.load(source, target)
+}
@@ -0,0 +1,101 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoveredSource_Load(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"site.json":     `{"name": "site", "port": 9090}`,
+		"fallback.json": `{"name": "fallback"}`,
+		"config.txt":    `broken`,
+	}
+
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested.json"), 0o700))
+
+	missing := filepath.Join(dir, "missing.json")
+
+	tests := []struct {
+		want     *MockConfig
+		wantErr  error
+		name     string
+		wantPath string
+		paths    []string
+		optional bool
+	}{
+		{
+			name: "first existing candidate",
+			paths: []string{
+				missing,
+				filepath.Join(dir, "nested.json"),
+				filepath.Join(dir, "site.json"),
+				filepath.Join(dir, "fallback.json"),
+			},
+			want:     &MockConfig{Name: "site", Port: 9090},
+			wantPath: filepath.Join(dir, "site.json"),
+		},
+		{
+			name:     "no candidate",
+			paths:    []string{missing},
+			wantErr:  config.ErrConfigNotFound,
+			wantPath: "",
+		},
+		{
+			name:     "optional without candidate",
+			paths:    []string{missing},
+			optional: true,
+			want:     &MockConfig{},
+		},
+		{
+			name:     "unsupported format",
+			paths:    []string{filepath.Join(dir, "config.txt")},
+			wantErr:  config.ErrInvalidConfig,
+			wantPath: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			source := &config.DiscoveredSource{Paths: tt.paths, Optional: tt.optional}
+			target := &MockConfig{}
+
+			err := source.Load(target)
+			assert.Equal(t, tt.wantPath, source.Path())
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, target)
+		})
+	}
+}
+
+func TestDiscoveryPaths(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/home/user/.config")
+
+	paths := config.DiscoveryPaths("app")
+	assert.Subset(t, paths, []string{
+		"app.json",
+		filepath.Join("/home/user/.config", "app", "config.json"),
+		filepath.Join("/etc", "app", "config.json"),
+	})
+	assert.Equal(t, "app.json", paths[0])
+}
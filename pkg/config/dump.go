@@ -0,0 +1,234 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format names a serialization format of Dump.
+type Format string
+
+// RedactedValue replaces the values of secret fields in Dump and Redacted.
+const RedactedValue = "*****"
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("config: unsupported format")
+
+	// formatCodecs maps formats to their codecs. Optional formats register themselves
+	// from the files behind their build tags.
+	//
+	//nolint:gochecknoglobals // Registry of formats, extended only during initialization.
+	formatCodecs = map[Format]codec{FormatJSON: jsonCodec}
+)
+
+// dumpEntry is a named field of a dumped struct.
+type dumpEntry struct {
+	field reflect.Value
+	name  string
+
+	// secret is set for non-zero fields tagged `secret:"true"`, whose value is replaced by RedactedValue.
+	secret bool
+}
+
+// Dump serializes the configuration, e.g. to log the effective configuration after Load.
+// Fields keep their struct order and are named by the tag of the format, falling back to the shared config tag;
+// "-" and omitempty are honored. Durations are written in their human-readable form like "1m30s" and
+// nil pointers are omitted. Values of fields tagged `secret:"true"` are replaced by RedactedValue unless empty.
+// YAML and TOML are only available if built with their tags.
+func Dump(target any, format Format) ([]byte, error) {
+	return dump(target, format, string(format))
+}
+
+// dump implements Dump, naming the fields by the given tag instead of the native tag of the format.
+func dump(target any, format Format, tag string) ([]byte, error) {
+	formatCodec, ok := formatCodecs[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+
+	valueOf := reflect.Indirect(reflect.ValueOf(target))
+	if valueOf.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: target must be a struct or pointer to struct, got %T", ErrInvalidTarget, target)
+	}
+
+	data, err := formatCodec.marshal(dumpValue(valueOf, tag, formatCodec.tag).Interface())
+	if err != nil {
+		return nil, fmt.Errorf("%w: marshal %s: %w", ErrInvalidConfig, format, err)
+	}
+
+	if format == FormatJSON {
+		var indented bytes.Buffer
+
+		err = json.Indent(&indented, data, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("%w: indent JSON: %w", ErrInvalidConfig, err)
+		}
+
+		return indented.Bytes(), nil
+	}
+
+	return data, nil
+}
+
+// dumpValue converts the value into a form the encoders write in the desired order and notation.
+// Structs become anonymous structs with one field per entry, as all encoders keep the order of struct fields.
+// The entries are named by the struct tag key and carry their name in the native tag of the encoder.
+func dumpValue(valueOf reflect.Value, key, tag string) reflect.Value {
+	if valueOf.Kind() == reflect.Interface || valueOf.Kind() == reflect.Ptr {
+		if valueOf.IsNil() {
+			return reflect.Value{}
+		}
+
+		return dumpValue(valueOf.Elem(), key, tag)
+	}
+
+	if valueOf.Type() == reflect.TypeFor[time.Duration]() {
+		return reflect.ValueOf(time.Duration(valueOf.Int()).String())
+	}
+
+	if isDumpLeaf(valueOf.Type()) {
+		return valueOf
+	}
+
+	switch valueOf.Kind() {
+	case reflect.Struct:
+		return dumpStruct(dumpEntries(valueOf, key), key, tag)
+	case reflect.Slice, reflect.Array:
+		if valueOf.Kind() == reflect.Slice && valueOf.IsNil() {
+			return valueOf
+		}
+
+		elements := make([]any, valueOf.Len())
+		for i := range valueOf.Len() {
+			if elem := dumpValue(valueOf.Index(i), key, tag); elem.IsValid() {
+				elements[i] = elem.Interface()
+			}
+		}
+
+		return reflect.ValueOf(elements)
+	case reflect.Map:
+		if valueOf.IsNil() {
+			return valueOf
+		}
+
+		entries := make(map[string]any, valueOf.Len())
+		for iter := valueOf.MapRange(); iter.Next(); {
+			if elem := dumpValue(iter.Value(), key, tag); elem.IsValid() {
+				entries[fmt.Sprint(iter.Key().Interface())] = elem.Interface()
+			}
+		}
+
+		return reflect.ValueOf(entries)
+	default:
+		return valueOf
+	}
+}
+
+// dumpEntries returns the entries of the struct in field order, flattening embedded structs without a name.
+func dumpEntries(valueOf reflect.Value, tag string) []dumpEntry {
+	var entries []dumpEntry
+
+	typeOf := valueOf.Type()
+
+	for i := range valueOf.NumField() {
+		field := valueOf.Field(i)
+		fieldType := typeOf.Field(i)
+
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		name, _ := lookupTag(fieldType, tag)
+		if name == "-" {
+			continue
+		}
+
+		if fieldType.Anonymous && name == "" && reflect.Indirect(field).Kind() == reflect.Struct {
+			if field.Kind() != reflect.Ptr || !field.IsNil() {
+				entries = append(entries, dumpEntries(reflect.Indirect(field), tag)...)
+			}
+
+			continue
+		}
+
+		if name == "" {
+			name = fieldType.Name
+		}
+
+		if hasTagOption(fieldType, tag, "omitempty") && field.IsZero() {
+			continue
+		}
+
+		secret, _ := strconv.ParseBool(fieldType.Tag.Get("secret"))
+
+		entries = append(entries, dumpEntry{field: field, name: name, secret: secret && !field.IsZero()})
+	}
+
+	return entries
+}
+
+// dumpStruct builds an anonymous struct holding the entries in order, tagged for the format.
+func dumpStruct(entries []dumpEntry, key, tag string) reflect.Value {
+	var (
+		fields []reflect.StructField
+		values []reflect.Value
+	)
+
+	for _, entry := range entries {
+		value := reflect.ValueOf(RedactedValue)
+		if !entry.secret {
+			value = dumpValue(entry.field, key, tag)
+		}
+
+		if !value.IsValid() {
+			continue
+		}
+
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("F%d", len(fields)),
+			Type: reflect.TypeFor[any](),
+			Tag:  reflect.StructTag(fmt.Sprintf("%s:%q", tag, entry.name)),
+		})
+		values = append(values, value)
+	}
+
+	valueOf := reflect.New(reflect.StructOf(fields)).Elem()
+	for i, value := range values {
+		valueOf.Field(i).Set(value)
+	}
+
+	return valueOf
+}
+
+// hasTagOption reports whether the tag of the format, or the shared config tag, lists the option.
+func hasTagOption(field reflect.StructField, key, option string) bool {
+	tag, ok := field.Tag.Lookup(key)
+	if !ok {
+		tag = field.Tag.Get(TagName)
+	}
+
+	_, options, _ := strings.Cut(tag, ",")
+
+	return slices.Contains(strings.Split(options, ","), option)
+}
+
+// isDumpLeaf reports whether the type serializes itself and must not be taken apart.
+func isDumpLeaf(typeOf reflect.Type) bool {
+	return typeOf.Implements(reflect.TypeFor[encoding.TextMarshaler]()) ||
+		typeOf.Implements(reflect.TypeFor[json.Marshaler]()) ||
+		(typeOf.Kind() == reflect.Struct && !hasExportedFields(typeOf))
+}
@@ -0,0 +1,85 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DumpDatabase struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+type DumpEmbedded struct {
+	Region string `json:"region"`
+}
+
+type DumpConfig struct {
+	DumpEmbedded
+
+	Started   time.Time         `json:"started"`
+	Database  *DumpDatabase     `json:"database"`
+	Missing   *DumpDatabase     `json:"missing"`
+	Labels    map[string]string `json:"labels"`
+	Zeta      string            `json:"zeta"`
+	Alpha     string            `config:"alpha"`
+	Hidden    string            `json:"-"`
+	Optional  string            `json:"optional,omitempty"`
+	Upstreams []DumpDatabase    `json:"upstreams"`
+	Timeout   time.Duration     `json:"timeout"`
+}
+
+func testDumpConfig() *DumpConfig {
+	return &DumpConfig{
+		DumpEmbedded: DumpEmbedded{Region: "eu"},
+		Started:      time.Date(2024, time.May, 27, 7, 32, 0, 0, time.UTC),
+		Database:     &DumpDatabase{Host: "db", Port: 5432},
+		Labels:       map[string]string{"team": "core"},
+		Zeta:         "last",
+		Alpha:        "first",
+		Hidden:       "hidden",
+		Upstreams:    []DumpDatabase{{Host: "api", Port: 80}},
+		Timeout:      90 * time.Second,
+	}
+}
+
+func TestDump(t *testing.T) {
+	t.Parallel()
+
+	data, err := config.Dump(testDumpConfig(), config.FormatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "region": "eu",
+  "started": "2024-05-27T07:32:00Z",
+  "database": {
+    "host": "db",
+    "port": 5432
+  },
+  "labels": {
+    "team": "core"
+  },
+  "zeta": "last",
+  "alpha": "first",
+  "upstreams": [
+    {
+      "host": "api",
+      "port": 80
+    }
+  ],
+  "timeout": "1m30s"
+}`, string(data))
+}
+
+func TestDump_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := config.Dump(testDumpConfig(), config.Format("xml"))
+	require.ErrorIs(t, err, config.ErrUnsupportedFormat)
+
+	_, err = config.Dump("not a struct", config.FormatJSON)
+	require.ErrorIs(t, err, config.ErrInvalidTarget)
+}
@@ -3,44 +3,158 @@ package config
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path"
+	"path/filepath"
 	"reflect"
+	"runtime/debug"
+	"slices"
 	"strings"
 	"unicode"
-
-	"github.com/spacecafe/go-parts/pkg/typeconv"
 )
 
 var (
-	_ Source = (*EnvSource)(nil)
+	_ Source       = (*EnvSource)(nil)
+	_ loaderSource = (*EnvSource)(nil)
 
 	ErrConversion = errors.New("config: failed to convert environment variable to field type")
 )
 
 // EnvSource loads configuration from environment variables.
+// Variable names are taken from the env tag, falling back to the shared config tag.
+// Nested structs add their name as a segment, e.g. APP_DATABASE_HOST, except embedded structs without
+// an explicit name and fields tagged `env:",squash"`, whose fields are read like those of the parent.
+// Renamed variables can keep their old names for a transition with `env_alias:"OLD_NAME"`,
+// whose use is logged as a warning by the Log of the Loader.
+// The typeconv tags unit, layout and sep control how individual fields are parsed.
 type EnvSource struct {
+	// OnUnknown is called with the name of every variable starting with the prefix that matches no field,
+	// e.g. to warn about APP_PROT when APP_PORT was meant. Variables are only checked if a prefix is set.
+	OnUnknown func(name string)
+
+	// values replaces the environment if set, e.g. by the files of a SecretsDirSource.
+	values map[string]string
+
 	// Prefix is an optional application prefix for environment variables.
 	// If set to "APP", it will look for variables like "APP_DATABASE_HOST".
 	Prefix string
+
+	// AutoPrefix derives the prefix from the name of the executable if Prefix is empty, see DefaultPrefix.
+	AutoPrefix bool
+
+	// Strict rejects variables starting with the prefix that match no field instead of calling OnUnknown.
+	Strict bool
+}
+
+// EnvVar describes an environment variable that EnvSource reads.
+type EnvVar struct {
+	// Type is the type of the field the variable is converted into.
+	Type reflect.Type
+
+	// Name is the name of the variable including the prefix.
+	Name string
+
+	// Field is the dotted Go path of the field.
+	Field string
+
+	// Aliases are deprecated names that are read if the variable is unset, see the env_alias tag.
+	Aliases []string
+
+	// File reports whether the variable Name_FILE is honored, naming a file that holds the value.
+	File bool
+}
+
+// DefaultPrefix derives an environment variable prefix from the name of the executable, or from the path
+// of the main package if the name is unknown. Characters other than letters and digits become underscores
+// and a file extension such as .exe is dropped, e.g. "my-server" becomes "MY_SERVER".
+func DefaultPrefix() string {
+	name := ""
+	if len(os.Args) > 0 {
+		name = filepath.Base(os.Args[0])
+	}
+
+	if info, ok := debug.ReadBuildInfo(); (name == "" || name == ".") && ok {
+		name = path.Base(info.Path)
+	}
+
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+
+	sanitized := strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+
+		return '_'
+	}, name)
+
+	return strings.Trim(sanitized, "_")
 }
 
 func (s EnvSource) Load(target any) error {
-	err := validatePointerToStruct(target)
-	if err != nil {
-		return err
+	return s.loadWith(target, &Loader{})
+}
+
+// Describe returns every variable that Load reads for the configuration type of the target in field order,
+// including variables of nested structs that set the struct as a whole, e.g. to print them for --help-env.
+func (s EnvSource) Describe(target any) ([]EnvVar, error) {
+	typeOf := reflect.TypeOf(target)
+	if typeOf != nil && typeOf.Kind() == reflect.Ptr {
+		typeOf = typeOf.Elem()
 	}
 
-	valueOf := reflect.ValueOf(target).Elem()
+	if typeOf == nil || typeOf.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: target must be a struct or pointer to struct, got %T", ErrInvalidTarget, target)
+	}
 
-	return s.loadStruct(valueOf, strings.ToUpper(s.Prefix))
+	return describeEnv(typeOf, s.prefix(), "", "env", s.values == nil, map[reflect.Type]bool{}), nil
+}
+
+// checkUnknown reports the variables starting with the prefix that match no field of the struct type,
+// either by calling OnUnknown or, in strict mode, as an error.
+func (s EnvSource) checkUnknown(typeOf reflect.Type, prefix string, loader *Loader) error {
+	strict := s.Strict || loader.Strict
+	if prefix == "" || (!strict && s.OnUnknown == nil) {
+		return nil
+	}
+
+	known := map[string]bool{}
+	for _, envVar := range describeEnv(typeOf, prefix, "", loader.tag("env"), false, map[reflect.Type]bool{}) {
+		known[envVar.Name] = true
+
+		for _, alias := range envVar.Aliases {
+			known[alias] = true
+		}
+	}
+
+	var unknown []string
+
+	for _, name := range s.names() {
+		if !strings.HasPrefix(name, prefix+"_") || known[name] || known[strings.TrimSuffix(name, "_FILE")] {
+			continue
+		}
+
+		unknown = append(unknown, name)
+	}
+
+	slices.Sort(unknown)
+
+	if strict && len(unknown) > 0 {
+		return fmt.Errorf("%w: environment variables %s", ErrUnknownKey, strings.Join(unknown, ", "))
+	}
+
+	for _, name := range unknown {
+		s.OnUnknown(name)
+	}
+
+	return nil
 }
 
 // hasEnvWithPrefix checks if any environment variable with the given prefix exists.
 func (s EnvSource) hasEnvWithPrefix(prefix string) bool {
 	prefix += "_"
-	for _, env := range os.Environ() {
-		if strings.HasPrefix(env, prefix) {
+	for _, name := range s.names() {
+		if strings.HasPrefix(name, prefix) {
 			return true
 		}
 	}
@@ -49,7 +163,7 @@ func (s EnvSource) hasEnvWithPrefix(prefix string) bool {
 }
 
 // loadStruct recursively loads environment variables into struct fields.
-func (s EnvSource) loadStruct(valueOf reflect.Value, prefix string) error {
+func (s EnvSource) loadStruct(valueOf reflect.Value, prefix string, loader *Loader) error {
 	typeOf := valueOf.Type()
 
 	for i := range valueOf.NumField() {
@@ -61,18 +175,28 @@ func (s EnvSource) loadStruct(valueOf reflect.Value, prefix string) error {
 			continue
 		}
 
-		// Get the env tag
-		envTag := fieldType.Tag.Get("env")
+		// Get the env tag, falling back to the shared config tag
+		envTag, _ := lookupTag(fieldType, loader.tag("env"))
 		if envTag == "-" {
 			continue
 		}
 
+		// Flatten embedded structs into the namespace of the parent
+		if isSquashed(fieldType, envTag, loader.tag("env")) {
+			err := s.loadSquashed(field, prefix, loader)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
 		// Build the environment variable name
 		envName := createEnvName(prefix, fieldType.Name, envTag)
 
 		// Handle nested structs recursively
 		if field.Kind() == reflect.Struct {
-			err := s.loadStruct(field, envName)
+			err := s.loadStructValue(field, envName, loader)
 			if err != nil {
 				return err
 			}
@@ -84,12 +208,13 @@ func (s EnvSource) loadStruct(valueOf reflect.Value, prefix string) error {
 		//nolint:nestif // Required for optional nested struct initialization and loading.
 		if field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.Struct {
 			// Initialize nil pointer if environment variable exists
-			if s.hasEnvWithPrefix(envName) {
+			_, exists := s.lookup(envName)
+			if exists || s.hasEnvWithPrefix(envName) {
 				if field.IsNil() {
 					field.Set(reflect.New(field.Type().Elem()))
 				}
 
-				err := s.loadStruct(field.Elem(), envName)
+				err := s.loadStructValue(field.Elem(), envName, loader)
 				if err != nil {
 					return err
 				}
@@ -98,14 +223,18 @@ func (s EnvSource) loadStruct(valueOf reflect.Value, prefix string) error {
 			continue
 		}
 
-		// Load the environment variable value
-		envValue, exists := lookupEnv(envName)
+		// Load the environment variable value, falling back to deprecated aliases
+		envValue, exists := s.lookup(envName)
+		if !exists {
+			envValue, exists = s.lookupAlias(prefix, envName, fieldType, loader)
+		}
+
 		if !exists {
 			continue
 		}
 
-		// Set the field value
-		err := typeconv.Default.Convert(field, envValue)
+		// Set the field value, honoring per-field parsing tags like unit, layout and sep
+		err := loader.convert(field, envValue, fieldType.Tag)
 		if err != nil {
 			return fmt.Errorf("%w: %w", ErrConversion, err)
 		}
@@ -114,6 +243,190 @@ func (s EnvSource) loadStruct(valueOf reflect.Value, prefix string) error {
 	return nil
 }
 
+// loadSquashed loads a flattened struct or pointer to struct with the prefix of its parent.
+// A nil pointer is only initialized if any of its fields was set.
+func (s EnvSource) loadSquashed(field reflect.Value, prefix string, loader *Loader) error {
+	if field.Kind() == reflect.Struct {
+		return s.loadStruct(field, prefix, loader)
+	}
+
+	if !field.IsNil() {
+		return s.loadStruct(field.Elem(), prefix, loader)
+	}
+
+	value := reflect.New(field.Type().Elem())
+
+	err := s.loadStruct(value.Elem(), prefix, loader)
+	if err != nil {
+		return err
+	}
+
+	if !value.Elem().IsZero() {
+		field.Set(value)
+	}
+
+	return nil
+}
+
+// loadStructValue loads a nested struct. A variable named exactly like the struct,
+// e.g. APP_UPSTREAM="host=db,port=5432", is converted as a whole first,
+// so that more specific variables like APP_UPSTREAM_PORT take precedence.
+func (s EnvSource) loadStructValue(field reflect.Value, envName string, loader *Loader) error {
+	envValue, exists := s.lookup(envName)
+	if exists {
+		err := loader.convert(field, envValue, "")
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrConversion, err)
+		}
+	}
+
+	return s.loadStruct(field, envName, loader)
+}
+
+func (s EnvSource) loadWith(target any, loader *Loader) error {
+	err := validatePointerToStruct(target)
+	if err != nil {
+		return err
+	}
+
+	valueOf := reflect.ValueOf(target).Elem()
+	prefix := s.prefix()
+
+	err = s.loadStruct(valueOf, prefix, loader)
+	if err != nil {
+		return err
+	}
+
+	return s.checkUnknown(valueOf.Type(), prefix, loader)
+}
+
+// lookup returns the value of a variable, or the value of a source replacing the environment.
+func (s EnvSource) lookup(name string) (string, bool) {
+	if s.values == nil {
+		return lookupEnv(name)
+	}
+
+	value, ok := s.values[name]
+
+	return value, ok
+}
+
+// lookupAlias returns the value of the first deprecated alias of the field that is set, warning about its use.
+// Aliases are listed in the env_alias tag separated by commas and are prefixed like the env tag.
+func (s EnvSource) lookupAlias(prefix, envName string, fieldType reflect.StructField, loader *Loader) (string, bool) {
+	for _, alias := range envAliases(prefix, fieldType) {
+		value, exists := s.lookup(alias)
+		if exists {
+			loader.logger().Warn("deprecated environment variable, use the replacement instead",
+				"variable", alias, "replacement", envName)
+
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// names returns the names of all variables, or the names of a source replacing the environment.
+func (s EnvSource) names() []string {
+	if s.values != nil {
+		return slices.Collect(maps.Keys(s.values))
+	}
+
+	environ := os.Environ()
+	names := make([]string, len(environ))
+
+	for i, env := range environ {
+		names[i], _, _ = strings.Cut(env, "=")
+	}
+
+	return names
+}
+
+// prefix returns the upper-cased prefix, derived from the executable if AutoPrefix is set.
+func (s EnvSource) prefix() string {
+	if s.Prefix == "" && s.AutoPrefix {
+		return DefaultPrefix()
+	}
+
+	return strings.ToUpper(s.Prefix)
+}
+
+// describeEnv returns the variables of all fields of the struct type, including nested structs that
+// may be set as a whole. Types already visited on the path are skipped to end recursion.
+func describeEnv(typeOf reflect.Type, prefix, path, key string, file bool, visiting map[reflect.Type]bool) []EnvVar {
+	if visiting[typeOf] {
+		return nil
+	}
+
+	visiting[typeOf] = true
+	defer delete(visiting, typeOf)
+
+	var envVars []EnvVar
+
+	for i := range typeOf.NumField() {
+		fieldType := typeOf.Field(i)
+
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		envTag, _ := lookupTag(fieldType, key)
+		if envTag == "-" {
+			continue
+		}
+
+		fieldPath := fieldType.Name
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+
+		elem := fieldType.Type
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+
+		if isSquashed(fieldType, envTag, key) {
+			envVars = append(envVars, describeEnv(elem, prefix, fieldPath, key, file, visiting)...)
+
+			continue
+		}
+
+		envName := createEnvName(prefix, fieldType.Name, envTag)
+		envVars = append(envVars, EnvVar{
+			Type:    fieldType.Type,
+			Name:    envName,
+			Field:   fieldPath,
+			Aliases: envAliases(prefix, fieldType),
+			File:    file,
+		})
+
+		if elem.Kind() == reflect.Struct {
+			envVars = append(envVars, describeEnv(elem, envName, fieldPath, key, file, visiting)...)
+		}
+	}
+
+	return envVars
+}
+
+// envAliases returns the prefixed names of the env_alias tag of the field.
+func envAliases(prefix string, fieldType reflect.StructField) []string {
+	tag := fieldType.Tag.Get("env_alias")
+	if tag == "" {
+		return nil
+	}
+
+	var aliases []string
+
+	for alias := range strings.SplitSeq(tag, ",") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases = append(aliases, createEnvName(prefix, "", alias))
+		}
+	}
+
+	return aliases
+}
+
 // createEnvName generates an environment variable name using the provided prefix, field name, and optional env tag.
 // It converts camel case field names to uppercase with underscores and includes the prefix if provided.
 func createEnvName(prefix, fieldName, envTag string) string {
@@ -139,6 +452,22 @@ func createEnvName(prefix, fieldName, envTag string) string {
 	return result.String()
 }
 
+// isSquashed reports whether the fields of a struct or pointer to struct share the namespace of its parent.
+// This applies to embedded structs without an explicit name and to fields tagged with the squash option,
+// e.g. `env:",squash"`.
+func isSquashed(fieldType reflect.StructField, envTag, key string) bool {
+	typeOf := fieldType.Type
+	if typeOf.Kind() == reflect.Ptr {
+		typeOf = typeOf.Elem()
+	}
+
+	if typeOf.Kind() != reflect.Struct || !hasExportedFields(typeOf) {
+		return false
+	}
+
+	return hasTagOption(fieldType, key, "squash") || (fieldType.Anonymous && envTag == "")
+}
+
 func lookupEnv(envName string) (string, bool) {
 	envValue, exists := os.LookupEnv(envName + "_FILE")
 	if exists {
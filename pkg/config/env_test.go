@@ -3,7 +3,9 @@ package config_test
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/spacecafe/go-parts/pkg/config"
 	"github.com/stretchr/testify/assert"
@@ -22,14 +24,17 @@ func TestEnvSource_Load(t *testing.T) {
 	}
 
 	type Config struct {
-		RefSub  *SubConfig
-		hidden  string
-		Skip    string    `env:"-"`
-		Name    string    `env:"NAME"`
-		Tags    []string  `env:"TAGS"`
-		Options []int     `env:"OPTIONS"`
-		Sub     SubConfig `env:"SUB"`
-		Port    int       `env:"PORT"`
+		RefSub    *SubConfig
+		hidden    string
+		Shared    string        `config:"SHARED"`
+		Skip      string        `env:"-"`
+		Name      string        `env:"NAME"`
+		Hosts     []string      `sep:";"`
+		Tags      []string      `env:"TAGS"`
+		Options   []int         `env:"OPTIONS"`
+		Sub       SubConfig     `env:"SUB"`
+		Retention time.Duration `unit:"days"`
+		Port      int           `env:"PORT"`
 	}
 
 	type fields struct {
@@ -61,16 +66,22 @@ func TestEnvSource_Load(t *testing.T) {
 					"APP_SUB_VALUE":                   "nested-payload",
 					"APP_SUB_NOT_ANNOTATED_VALUE":     "42",
 					"APP_REF_SUB_NOT_ANNOTATED_VALUE": "42",
+					"APP_SHARED":                      "shared-tag",
+					"APP_HOSTS":                       "a;b",
+					"APP_RETENTION":                   "2",
 				},
 			},
 			want: Config{
-				hidden:  "",
-				Name:    "test-app",
-				Port:    8080,
-				Tags:    []string{"prod", "web", "go"},
-				Options: []int{1, 2, 3},
-				Sub:     SubConfig{Value: "nested-payload", NotAnnotatedValue: 42},
-				RefSub:  &SubConfig{NotAnnotatedValue: 42},
+				hidden:    "",
+				Name:      "test-app",
+				Port:      8080,
+				Tags:      []string{"prod", "web", "go"},
+				Options:   []int{1, 2, 3},
+				Sub:       SubConfig{Value: "nested-payload", NotAnnotatedValue: 42},
+				RefSub:    &SubConfig{NotAnnotatedValue: 42},
+				Shared:    "shared-tag",
+				Hosts:     []string{"a", "b"},
+				Retention: 48 * time.Hour,
 			},
 		},
 		{
@@ -86,6 +97,22 @@ func TestEnvSource_Load(t *testing.T) {
 			},
 			want: Config{Name: "standalone", Port: 9000},
 		},
+		{
+			name:   "successful load of composite values",
+			fields: fields{Prefix: "APP"},
+			args: args{
+				target: &Config{},
+				env: map[string]string{
+					"APP_SUB":       "value=composite,notAnnotatedValue=1",
+					"APP_SUB_VALUE": "override",
+					"APP_REF_SUB":   `{"Value":"json"}`,
+				},
+			},
+			want: Config{
+				Sub:    SubConfig{Value: "override", NotAnnotatedValue: 1},
+				RefSub: &SubConfig{Value: "json"},
+			},
+		},
 		{
 			name:    "invalid target (not a pointer)",
 			fields:  fields{Prefix: "APP"},
@@ -140,3 +167,148 @@ func TestEnvSource_Load(t *testing.T) {
 		})
 	}
 }
+
+func TestEnvSource_Load_Squash(t *testing.T) {
+	type TLSConfig struct {
+		CertFile string
+		KeyFile  string
+	}
+
+	type Proxy struct {
+		Address string
+	}
+
+	type Config struct {
+		TLSConfig
+
+		*Proxy
+
+		Upstream TLSConfig `env:",squash"`
+		Named    TLSConfig `env:"SERVER_TLS"`
+		Name     string
+	}
+
+	tests := []struct {
+		env  map[string]string
+		want Config
+		name string
+	}{
+		{
+			name: "flattened fields",
+			env: map[string]string{
+				"APP_CERT_FILE":           "cert.pem",
+				"APP_KEY_FILE":            "key.pem",
+				"APP_ADDRESS":             "http://proxy",
+				"APP_SERVER_TLS_KEY_FILE": "server.pem",
+				"APP_NAME":                "app",
+			},
+			want: Config{
+				TLSConfig: TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem"},
+				Proxy:     &Proxy{Address: "http://proxy"},
+				Upstream:  TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem"},
+				Named:     TLSConfig{KeyFile: "server.pem"},
+				Name:      "app",
+			},
+		},
+		{
+			name: "nil embedded pointer stays nil",
+			env:  map[string]string{"APP_NAME": "app"},
+			want: Config{Name: "app"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			target := &Config{}
+
+			err := config.EnvSource{Prefix: "APP"}.Load(target)
+			require.NoError(t, err)
+			assert.Equal(t, &tt.want, target)
+		})
+	}
+}
+
+func TestEnvSource_Load_Unknown(t *testing.T) {
+	type Database struct {
+		Host string
+	}
+
+	type Config struct {
+		Database *Database
+		Skip     string `env:"-"`
+		Name     string
+		Port     int
+	}
+
+	t.Setenv("APP_NAME", "app")
+	t.Setenv("APP_PROT", "8080")
+	t.Setenv("APP_PORT_FILE", "/run/secrets/port")
+	t.Setenv("APP_DATABASE", "host=db")
+	t.Setenv("APP_DATABASE_HOTS", "db")
+	t.Setenv("APP_SKIP", "ignored")
+	t.Setenv("APPLICATION", "other")
+
+	var unknown []string
+
+	err := config.EnvSource{Prefix: "app", OnUnknown: func(name string) {
+		unknown = append(unknown, name)
+	}}.Load(&Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"APP_DATABASE_HOTS", "APP_PROT", "APP_SKIP"}, unknown)
+
+	err = config.EnvSource{Prefix: "APP", Strict: true}.Load(&Config{})
+	require.ErrorIs(t, err, config.ErrUnknownKey)
+	assert.ErrorContains(t, err, "APP_DATABASE_HOTS, APP_PROT, APP_SKIP")
+}
+
+func TestEnvSource_Load_AutoPrefix(t *testing.T) {
+	// Test binaries are named after the package, e.g. config.test.
+	assert.Equal(t, "CONFIG", config.DefaultPrefix())
+
+	t.Setenv("CONFIG_NAME", "auto")
+	t.Setenv("APP_NAME", "explicit")
+
+	target := &MockConfig{}
+	require.NoError(t, config.EnvSource{AutoPrefix: true}.Load(target))
+	assert.Equal(t, "auto", target.Name)
+
+	require.NoError(t, config.EnvSource{Prefix: "APP", AutoPrefix: true}.Load(target))
+	assert.Equal(t, "explicit", target.Name)
+}
+
+func TestEnvSource_Describe(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string
+		Port int `env:"PORT"`
+	}
+
+	type Embedded struct {
+		Region string
+	}
+
+	type Config struct {
+		Embedded
+
+		Database *Database
+		Skip     string `env:"-"`
+		Timeout  time.Duration
+	}
+
+	envVars, err := config.EnvSource{Prefix: "app"}.Describe(Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []config.EnvVar{
+		{Name: "APP_REGION", Field: "Embedded.Region", Type: reflect.TypeFor[string](), File: true},
+		{Name: "APP_DATABASE", Field: "Database", Type: reflect.TypeFor[*Database](), File: true},
+		{Name: "APP_DATABASE_HOST", Field: "Database.Host", Type: reflect.TypeFor[string](), File: true},
+		{Name: "APP_DATABASE_PORT", Field: "Database.Port", Type: reflect.TypeFor[int](), File: true},
+		{Name: "APP_TIMEOUT", Field: "Timeout", Type: reflect.TypeFor[time.Duration](), File: true},
+	}, envVars)
+
+	_, err = config.EnvSource{}.Describe(nil)
+	require.ErrorIs(t, err, config.ErrInvalidTarget)
+}
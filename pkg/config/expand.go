@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// expandEnv replaces ${VAR} by the value of the environment variable, ${VAR:-default} by the default
+// if the variable is unset or empty, and ${VAR-default} by the default if it is unset.
+// Unknown variables expand to an empty string; $${ escapes a literal ${. A $ without braces is kept,
+// so passwords and patterns containing $ are left alone.
+func expandEnv(value string) string {
+	if !strings.Contains(value, "${") {
+		return value
+	}
+
+	var result strings.Builder
+
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			break
+		}
+
+		if start > 0 && value[start-1] == '$' {
+			result.WriteString(value[:start])
+			result.WriteString("{")
+			value = value[start+2:]
+
+			continue
+		}
+
+		end := strings.IndexByte(value[start:], '}')
+		if end < 0 {
+			break
+		}
+
+		result.WriteString(value[:start])
+		result.WriteString(lookupExpansion(value[start+2 : start+end]))
+		value = value[start+end+1:]
+	}
+
+	result.WriteString(value)
+
+	return result.String()
+}
+
+// lookupExpansion resolves the expression between the braces of ${...}.
+func lookupExpansion(expression string) string {
+	if name, fallback, ok := strings.Cut(expression, ":-"); ok {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+
+		return fallback
+	}
+
+	if name, fallback, ok := strings.Cut(expression, "-"); ok {
+		if value, exists := os.LookupEnv(name); exists {
+			return value
+		}
+
+		return fallback
+	}
+
+	return os.Getenv(expression)
+}
+
+// rewriteValues replaces all strings of a decoded document by the result of rewrite.
+// Keys are left alone. It reports whether any string changed.
+func rewriteValues(raw any, rewrite func(string) (string, error)) (any, bool, error) {
+	switch value := raw.(type) {
+	case string:
+		rewritten, err := rewrite(value)
+
+		return rewritten, rewritten != value, err
+	case map[string]any:
+		changed := false
+
+		for key, elem := range value {
+			rewritten, elemChanged, err := rewriteValues(elem, rewrite)
+			if err != nil {
+				return nil, false, fmt.Errorf("%s: %w", key, err)
+			}
+
+			value[key] = rewritten
+			changed = changed || elemChanged
+		}
+
+		return value, changed, nil
+	case []any:
+		changed := false
+
+		for i, elem := range value {
+			rewritten, elemChanged, err := rewriteValues(elem, rewrite)
+			if err != nil {
+				return nil, false, fmt.Errorf("[%d]: %w", i, err)
+			}
+
+			value[i] = rewritten
+			changed = changed || elemChanged
+		}
+
+		return value, changed, nil
+	default:
+		return raw, false, nil
+	}
+}
+
+// rewrite decodes the document, rewrites its string values, and encodes it again.
+// Rewriting after decoding keeps values with quotes or newlines from breaking the syntax of the document.
+func (c codec) rewrite(data []byte, rewrite func(string) (string, error)) ([]byte, error) {
+	var raw any
+
+	err := c.unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, changed, err := rewriteValues(raw, rewrite)
+	if err != nil || !changed {
+		return data, err
+	}
+
+	return c.marshal(raw)
+}
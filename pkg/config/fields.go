@@ -0,0 +1,112 @@
+package config
+
+import (
+	"reflect"
+)
+
+// leafField describes a struct field that receives a single value, addressed by a derived name.
+type leafField struct {
+	typeOf reflect.Type
+	tag    reflect.StructTag
+	name   string
+
+	// index is the path of field indices from the target struct to the field.
+	index []int
+}
+
+// naming controls how leafFields derives the names of fields.
+type naming struct {
+	// name converts the name of a field without a tag.
+	name func(fieldName string) string
+
+	// tag is the source-specific tag consulted before the shared config tag.
+	tag string
+
+	// separator joins the names of nested struct fields.
+	separator string
+}
+
+// fieldByIndex returns the nested field. Nil pointers to structs are allocated if alloc is set,
+// otherwise an invalid value is returned for fields behind them.
+func fieldByIndex(valueOf reflect.Value, index []int, alloc bool) reflect.Value {
+	for i, fieldIndex := range index {
+		if i > 0 && valueOf.Kind() == reflect.Ptr {
+			if valueOf.IsNil() {
+				if !alloc {
+					return reflect.Value{}
+				}
+
+				valueOf.Set(reflect.New(valueOf.Type().Elem()))
+			}
+
+			valueOf = valueOf.Elem()
+		}
+
+		valueOf = valueOf.Field(fieldIndex)
+	}
+
+	return valueOf
+}
+
+// leafFields walks the struct type and describes every exported field that receives a single value.
+// Structs and pointers to structs with exported fields are flattened; other structs like time.Time are leaves.
+func leafFields(typeOf reflect.Type, naming naming) []leafField {
+	return naming.collect(typeOf, "", nil, map[reflect.Type]bool{})
+}
+
+// collect implements leafFields. Recursive types are only flattened once per path.
+func (n naming) collect(typeOf reflect.Type, prefix string, index []int, seen map[reflect.Type]bool) []leafField {
+	var fields []leafField
+
+	seen[typeOf] = true
+	defer delete(seen, typeOf)
+
+	for i := range typeOf.NumField() {
+		fieldType := typeOf.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		name, _ := lookupTag(fieldType, n.tag)
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = n.name(fieldType.Name)
+		}
+
+		if prefix != "" {
+			name = prefix + n.separator + name
+		}
+
+		fieldIndex := append(index[:len(index):len(index)], i)
+
+		elem := fieldType.Type
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+
+		if elem.Kind() == reflect.Struct && hasExportedFields(elem) {
+			if !seen[elem] {
+				fields = append(fields, n.collect(elem, name, fieldIndex, seen)...)
+			}
+
+			continue
+		}
+
+		fields = append(fields, leafField{typeOf: fieldType.Type, tag: fieldType.Tag, name: name, index: fieldIndex})
+	}
+
+	return fields
+}
+
+func hasExportedFields(typeOf reflect.Type) bool {
+	for i := range typeOf.NumField() {
+		if typeOf.Field(i).IsExported() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+var (
+	_ Source       = (*FlagSource)(nil)
+	_ loaderSource = (*FlagSource)(nil)
+	_ flag.Value   = (*flagValue)(nil)
+
+	//nolint:gochecknoglobals // Immutable naming rules shared by FlagSource and BindFlags.
+	flagNaming = naming{tag: "flag", separator: "-", name: createFlagName}
+)
+
+// FlagSource loads configuration from command-line flags that are derived from the struct fields.
+// Flag names are the kebab-cased field names, nested structs are joined by a dash, e.g. -database-host.
+// Names are taken from the flag tag, falling back to the shared config tag, and the usage text from the usage tag.
+// Only flags that were explicitly set on the command line are applied, so earlier sources keep their values.
+// The typeconv tags unit, layout and sep control how individual fields are parsed.
+type FlagSource struct {
+	// FlagSet receives the generated flags. It must not define flags with the same names already, except those
+	// registered by an earlier Load, which are reused, so reloads of a Watcher or Store parse the set again.
+	// Default is a new set named after the executable that returns parse errors instead of exiting.
+	FlagSet *flag.FlagSet
+
+	// Arguments are the command-line arguments to parse, without the program name.
+	// Default is os.Args[1:].
+	Arguments []string
+}
+
+// flagValue records the raw value of a flag until it is converted into its field.
+type flagValue struct {
+	defaultValue string
+	value        string
+	isBool       bool
+}
+
+func (s FlagSource) Load(target any) error {
+	return s.loadWith(target, &Loader{})
+}
+
+func (s FlagSource) loadWith(target any, loader *Loader) error {
+	err := validatePointerToStruct(target)
+	if err != nil {
+		return err
+	}
+
+	flagSet := s.FlagSet
+	if flagSet == nil {
+		flagSet = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	}
+
+	arguments := s.Arguments
+	if arguments == nil {
+		arguments = os.Args[1:]
+	}
+
+	valueOf := reflect.ValueOf(target).Elem()
+	names := flagNaming
+	names.tag = loader.tag(names.tag)
+
+	fields := leafFields(valueOf.Type(), names)
+	values := make(map[string]*flagValue, len(fields))
+
+	for _, field := range fields {
+		if existing := flagSet.Lookup(field.name); existing != nil {
+			value, ok := existing.Value.(*flagValue)
+			if !ok {
+				return fmt.Errorf("%w: flag -%s is already defined", ErrInvalidTarget, field.name)
+			}
+
+			values[field.name] = value
+
+			continue
+		}
+
+		current := fieldByIndex(valueOf, field.index, false)
+		value := &flagValue{isBool: field.typeOf.Kind() == reflect.Bool}
+
+		if current.IsValid() && !current.IsZero() {
+			value.defaultValue = fmt.Sprint(current.Interface())
+		}
+
+		values[field.name] = value
+		flagSet.Var(value, field.name, field.tag.Get("usage"))
+	}
+
+	err = flagSet.Parse(arguments)
+	if err != nil {
+		return fmt.Errorf("%w: parse flags: %w", ErrInvalidConfig, err)
+	}
+
+	set := make(map[string]bool, len(fields))
+	flagSet.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	for _, field := range fields {
+		if !set[field.name] {
+			continue
+		}
+
+		fieldValue := fieldByIndex(valueOf, field.index, true)
+
+		err = loader.convert(fieldValue, values[field.name].value, field.tag)
+		if err != nil {
+			return fmt.Errorf("%w: flag -%s: %w", ErrInvalidConfig, field.name, err)
+		}
+	}
+
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+func (v *flagValue) Set(value string) error {
+	v.value = value
+
+	return nil
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+
+	return v.defaultValue
+}
+
+// createFlagName converts a field name into kebab case, keeping acronyms together, e.g. HTTPPort becomes http-port.
+func createFlagName(fieldName string) string {
+	var result strings.Builder
+
+	runes := []rune(fieldName)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				result.WriteRune('-')
+			}
+		}
+
+		result.WriteRune(unicode.ToLower(r))
+	}
+
+	return result.String()
+}
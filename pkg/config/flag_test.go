@@ -0,0 +1,154 @@
+package config_test
+
+import (
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagSource_Load(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string `usage:"database host"`
+		Port int
+	}
+
+	type Config struct {
+		Database  *Database
+		Started   time.Time     `layout:"2006-01-02"`
+		Name      string        `flag:"app-name"`
+		Level     string        `config:"log-level"`
+		Skip      string        `flag:"-"`
+		Hosts     []string      `sep:";"`
+		HTTPPort  int           `usage:"port to listen on"`
+		Retention time.Duration `unit:"days"`
+		Verbose   bool
+	}
+
+	tests := []struct {
+		name      string
+		arguments []string
+		initial   Config
+		want      Config
+		wantErr   bool
+	}{
+		{
+			name: "successful load",
+			arguments: []string{
+				"-app-name", "test-app", "-log-level=debug", "-http-port", "8080", "-verbose",
+				"-database-host", "localhost", "-hosts", "a;b", "-retention", "2", "-started", "2024-05-27",
+			},
+			want: Config{
+				Database:  &Database{Host: "localhost"},
+				Started:   time.Date(2024, time.May, 27, 0, 0, 0, 0, time.UTC),
+				Name:      "test-app",
+				Level:     "debug",
+				HTTPPort:  8080,
+				Hosts:     []string{"a", "b"},
+				Retention: 48 * time.Hour,
+				Verbose:   true,
+			},
+		},
+		{
+			name:      "unset flags keep previous values",
+			initial:   Config{Name: "from-file", HTTPPort: 80, Database: &Database{Port: 5432}},
+			arguments: []string{"-database-host", "db"},
+			want:      Config{Name: "from-file", HTTPPort: 80, Database: &Database{Host: "db", Port: 5432}},
+		},
+		{
+			name:      "explicit zero value overrides",
+			initial:   Config{HTTPPort: 80, Verbose: true},
+			arguments: []string{"-verbose=false", "-http-port", "0"},
+			want:      Config{},
+		},
+		{
+			name:      "unknown flag",
+			arguments: []string{"-skip", "value"},
+			wantErr:   true,
+		},
+		{
+			name:      "invalid value",
+			arguments: []string{"-http-port", "not-a-number"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+			flagSet.SetOutput(io.Discard)
+
+			target := tt.initial
+
+			err := config.FlagSource{FlagSet: flagSet, Arguments: tt.arguments}.Load(&target)
+			if tt.wantErr {
+				require.ErrorIs(t, err, config.ErrInvalidConfig)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, target)
+		})
+	}
+}
+
+func TestFlagSource_Load_Usage(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Name string `usage:"name of the app"`
+		Port int
+	}
+
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagSet.SetOutput(io.Discard)
+
+	err := config.FlagSource{FlagSet: flagSet, Arguments: []string{"-help"}}.Load(&Config{Port: 8080})
+	require.ErrorIs(t, err, config.ErrInvalidConfig)
+	require.ErrorIs(t, err, flag.ErrHelp)
+
+	assert.Equal(t, "name of the app", flagSet.Lookup("name").Usage)
+	assert.Equal(t, "8080", flagSet.Lookup("port").DefValue)
+}
+
+func TestFlagSource_Load_Redefined(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Name string
+	}
+
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagSet.String("name", "", "")
+
+	err := config.FlagSource{FlagSet: flagSet, Arguments: []string{}}.Load(&Config{})
+	require.ErrorIs(t, err, config.ErrInvalidTarget)
+}
+
+func TestFlagSource_Load_Twice(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Name string
+		Port int
+	}
+
+	source := config.FlagSource{
+		FlagSet:   flag.NewFlagSet("test", flag.ContinueOnError),
+		Arguments: []string{"-name", "app"},
+	}
+
+	for range 2 {
+		target := &Config{Port: 8080}
+		require.NoError(t, source.Load(target))
+		assert.Equal(t, &Config{Name: "app", Port: 8080}, target)
+	}
+}
@@ -1,28 +1,77 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 )
 
-var _ Source = (*JSONSource)(nil)
+var (
+	_ Source       = (*JSONSource)(nil)
+	_ loaderSource = (*JSONSource)(nil)
+
+	//nolint:gochecknoglobals // Stateless codec shared by all JSON sources.
+	jsonCodec = codec{unmarshal: unmarshalJSON, marshal: json.Marshal, tag: "json"}
+)
 
 // JSONSource loads configuration from a JSON file.
+// Fields are matched by their json tag, falling back to the shared config tag.
 type JSONSource struct {
 	Path string
+
+	// ExpandEnv expands ${VAR}, ${VAR:-default} and ${VAR-default} in string values with environment variables.
+	ExpandEnv bool
+
+	// Strict rejects keys that match no field of the target, reporting their paths, e.g. to catch typos.
+	Strict bool
 }
 
 func (s JSONSource) Load(target any) error {
+	return s.loadWith(target, &Loader{})
+}
+
+func (s JSONSource) loadWith(target any, loader *Loader) error {
+	keyedCodec := jsonCodec.withKey(loader.tag(jsonCodec.tag))
+	keyedCodec.strict = s.Strict || loader.Strict
+
 	data, err := os.ReadFile(s.Path)
 	if err != nil {
 		return fmt.Errorf("%w: read JSON file: %w", ErrConfigNotFound, err)
 	}
 
-	err = json.Unmarshal(data, target)
+	if rewrite := loader.rewriter(s.ExpandEnv); rewrite != nil {
+		data, err = keyedCodec.rewrite(data, rewrite)
+		if err != nil {
+			return fmt.Errorf("%w: unmarshal JSON: %w", ErrInvalidConfig, err)
+		}
+	}
+
+	err = keyedCodec.decode(data, target)
 	if err != nil {
 		return fmt.Errorf("%w: unmarshal JSON: %w", ErrInvalidConfig, err)
 	}
 
 	return nil
 }
+
+// unmarshalJSON works like json.Unmarshal, but decodes numbers into untyped targets as json.Number,
+// so large integers keep their precision when values are re-encoded.
+func unmarshalJSON(data []byte, target any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	err := decoder.Decode(target)
+	if err != nil {
+		return err
+	}
+
+	_, err = decoder.Token()
+	if !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: unexpected data after top-level value", ErrInvalidConfig)
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -27,3 +28,154 @@ func TestJSONSource_Load(t *testing.T) {
 		}
 	}, validFile, invalidFile)
 }
+
+func TestJSONSource_Load_SharedTag(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string `config:"hostname"`
+		Port int    `json:"port"`
+	}
+
+	type Config struct {
+		Database *Database `config:"db"`
+		Name     string    `config:"appName"   json:"name"`
+		Level    string    `config:"logLevel"`
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(file, []byte(`{
+		"name": "test-app",
+		"appName": "ignored",
+		"logLevel": "debug",
+		"db": {"hostname": "localhost", "port": 5432}
+	}`), 0o600)
+	require.NoError(t, err)
+
+	target := &Config{}
+	err = config.JSONSource{Path: file}.Load(target)
+	require.NoError(t, err)
+	assert.Equal(t, &Config{
+		Database: &Database{Host: "localhost", Port: 5432},
+		Name:     "test-app",
+		Level:    "debug",
+	}, target)
+}
+
+func TestJSONSource_Load_ExpandEnv(t *testing.T) {
+	t.Setenv("EXPAND_HOST", "db.example")
+	t.Setenv("EXPAND_EMPTY", "")
+	t.Setenv("EXPAND_QUOTED", `he said "hi"`)
+
+	type Config struct {
+		Host     string   `json:"host"`
+		Fallback string   `json:"fallback"`
+		Empty    string   `json:"empty"`
+		Quoted   string   `json:"quoted"`
+		Literal  string   `json:"literal"`
+		Password string   `json:"password"`
+		Hosts    []string `json:"hosts"`
+		ID       int64    `json:"id"`
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(file, []byte(`{
+		"host": "${EXPAND_HOST}:5432",
+		"fallback": "${EXPAND_UNSET:-fallback}",
+		"empty": "${EXPAND_EMPTY-unused}",
+		"quoted": "${EXPAND_QUOTED}",
+		"literal": "$${EXPAND_HOST}",
+		"password": "pa$word",
+		"hosts": ["${EXPAND_HOST}", "${EXPAND_UNSET}"],
+		"id": 9007199254740993
+	}`), 0o600)
+	require.NoError(t, err)
+
+	target := &Config{}
+	err = config.JSONSource{Path: file, ExpandEnv: true}.Load(target)
+	require.NoError(t, err)
+	assert.Equal(t, &Config{
+		Host:     "db.example:5432",
+		Fallback: "fallback",
+		Empty:    "",
+		Quoted:   `he said "hi"`,
+		Literal:  "${EXPAND_HOST}",
+		Password: "pa$word",
+		Hosts:    []string{"db.example", ""},
+		ID:       9007199254740993,
+	}, target)
+
+	// Without the option the values are kept as written.
+	target = &Config{}
+	err = config.JSONSource{Path: file}.Load(target)
+	require.NoError(t, err)
+	assert.Equal(t, "${EXPAND_HOST}:5432", target.Host)
+}
+
+func TestJSONSource_Load_Strict(t *testing.T) {
+	t.Parallel()
+
+	type Server struct {
+		Host string `json:"host"`
+	}
+
+	type Embedded struct {
+		Region string `json:"region"`
+	}
+
+	type Config struct {
+		Embedded
+
+		Labels  map[string]string `json:"labels"`
+		Routes  map[string]Server `json:"routes"`
+		Name    string            `json:"name"`
+		Level   string            `config:"logLevel"`
+		Hidden  string            `json:"-"`
+		Servers []Server          `json:"servers"`
+		Port    int
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "known keys",
+			content: `{
+				"name": "app", "PORT": 8080, "logLevel": "debug", "region": "eu",
+				"servers": [{"host": "a"}], "labels": {"any": "value"}, "routes": {"api": {"host": "b"}}
+			}`,
+		},
+		{
+			name: "unknown keys",
+			content: `{
+				"nmae": "app", "Hidden": "x", "servers": [{"host": "a"}, {"hots": "b"}],
+				"routes": {"api": {"host": "b", "port": 1}}
+			}`,
+			wantErr: "Hidden, nmae, routes.api.port, servers[1].hots",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			file := filepath.Join(t.TempDir(), "config.json")
+			require.NoError(t, os.WriteFile(file, []byte(tt.content), 0o600))
+
+			err := config.JSONSource{Path: file, Strict: true}.Load(&Config{})
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, config.ErrInvalidConfig)
+			require.ErrorIs(t, err, config.ErrUnknownKey)
+			assert.ErrorContains(t, err, tt.wantErr)
+
+			// Without the option unknown keys are ignored.
+			require.NoError(t, config.JSONSource{Path: file}.Load(&Config{}))
+		})
+	}
+}
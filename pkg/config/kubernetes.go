@@ -0,0 +1,381 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// kubernetesServiceAccountDir holds the token, CA certificate and namespace mounted into every pod.
+	kubernetesServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+	// kubernetesRequestTimeout limits a single Load, which has no context of its own.
+	kubernetesRequestTimeout = 30 * time.Second
+
+	// kubernetesMinBackoff and kubernetesMaxBackoff bound the delay before Watch re-establishes a watch stream
+	// that ended without delivering an event, which doubles with every such stream.
+	kubernetesMinBackoff = 250 * time.Millisecond
+	kubernetesMaxBackoff = 30 * time.Second
+)
+
+var (
+	_ Source       = (*KubernetesSource)(nil)
+	_ loaderSource = (*KubernetesSource)(nil)
+
+	//nolint:gochecknoglobals // Immutable naming rules of KubernetesSource.
+	kubernetesNaming = naming{tag: TagName, separator: ".", name: strings.ToLower}
+)
+
+// KubernetesSource loads configuration from a ConfigMap or Secret through the Kubernetes API,
+// so changes can be picked up without mounting the object and restarting the pod.
+// Data keys are matched case-insensitively against the shared config tag or the field name;
+// nested struct fields are addressed by dotted keys like "database.host". Keys without a field are ignored.
+// The typeconv tags unit, layout and sep control how individual fields are parsed.
+// The service account needs get and watch permissions on the object.
+type KubernetesSource struct {
+	// Client sends the API requests.
+	// Default is a client trusting the CA certificate of the service account.
+	Client *http.Client
+
+	// Name is the name of the ConfigMap or Secret.
+	Name string
+
+	// Namespace is the namespace of the object.
+	// Default is the namespace of the service account.
+	Namespace string
+
+	// APIServer is the base URL of the Kubernetes API.
+	// Default is derived from KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT.
+	APIServer string
+
+	// TokenFile holds the bearer token. It is read on every request, as tokens are rotated.
+	// Default is the token of the service account.
+	TokenFile string
+
+	// Secret selects a Secret instead of a ConfigMap.
+	Secret bool
+}
+
+// kubernetesObject is the subset of a ConfigMap or Secret used by KubernetesSource.
+type kubernetesObject struct {
+	Data       map[string]string `json:"data"`
+	BinaryData map[string]string `json:"binaryData"`
+	Metadata   struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+}
+
+// kubernetesEvent is a single event of a watch stream.
+type kubernetesEvent struct {
+	Type   string           `json:"type"`
+	Object kubernetesObject `json:"object"`
+}
+
+func (s KubernetesSource) Load(target any) error {
+	return s.loadWith(target, &Loader{})
+}
+
+// Watch calls notify whenever the object is added, modified or deleted, so the caller can load it again.
+// It blocks until the context is canceled, which is not reported as an error,
+// and re-establishes the watch when the API server closes the stream. Streams that end without an event are
+// re-established with an exponential backoff, so an API server closing them right away is not flooded.
+func (s KubernetesSource) Watch(ctx context.Context, notify func()) error {
+	client, err := s.client()
+	if err != nil {
+		return err
+	}
+
+	backoff := kubernetesMinBackoff
+
+	for ctx.Err() == nil {
+		object, err := s.get(ctx, client)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+
+			return err
+		}
+
+		received, err := s.watch(ctx, client, object.Metadata.ResourceVersion, notify)
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+
+		if received {
+			backoff = kubernetesMinBackoff
+
+			continue
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+
+		backoff = min(2*backoff, kubernetesMaxBackoff)
+	}
+
+	return nil
+}
+
+// apiServer returns the base URL of the Kubernetes API.
+func (s KubernetesSource) apiServer() (string, error) {
+	if s.APIServer != "" {
+		return strings.TrimSuffix(s.APIServer, "/"), nil
+	}
+
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return "", fmt.Errorf("%w: not running in a Kubernetes cluster", ErrConfigNotFound)
+	}
+
+	return "https://" + net.JoinHostPort(host, port), nil
+}
+
+// client returns the configured client or one trusting the CA certificate of the service account.
+func (s KubernetesSource) client() (*http.Client, error) {
+	if s.Client != nil {
+		return s.Client, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(kubernetesServiceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: read Kubernetes CA certificate: %w", ErrConfigNotFound, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%w: invalid Kubernetes CA certificate", ErrInvalidConfig)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert // Always a *http.Transport.
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// get fetches the current state of the object.
+func (s KubernetesSource) get(ctx context.Context, client *http.Client) (*kubernetesObject, error) {
+	resp, err := s.request(ctx, client, "/"+url.PathEscape(s.Name), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	object := &kubernetesObject{}
+
+	err = json.NewDecoder(resp.Body).Decode(object)
+	if err != nil {
+		return nil, fmt.Errorf("%w: decode Kubernetes object: %w", ErrInvalidConfig, err)
+	}
+
+	return object, nil
+}
+
+func (s KubernetesSource) loadWith(target any, loader *Loader) error {
+	err := validatePointerToStruct(target)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.client()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), kubernetesRequestTimeout)
+	defer cancel()
+
+	object, err := s.get(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	values, err := object.values(s.Secret)
+	if err != nil {
+		return err
+	}
+
+	valueOf := reflect.ValueOf(target).Elem()
+
+	for _, field := range leafFields(valueOf.Type(), kubernetesNaming) {
+		value, ok := values[strings.ToLower(field.name)]
+		if !ok {
+			continue
+		}
+
+		err = loader.convert(fieldByIndex(valueOf, field.index, true), value, field.tag)
+		if err != nil {
+			return fmt.Errorf("%w: key %s: %w", ErrInvalidConfig, field.name, err)
+		}
+	}
+
+	return nil
+}
+
+// request sends a GET request below the collection of the object kind and checks the response status.
+func (s KubernetesSource) request(
+	ctx context.Context,
+	client *http.Client,
+	path string,
+	query url.Values,
+) (*http.Response, error) {
+	base, err := s.apiServer()
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := s.Namespace
+	if namespace == "" {
+		var data []byte
+
+		data, err = os.ReadFile(filepath.Join(kubernetesServiceAccountDir, "namespace"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: read Kubernetes namespace: %w", ErrConfigNotFound, err)
+		}
+
+		namespace = strings.TrimSpace(string(data))
+	}
+
+	resource := "configmaps"
+	if s.Secret {
+		resource = "secrets"
+	}
+
+	endpoint := base + "/api/v1/namespaces/" + url.PathEscape(namespace) + "/" + resource + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: build Kubernetes request: %w", ErrInvalidConfig, err)
+	}
+
+	tokenFile := s.TokenFile
+	if tokenFile == "" {
+		tokenFile = filepath.Join(kubernetesServiceAccountDir, "token")
+	}
+
+	token, err := os.ReadFile(filepath.Clean(tokenFile))
+	if err != nil {
+		return nil, fmt.Errorf("%w: read Kubernetes token: %w", ErrConfigNotFound, err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: request Kubernetes object %s/%s: %w", ErrConfigNotFound, namespace, s.Name, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+
+		return nil, fmt.Errorf(
+			"%w: request Kubernetes object %s/%s: %s",
+			ErrConfigNotFound, namespace, s.Name, resp.Status,
+		)
+	}
+
+	return resp, nil
+}
+
+// watch consumes a single watch stream starting at the resource version and reports whether it received a
+// change of the object. It returns no error when the stream ends or the resource version expired, so the caller fetches the
+// object again and starts a new stream.
+func (s KubernetesSource) watch(
+	ctx context.Context,
+	client *http.Client,
+	version string,
+	notify func(),
+) (bool, error) {
+	query := url.Values{
+		"watch":           {"true"},
+		"fieldSelector":   {"metadata.name=" + s.Name},
+		"resourceVersion": {version},
+	}
+
+	resp, err := s.request(ctx, client, "", query)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+
+	received := false
+
+	for {
+		var event kubernetesEvent
+
+		err = decoder.Decode(&event)
+		if errors.Is(err, io.EOF) {
+			return received, nil
+		}
+
+		if err != nil {
+			return received, fmt.Errorf("%w: decode Kubernetes watch event: %w", ErrInvalidConfig, err)
+		}
+
+		switch event.Type {
+		case "ADDED", "MODIFIED", "DELETED":
+			received = true
+
+			notify()
+		case "ERROR":
+			// The object may have changed while the resource version was outdated. Errors do not reset the
+			// backoff, so a stream failing right away is not re-established at once.
+			notify()
+
+			return received, nil
+		}
+	}
+}
+
+// values returns the data of the object by lowercase key, decoding base64 encoded entries.
+func (o *kubernetesObject) values(secret bool) (map[string]string, error) {
+	values := make(map[string]string, len(o.Data)+len(o.BinaryData))
+
+	for key, value := range o.Data {
+		if secret {
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: decode Secret key %s: %w", ErrInvalidConfig, key, err)
+			}
+
+			value = string(decoded)
+		}
+
+		values[strings.ToLower(key)] = value
+	}
+
+	for key, value := range o.BinaryData {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: decode ConfigMap key %s: %w", ErrInvalidConfig, key, err)
+		}
+
+		values[strings.ToLower(key)] = string(decoded)
+	}
+
+	return values, nil
+}
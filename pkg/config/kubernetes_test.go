@@ -0,0 +1,204 @@
+package config_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubernetesSource_Load(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string
+		Port int
+	}
+
+	type Config struct {
+		Database *Database
+		Name     string `config:"appName"`
+		Password string
+		Timeout  time.Duration `unit:"s"`
+	}
+
+	token := filepath.Join(t.TempDir(), "token")
+	err := os.WriteFile(token, []byte("secret-token\n"), 0o600)
+	require.NoError(t, err)
+
+	password := base64.StdEncoding.EncodeToString([]byte("hunter2"))
+
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer secret-token" {
+			resp.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		switch req.URL.Path {
+		case "/api/v1/namespaces/default/configmaps/app":
+			_, _ = fmt.Fprintf(resp, `{
+				"metadata": {"resourceVersion": "1"},
+				"data": {"appName": "test-app", "database.host": "db", "unknown": "ignored"},
+				"binaryData": {"timeout": %q}
+			}`, base64.StdEncoding.EncodeToString([]byte("30")))
+		case "/api/v1/namespaces/default/configmaps/invalid":
+			_, _ = fmt.Fprint(resp, `{"data": {"database.port": "not-a-number"}}`)
+		case "/api/v1/namespaces/default/secrets/app":
+			_, _ = fmt.Fprintf(resp, `{"data": {"password": %q}}`, password)
+		default:
+			resp.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		want    *Config
+		wantErr error
+		name    string
+		source  config.KubernetesSource
+	}{
+		{
+			name:   "successful load of ConfigMap",
+			source: config.KubernetesSource{Name: "app"},
+			want:   &Config{Database: &Database{Host: "db"}, Name: "test-app", Timeout: 30 * time.Second},
+		},
+		{
+			name:   "successful load of Secret",
+			source: config.KubernetesSource{Name: "app", Secret: true},
+			want:   &Config{Password: "hunter2"},
+		},
+		{
+			name:    "object not found",
+			source:  config.KubernetesSource{Name: "missing"},
+			wantErr: config.ErrConfigNotFound,
+		},
+		{
+			name:    "invalid value",
+			source:  config.KubernetesSource{Name: "invalid"},
+			wantErr: config.ErrInvalidConfig,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			source := tt.source
+			source.Client = server.Client()
+			source.APIServer = server.URL
+			source.Namespace = "default"
+			source.TokenFile = token
+
+			target := &Config{}
+
+			err := source.Load(target)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, target)
+		})
+	}
+}
+
+func TestKubernetesSource_Watch(t *testing.T) {
+	t.Parallel()
+
+	token := filepath.Join(t.TempDir(), "token")
+	err := os.WriteFile(token, []byte("secret-token"), 0o600)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("watch") != "true" {
+			_, _ = fmt.Fprint(resp, `{"metadata": {"resourceVersion": "1"}}`)
+
+			return
+		}
+
+		assert.Equal(t, "metadata.name=app", req.URL.Query().Get("fieldSelector"))
+		assert.Equal(t, "1", req.URL.Query().Get("resourceVersion"))
+
+		_, _ = fmt.Fprint(resp, `{"type": "MODIFIED", "object": {"metadata": {"resourceVersion": "2"}}}`)
+		resp.(http.Flusher).Flush()
+
+		<-req.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	source := config.KubernetesSource{
+		Client:    server.Client(),
+		Name:      "app",
+		Namespace: "default",
+		APIServer: server.URL,
+		TokenFile: token,
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	notified := make(chan struct{})
+	done := make(chan error)
+
+	go func() {
+		done <- source.Watch(ctx, func() { close(notified) })
+	}()
+
+	select {
+	case <-notified:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not notify about the modification")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestKubernetesSource_Watch_Backoff(t *testing.T) {
+	t.Parallel()
+
+	token := filepath.Join(t.TempDir(), "token")
+	err := os.WriteFile(token, []byte("secret-token"), 0o600)
+	require.NoError(t, err)
+
+	var watches atomic.Int64
+
+	// The API server closes every watch stream right away.
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("watch") == "true" {
+			watches.Add(1)
+
+			return
+		}
+
+		_, _ = fmt.Fprint(resp, `{"metadata": {"resourceVersion": "1"}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	source := config.KubernetesSource{
+		Client:    server.Client(),
+		Name:      "app",
+		Namespace: "default",
+		APIServer: server.URL,
+		TokenFile: token,
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+
+	require.NoError(t, source.Watch(ctx, func() {}))
+
+	// Streams are re-established after 250ms, 500ms and 1s.
+	assert.LessOrEqual(t, watches.Load(), int64(4))
+	assert.GreaterOrEqual(t, watches.Load(), int64(2))
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"github.com/spacecafe/go-parts/pkg/log"
+	"github.com/spacecafe/go-parts/pkg/typeconv"
+)
+
+// Loader loads configuration like Load, but lets an application customize how values are converted
+// and which struct tags are consulted without side effects on other users of the package.
+// The zero value behaves like Load.
+type Loader struct {
+	// Log receives warnings about deprecated fields and environment variables.
+	// Default is slog.Default().
+	Log log.Logger
+
+	// Converter parses the string values of environment variables, flags and Kubernetes objects.
+	// Default is typeconv.Default.
+	Converter *typeconv.Converter
+
+	// Tags replaces the names of source-specific struct tags, e.g. {"env": "envconfig", "json": "cfg"}.
+	// The shared config tag is still consulted if a field has no replaced tag.
+	// Note that JSON, YAML and TOML decoders keep honoring their native tags in addition.
+	// Default is no replacement.
+	Tags map[string]string
+
+	// Merge is the strategy for slices and maps set by several sources, unless a field has a merge tag.
+	// Default is MergeReplace.
+	Merge MergeStrategy
+
+	// ExpandEnv expands environment variables in the string values of all file sources, see JSONSource.ExpandEnv.
+	ExpandEnv bool
+
+	// Templates renders the string values of all sources as text/template before they are converted,
+	// e.g. "{{ env \"HOSTNAME\" }}:9090". Templates can call env, file, default and b64dec.
+	// File sources render after expanding environment variables.
+	Templates bool
+
+	// Strict rejects keys that match no field of the target in all file sources, see JSONSource.Strict,
+	// and unrecognized variables of EnvSource, see EnvSource.Strict.
+	Strict bool
+}
+
+// loaderSource is implemented by the sources of this package to receive the settings of the Loader.
+type loaderSource interface {
+	loadWith(target any, loader *Loader) error
+}
+
+// Dump serializes the configuration like Dump, naming the fields by the replaced tag of the format.
+func (l *Loader) Dump(target any, format Format) ([]byte, error) {
+	return dump(target, format, l.tag(string(format)))
+}
+
+// Load applies defaults, loads the sources in order and validates the result like Load.
+//
+//nolint:wrapcheck // Errors are already wrapped in sources.
+func (l *Loader) Load(target Validatable, sources ...Source) error {
+	err := validatePointerToStruct(target)
+	if err != nil {
+		return err
+	}
+
+	// Apply defaults of the target and nested structs implementing Defaultable
+	applyDefaults(reflect.ValueOf(target).Elem())
+
+	err = l.loadMerged(target, l.Merge, sources)
+	if err != nil {
+		return err
+	}
+
+	l.migrateDeprecated(reflect.ValueOf(target).Elem(), "")
+
+	prefix := envPrefix(sources)
+
+	err = errors.Join(l.checkRequired(target, prefix), l.validateTags(target, prefix))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	err = errors.Join(validateNested(reflect.ValueOf(target).Elem(), "")...)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	return nil
+}
+
+// convert renders the value of a source if Templates is set and converts it into the field,
+// honoring the parsing tags of the field.
+//
+//nolint:wrapcheck // Callers wrap the errors with their context.
+func (l *Loader) convert(field reflect.Value, value string, tag reflect.StructTag) error {
+	value, err := l.render(value)
+	if err != nil {
+		return err
+	}
+
+	return l.converter().ConvertWithTag(field, value, tag)
+}
+
+// converter returns the Converter of the loader or typeconv.Default.
+func (l *Loader) converter() *typeconv.Converter {
+	if l.Converter == nil {
+		return typeconv.Default
+	}
+
+	return l.Converter
+}
+
+// load loads a single source, passing the settings of the loader to sources of this package.
+//
+//nolint:wrapcheck // Errors are already wrapped in sources.
+func (l *Loader) load(source Source, target any) error {
+	if withLoader, ok := source.(loaderSource); ok {
+		return withLoader.loadWith(target, l)
+	}
+
+	return source.Load(target)
+}
+
+// logger returns the Log of the loader or slog.Default().
+//
+//nolint:ireturn // Loggers are passed around by interface.
+func (l *Loader) logger() log.Logger {
+	if l.Log == nil {
+		return slog.Default()
+	}
+
+	return l.Log
+}
+
+// render renders the value of a source as template if Templates is set.
+func (l *Loader) render(value string) (string, error) {
+	if !l.Templates {
+		return value, nil
+	}
+
+	return renderTemplate(value)
+}
+
+// rewriter returns the function rewriting the string values of a file source, or nil if they are kept.
+func (l *Loader) rewriter(expand bool) func(string) (string, error) {
+	expand = expand || l.ExpandEnv
+
+	if !expand && !l.Templates {
+		return nil
+	}
+
+	return func(value string) (string, error) {
+		if expand {
+			value = expandEnv(value)
+		}
+
+		return l.render(value)
+	}
+}
+
+// tag returns the replaced name of the source-specific tag.
+func (l *Loader) tag(name string) string {
+	if replaced, ok := l.Tags[name]; ok && replaced != "" {
+		return replaced
+	}
+
+	return name
+}
@@ -0,0 +1,130 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/spacecafe/go-parts/pkg/typeconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type LoaderConfig struct {
+	Name  string   `cfg:"appName"    json:"name"`
+	Level string   `cfg:"logLevel"`
+	Hosts []string `envconfig:"HOST_LIST" json:"hosts"`
+	Port  int      `json:"port"`
+}
+
+func (c *LoaderConfig) Validate() error {
+	return nil
+}
+
+func TestLoader_Load(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(file, []byte(`{
+		"name": "native",
+		"appName": "${LOADER_NAME}",
+		"logLevel": "debug",
+		"hosts": ["a"],
+		"port": 8080
+	}`), 0o600)
+	require.NoError(t, err)
+
+	t.Setenv("LOADER_NAME", "replaced")
+	t.Setenv("APP_HOST_LIST", "b;c")
+
+	converter := *typeconv.Default
+	converter.SliceSeparator = ";"
+
+	loader := &config.Loader{
+		Converter: &converter,
+		Tags:      map[string]string{"env": "envconfig", "json": "cfg"},
+		Merge:     config.MergeAppend,
+		ExpandEnv: true,
+	}
+
+	target := &LoaderConfig{}
+	err = loader.Load(target, config.JSONSource{Path: file}, config.EnvSource{Prefix: "APP"})
+	require.NoError(t, err)
+	assert.Equal(t, &LoaderConfig{
+		Name:  "replaced",
+		Level: "debug",
+		Hosts: []string{"a", "b", "c"},
+		Port:  8080,
+	}, target)
+
+	data, err := loader.Dump(target, config.FormatJSON)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"appName": "replaced", "logLevel": "debug", "Hosts": ["a", "b", "c"], "Port": 8080}`, string(data))
+
+	// The zero Loader behaves like Load and leaves typeconv.Default alone.
+	target = &LoaderConfig{}
+	err = (&config.Loader{}).Load(target, config.JSONSource{Path: file}, config.EnvSource{Prefix: "APP"})
+	require.NoError(t, err)
+	assert.Equal(t, &LoaderConfig{Name: "native", Hosts: []string{"a"}, Port: 8080}, target)
+	assert.Equal(t, ",", typeconv.Default.SliceSeparator)
+}
+
+func TestLoader_Load_Templates(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(secret, []byte("s3cr3t\n"), 0o600))
+
+	file := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{
+		"name": "{{ env \"TEMPLATE_HOST\" }}:9090",
+		"hosts": ["{{ file \"`+secret+`\" }}", "{{ env \"TEMPLATE_UNSET\" | default \"fallback\" }}"]
+	}`), 0o600))
+
+	t.Setenv("TEMPLATE_HOST", "node-1")
+	t.Setenv("APP_LEVEL", `{{ "ZGVidWc=" | b64dec }}`)
+	t.Setenv("APP_PORT", `{{ env "TEMPLATE_PORT" | default "8080" }}`)
+
+	loader := &config.Loader{Templates: true}
+
+	target := &LoaderConfig{}
+	err := loader.Load(target, config.JSONSource{Path: file}, config.EnvSource{Prefix: "APP"})
+	require.NoError(t, err)
+	assert.Equal(t, &LoaderConfig{
+		Name:  "node-1:9090",
+		Level: "debug",
+		Hosts: []string{"s3cr3t", "fallback"},
+		Port:  8080,
+	}, target)
+
+	// Without the option templates are kept as written.
+	target = &LoaderConfig{}
+	err = (&config.Loader{}).Load(target, config.JSONSource{Path: file})
+	require.NoError(t, err)
+	assert.Equal(t, `{{ env "TEMPLATE_HOST" }}:9090`, target.Name)
+
+	t.Setenv("APP_PORT", `{{ env "TEMPLATE_PORT" `)
+	err = loader.Load(&LoaderConfig{}, config.EnvSource{Prefix: "APP"})
+	require.ErrorIs(t, err, config.ErrInvalidConfig)
+}
+
+type BoundsConfig struct {
+	Timeout time.Duration `validate:"min=12h,max=1w"`
+}
+
+func (c *BoundsConfig) Validate() error {
+	return nil
+}
+
+func TestLoader_Load_ValidateBounds(t *testing.T) {
+	t.Setenv("BOUNDS_TIMEOUT", "2w")
+
+	converter := *typeconv.Default
+	converter.ExtendedDuration = true
+
+	loader := &config.Loader{Converter: &converter}
+
+	err := loader.Load(&BoundsConfig{}, config.EnvSource{Prefix: "BOUNDS"})
+	require.ErrorIs(t, err, config.ErrConstraint)
+	require.NotErrorIs(t, err, config.ErrInvalidTarget)
+	assert.Contains(t, err.Error(), "Timeout (env BOUNDS_TIMEOUT) must be at most 1w")
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Markdown documents the configuration type of the target as a Markdown table with the field path, type,
+// default value, environment variable and description of every field. Defaults are taken from SetDefaults
+// of a fresh value, descriptions from the desc tag, e.g. `desc:"Port to listen on."`. Environment variables
+// are named like EnvSource with the given prefix. Defaults of fields tagged `secret:"true"` are redacted.
+func Markdown(target any, envPrefix string) ([]byte, error) {
+	typeOf := reflect.TypeOf(target)
+	if typeOf != nil && typeOf.Kind() == reflect.Ptr {
+		typeOf = typeOf.Elem()
+	}
+
+	if typeOf == nil || typeOf.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: target must be a struct or pointer to struct, got %T", ErrInvalidTarget, target)
+	}
+
+	// Optional sections are allocated, so their fields and defaults are documented as well.
+	valueOf := reflect.New(typeOf).Elem()
+	allocStructs(valueOf, map[reflect.Type]bool{})
+	applyDefaults(valueOf)
+
+	var result strings.Builder
+
+	result.WriteString("| Field | Type | Default | Environment | Description |\n")
+	result.WriteString("|-------|------|---------|-------------|-------------|\n")
+
+	describe := func(field reflect.Value, fieldType reflect.StructField, path, envName string) {
+		if isStructOrStructPtr(fieldType.Type) && !isDumpLeaf(fieldType.Type) {
+			return
+		}
+
+		fmt.Fprintf(&result, "| `%s` | `%s` | %s | `%s` | %s |\n",
+			path, fieldType.Type, markdownDefault(field, fieldType), envName, markdownEscape(fieldType.Tag.Get("desc")))
+	}
+
+	walkFields(valueOf, "", strings.ToUpper(envPrefix), "env", describe)
+
+	return []byte(result.String()), nil
+}
+
+// allocStructs allocates all nil pointers to structs below the value, skipping types already visited on the path.
+func allocStructs(valueOf reflect.Value, visiting map[reflect.Type]bool) {
+	visiting[valueOf.Type()] = true
+	defer delete(visiting, valueOf.Type())
+
+	for i := range valueOf.NumField() {
+		field := valueOf.Field(i)
+
+		if !field.CanSet() || !isStructOrStructPtr(field.Type()) || isDumpLeaf(field.Type()) {
+			continue
+		}
+
+		if field.Kind() == reflect.Ptr {
+			if visiting[field.Type().Elem()] {
+				continue
+			}
+
+			field.Set(reflect.New(field.Type().Elem()))
+			field = field.Elem()
+		}
+
+		allocStructs(field, visiting)
+	}
+}
+
+// markdownDefault formats the default value of a field for a table cell, or returns an empty string if it is zero.
+func markdownDefault(field reflect.Value, fieldType reflect.StructField) string {
+	if field.IsZero() {
+		return ""
+	}
+
+	if secret, _ := strconv.ParseBool(fieldType.Tag.Get("secret")); secret {
+		return RedactedValue
+	}
+
+	value := reflect.Indirect(field)
+	if value.Type() == reflect.TypeFor[time.Duration]() {
+		return "`" + time.Duration(value.Int()).String() + "`"
+	}
+
+	return "`" + markdownEscape(fmt.Sprint(value.Interface())) + "`"
+}
+
+// markdownEscape keeps pipes and line breaks from breaking the table.
+func markdownEscape(value string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(value)
+}
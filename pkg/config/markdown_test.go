@@ -0,0 +1,51 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MarkdownConfig struct {
+	Database *MarkdownDatabase
+	Name     string        `desc:"Name of the service | shown in logs."`
+	Token    string        `desc:"API token."                            secret:"true"`
+	Timeout  time.Duration `desc:"Timeout of requests."`
+	Port     int           `desc:"Port to listen on."                    env:"HTTP_PORT"`
+}
+
+type MarkdownDatabase struct {
+	Parent *MarkdownConfig
+	Host   string `desc:"Hostname of the database."`
+}
+
+func (c *MarkdownDatabase) SetDefaults() {
+	c.Host = "localhost"
+}
+
+func (c *MarkdownConfig) SetDefaults() {
+	c.Name = "app"
+	c.Timeout = 5 * time.Second
+	c.Token = "default-token"
+	c.Port = 8080
+}
+
+func TestMarkdown(t *testing.T) {
+	t.Parallel()
+
+	data, err := config.Markdown(&MarkdownConfig{Port: 1}, "app")
+	require.NoError(t, err)
+	assert.Equal(t, "| Field | Type | Default | Environment | Description |\n"+
+		"|-------|------|---------|-------------|-------------|\n"+
+		"| `Database.Host` | `string` | `localhost` | `APP_DATABASE_HOST` | Hostname of the database. |\n"+
+		"| `Name` | `string` | `app` | `APP_NAME` | Name of the service \\| shown in logs. |\n"+
+		"| `Token` | `string` | ***** | `APP_TOKEN` | API token. |\n"+
+		"| `Timeout` | `time.Duration` | `5s` | `APP_TIMEOUT` | Timeout of requests. |\n"+
+		"| `Port` | `int` | `8080` | `APP_HTTP_PORT` | Port to listen on. |\n", string(data))
+
+	_, err = config.Markdown("invalid", "")
+	require.ErrorIs(t, err, config.ErrInvalidTarget)
+}
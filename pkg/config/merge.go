@@ -0,0 +1,274 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MergeStrategy controls how a slice or map field is combined when several sources set it.
+// The strategy of a single field can be chosen with the merge tag, e.g. `merge:"append"`,
+// or for slices of structs `merge:"merge=Name"` to merge elements by their Name field.
+type MergeStrategy int
+
+const (
+	// MergeReplace lets a later source replace the value of earlier sources.
+	MergeReplace MergeStrategy = iota
+
+	// MergeAppend appends the elements of a later source to those of earlier sources.
+	// Maps are merged like with MergeKeyed.
+	MergeAppend
+
+	// MergeKeyed merges maps by key and slices by element, letting later sources win.
+	// Slice elements are deduplicated, or matched by a key field given in the merge tag.
+	MergeKeyed
+)
+
+var (
+	_ Source       = (*mergedSources)(nil)
+	_ loaderSource = (*mergedSources)(nil)
+)
+
+// mergedSources loads its sources in order, combining slice and map fields with a default strategy.
+type mergedSources struct {
+	sources  []Source
+	strategy MergeStrategy
+}
+
+// mergeRule is the strategy of a single field.
+type mergeRule struct {
+	// key is the field of struct elements that identifies them when merging slices.
+	key      string
+	strategy MergeStrategy
+}
+
+// MergeSources returns a Source that loads the sources in order and combines slice and map fields
+// with the given strategy, unless a field selects its own by the merge tag. Load uses MergeReplace.
+func MergeSources(strategy MergeStrategy, sources ...Source) Source { //nolint:ireturn // Composes like any Source.
+	return mergedSources{sources: sources, strategy: strategy}
+}
+
+// String returns the name of the strategy as used in the merge tag.
+func (s MergeStrategy) String() string {
+	switch s {
+	case MergeReplace:
+		return "replace"
+	case MergeAppend:
+		return "append"
+	case MergeKeyed:
+		return "merge"
+	default:
+		return "unknown"
+	}
+}
+
+func (m mergedSources) Load(target any) error {
+	return m.loadWith(target, &Loader{})
+}
+
+func (m mergedSources) loadWith(target any, loader *Loader) error {
+	err := validatePointerToStruct(target)
+	if err != nil {
+		return err
+	}
+
+	return loader.loadMerged(target, m.strategy, m.sources)
+}
+
+// loadMerged loads the sources into the target in order of priority. Before every source, the slices and maps
+// that are not replaced are saved, and merged with the loaded values afterwards if an earlier source set them.
+// Values no source set, such as defaults, are replaced instead.
+//
+//nolint:wrapcheck // Errors are already wrapped in sources.
+func (l *Loader) loadMerged(target any, strategy MergeStrategy, sources []Source) error {
+	valueOf := reflect.ValueOf(target).Elem()
+
+	// loaded records the paths of the slices and maps that a source set.
+	loaded := map[string]bool{}
+
+	for _, source := range sortSources(sources) {
+		saved, err := saveCollections(valueOf, strategy)
+		if err != nil {
+			return err
+		}
+
+		err = l.load(source, target)
+		if err != nil {
+			return err
+		}
+
+		walkFields(valueOf, "", "", "", func(field reflect.Value, fieldType reflect.StructField, path, _ string) {
+			previous, ok := saved[path]
+
+			switch {
+			case !ok:
+				if (field.Kind() == reflect.Slice || field.Kind() == reflect.Map) && field.Len() > 0 {
+					loaded[path] = true
+				}
+			case !loaded[path]:
+				loaded[path] = !reflect.DeepEqual(field.Interface(), previous.Interface())
+			default:
+				// The rule was already validated while saving.
+				rule, _ := parseMergeRule(fieldType, strategy)
+				mergeCollection(field, previous, rule)
+			}
+		})
+	}
+
+	return nil
+}
+
+// saveCollections copies every non-empty slice and map that is not replaced by later sources, keyed by field path.
+func saveCollections(valueOf reflect.Value, strategy MergeStrategy) (map[string]reflect.Value, error) {
+	saved := map[string]reflect.Value{}
+
+	var errs []error
+
+	walkFields(valueOf, "", "", "", func(field reflect.Value, fieldType reflect.StructField, path, _ string) {
+		if (field.Kind() != reflect.Slice && field.Kind() != reflect.Map) || field.Len() == 0 {
+			return
+		}
+
+		rule, err := parseMergeRule(fieldType, strategy)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("merge tag of %s: %w", path, err))
+
+			return
+		}
+
+		if rule.strategy == MergeReplace {
+			return
+		}
+
+		// Decoders may reuse the backing array or map of the field, so the values are copied.
+		if field.Kind() == reflect.Slice {
+			saved[path] = reflect.AppendSlice(reflect.MakeSlice(field.Type(), 0, field.Len()), field)
+		} else {
+			saved[path] = cloneMap(field)
+		}
+	})
+
+	return saved, errors.Join(errs...)
+}
+
+// mergeCollection combines the value loaded by a source with the previous one according to the rule.
+// Fields that the source did not change are left alone.
+func mergeCollection(field, previous reflect.Value, rule mergeRule) {
+	if reflect.DeepEqual(field.Interface(), previous.Interface()) {
+		return
+	}
+
+	if field.Kind() == reflect.Map {
+		merged := cloneMap(previous)
+		for iter := field.MapRange(); iter.Next(); {
+			merged.SetMapIndex(iter.Key(), iter.Value())
+		}
+
+		field.Set(merged)
+
+		return
+	}
+
+	if rule.strategy == MergeAppend {
+		field.Set(reflect.AppendSlice(previous, field))
+
+		return
+	}
+
+	merged := previous
+
+	for i := range field.Len() {
+		elem := field.Index(i)
+
+		index := indexOfElement(merged, elem, rule.key)
+		if index < 0 {
+			merged = reflect.Append(merged, elem)
+		} else {
+			merged.Index(index).Set(elem)
+		}
+	}
+
+	field.Set(merged)
+}
+
+// indexOfElement finds an element with the same key field, or an equal element if no key is given.
+func indexOfElement(slice, elem reflect.Value, key string) int {
+	if key != "" {
+		elem = reflect.Indirect(elem)
+		if !elem.IsValid() {
+			return -1
+		}
+
+		elem = elem.FieldByName(key)
+	}
+
+	for i := range slice.Len() {
+		current := slice.Index(i)
+
+		if key != "" {
+			current = reflect.Indirect(current)
+			if !current.IsValid() {
+				continue
+			}
+
+			current = current.FieldByName(key)
+		}
+
+		if reflect.DeepEqual(current.Interface(), elem.Interface()) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func cloneMap(valueOf reflect.Value) reflect.Value {
+	clone := reflect.MakeMapWithSize(valueOf.Type(), valueOf.Len())
+	for iter := valueOf.MapRange(); iter.Next(); {
+		clone.SetMapIndex(iter.Key(), iter.Value())
+	}
+
+	return clone
+}
+
+// parseMergeRule returns the strategy of the merge tag, falling back to the default strategy.
+func parseMergeRule(fieldType reflect.StructField, strategy MergeStrategy) (mergeRule, error) {
+	tag, ok := fieldType.Tag.Lookup("merge")
+	if !ok {
+		return mergeRule{strategy: strategy}, nil
+	}
+
+	name, key, _ := strings.Cut(tag, "=")
+
+	for _, candidate := range []MergeStrategy{MergeReplace, MergeAppend, MergeKeyed} {
+		if name != candidate.String() {
+			continue
+		}
+
+		if key == "" {
+			return mergeRule{strategy: candidate}, nil
+		}
+
+		if candidate != MergeKeyed || fieldType.Type.Kind() != reflect.Slice {
+			return mergeRule{}, fmt.Errorf("%w: a key requires merge on a slice of structs", ErrInvalidTarget)
+		}
+
+		elem := fieldType.Type.Elem()
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+
+		if elem.Kind() != reflect.Struct {
+			return mergeRule{}, fmt.Errorf("%w: a key requires merge on a slice of structs", ErrInvalidTarget)
+		}
+
+		if _, found := elem.FieldByName(key); !found {
+			return mergeRule{}, fmt.Errorf("%w: unknown key field %s in %s", ErrInvalidTarget, key, elem)
+		}
+
+		return mergeRule{strategy: candidate, key: key}, nil
+	}
+
+	return mergeRule{}, fmt.Errorf("%w: unknown merge strategy %q", ErrInvalidTarget, name)
+}
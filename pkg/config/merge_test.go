@@ -0,0 +1,148 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MergeUpstream struct {
+	Name string `json:"name"`
+	Host string `json:"host"`
+}
+
+type MergeConfig struct {
+	Labels         map[string]string `json:"labels"`
+	AllowedOrigins []string          `json:"allowedOrigins" merge:"append"`
+	Hosts          []string          `json:"hosts"`
+	Tags           []string          `json:"tags"           merge:"merge"`
+	Upstreams      []MergeUpstream   `json:"upstreams"      merge:"merge=Name"`
+	Replaced       []string          `json:"replaced"       merge:"replace"`
+}
+
+func (c *MergeConfig) Validate() error {
+	return nil
+}
+
+func TestLoad_Merge(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "base.json")
+	err := os.WriteFile(base, []byte(`{
+		"labels": {"team": "core", "tier": "backend"},
+		"allowedOrigins": ["https://a.example"],
+		"hosts": ["a"],
+		"tags": ["x", "y"],
+		"upstreams": [{"name": "api", "host": "api:80"}, {"name": "auth", "host": "auth:80"}],
+		"replaced": ["old"]
+	}`), 0o600)
+	require.NoError(t, err)
+
+	t.Setenv("APP_ALLOWED_ORIGINS", "https://b.example")
+	t.Setenv("APP_HOSTS", "b")
+	t.Setenv("APP_TAGS", "y,z")
+	t.Setenv("APP_UPSTREAMS", "name=auth|host=auth:8080,name=db|host=db:5432")
+	t.Setenv("APP_REPLACED", "new")
+	t.Setenv("APP_LABELS", "tier=frontend")
+
+	target := &MergeConfig{}
+	err = config.Load(target, config.JSONSource{Path: base}, config.EnvSource{Prefix: "APP"})
+	require.NoError(t, err)
+	assert.Equal(t, &MergeConfig{
+		Labels:         map[string]string{"tier": "frontend"},
+		AllowedOrigins: []string{"https://a.example", "https://b.example"},
+		Hosts:          []string{"b"},
+		Tags:           []string{"x", "y", "z"},
+		Upstreams: []MergeUpstream{
+			{Name: "api", Host: "api:80"},
+			{Name: "auth", Host: "auth:8080"},
+			{Name: "db", Host: "db:5432"},
+		},
+		Replaced: []string{"new"},
+	}, target)
+}
+
+func TestMergeSources(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "base.json")
+	err := os.WriteFile(base, []byte(`{"labels": {"team": "core"}, "hosts": ["a"], "replaced": ["old"]}`), 0o600)
+	require.NoError(t, err)
+
+	t.Setenv("APP_LABELS", "tier=frontend")
+	t.Setenv("APP_HOSTS", "b")
+	t.Setenv("APP_REPLACED", "new")
+
+	target := &MergeConfig{}
+	err = config.Load(target, config.MergeSources(config.MergeAppend,
+		config.JSONSource{Path: base},
+		config.EnvSource{Prefix: "APP"},
+		// An unchanged field is not appended twice.
+		config.EnvSource{Prefix: "UNSET"},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, &MergeConfig{
+		Labels:   map[string]string{"team": "core", "tier": "frontend"},
+		Hosts:    []string{"a", "b"},
+		Replaced: []string{"new"},
+	}, target)
+}
+
+type MergeDefaultsConfig struct {
+	Labels         map[string]string `json:"labels"         merge:"merge"`
+	AllowedOrigins []string          `json:"allowedOrigins" merge:"append"`
+	Tags           []string          `json:"tags"           merge:"merge"`
+}
+
+func (c *MergeDefaultsConfig) SetDefaults() {
+	c.Labels = map[string]string{"team": "core"}
+	c.AllowedOrigins = []string{"*"}
+	c.Tags = []string{"x"}
+}
+
+func (c *MergeDefaultsConfig) Validate() error {
+	return nil
+}
+
+func TestLoad_Merge_Defaults(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "base.json")
+	require.NoError(t, os.WriteFile(base, []byte(`{"tags": ["y"]}`), 0o600))
+
+	t.Setenv("APP_LABELS", "tier=frontend")
+	t.Setenv("APP_ALLOWED_ORIGINS", "https://a.example")
+	t.Setenv("APP_TAGS", "z")
+
+	// Defaults are replaced by the first source setting a field, which later sources merge with.
+	target := &MergeDefaultsConfig{}
+	err := config.Load(target, config.JSONSource{Path: base}, config.EnvSource{Prefix: "APP"})
+	require.NoError(t, err)
+	assert.Equal(t, &MergeDefaultsConfig{
+		Labels:         map[string]string{"tier": "frontend"},
+		AllowedOrigins: []string{"https://a.example"},
+		Tags:           []string{"y", "z"},
+	}, target)
+
+	// Defaults no source sets are kept.
+	target = &MergeDefaultsConfig{}
+	err = config.Load(target, config.JSONSource{Path: base}, config.EnvSource{Prefix: "UNSET"})
+	require.NoError(t, err)
+	assert.Equal(t, &MergeDefaultsConfig{
+		Labels:         map[string]string{"team": "core"},
+		AllowedOrigins: []string{"*"},
+		Tags:           []string{"y"},
+	}, target)
+}
+
+func TestLoad_Merge_InvalidTag(t *testing.T) {
+	type Config struct {
+		MockConfig
+
+		Hosts []string `merge:"merge=Name"`
+	}
+
+	t.Setenv("APP_HOSTS", "a")
+
+	target := &Config{}
+	err := config.Load(target, config.EnvSource{Prefix: "APP"}, config.EnvSource{Prefix: "APP"})
+	require.ErrorIs(t, err, config.ErrInvalidTarget)
+}
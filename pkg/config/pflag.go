@@ -0,0 +1,113 @@
+//go:build with_pflag
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/typeconv"
+	"github.com/spf13/pflag"
+)
+
+var _ pflag.Value = (*pflagValue)(nil)
+
+// pflagValue converts flag values directly into the bound struct field.
+type pflagValue struct {
+	root  reflect.Value
+	field leafField
+
+	// changed is set once the flag was parsed, so repeated slice flags append instead of replacing.
+	changed bool
+}
+
+// BindFlags registers a flag for every field of the target struct on the pflag set, e.g. of a cobra command.
+// Names, nesting and the usage text follow the rules of FlagSource; a single-letter shorthand can be set with
+// the short tag. Parsed values are converted into the target immediately, so only flags that are explicitly set
+// override the values the target held when the flags were bound. Repeated slice flags append to each other.
+func BindFlags(target any, flagSet *pflag.FlagSet) error {
+	err := validatePointerToStruct(target)
+	if err != nil {
+		return err
+	}
+
+	valueOf := reflect.ValueOf(target).Elem()
+
+	for _, field := range leafFields(valueOf.Type(), flagNaming) {
+		if flagSet.Lookup(field.name) != nil {
+			return fmt.Errorf("%w: flag --%s is already defined", ErrInvalidTarget, field.name)
+		}
+
+		shorthand := field.tag.Get("short")
+		if shorthand != "" && flagSet.ShorthandLookup(shorthand) != nil {
+			return fmt.Errorf("%w: flag -%s is already defined", ErrInvalidTarget, shorthand)
+		}
+
+		value := &pflagValue{root: valueOf, field: field}
+
+		registered := flagSet.VarPF(value, field.name, shorthand, field.tag.Get("usage"))
+		if field.typeOf.Kind() == reflect.Bool {
+			registered.NoOptDefVal = "true"
+		}
+	}
+
+	return nil
+}
+
+func (v *pflagValue) Set(value string) error {
+	fieldValue := fieldByIndex(v.root, v.field.index, true)
+
+	if !v.changed || fieldValue.Kind() != reflect.Slice {
+		v.changed = true
+
+		//nolint:wrapcheck // pflag reports the flag name together with the conversion error.
+		return typeconv.Default.ConvertWithTag(fieldValue, value, v.field.tag)
+	}
+
+	elements := reflect.New(fieldValue.Type()).Elem()
+
+	err := typeconv.Default.ConvertWithTag(elements, value, v.field.tag)
+	if err != nil {
+		//nolint:wrapcheck // pflag reports the flag name together with the conversion error.
+		return err
+	}
+
+	fieldValue.Set(reflect.AppendSlice(fieldValue, elements))
+
+	return nil
+}
+
+func (v *pflagValue) String() string {
+	fieldValue := fieldByIndex(v.root, v.field.index, false)
+	if !fieldValue.IsValid() || fieldValue.IsZero() {
+		return ""
+	}
+
+	return fmt.Sprint(fieldValue.Interface())
+}
+
+func (v *pflagValue) Type() string {
+	return pflagTypeName(v.field.typeOf)
+}
+
+// pflagTypeName returns the placeholder shown in the usage text, following the names of the built-in pflag types.
+func pflagTypeName(typeOf reflect.Type) string {
+	switch typeOf {
+	case reflect.TypeFor[time.Duration]():
+		return "duration"
+	case reflect.TypeFor[time.Time]():
+		return "time"
+	}
+
+	switch typeOf.Kind() {
+	case reflect.Ptr:
+		return pflagTypeName(typeOf.Elem())
+	case reflect.Slice, reflect.Array:
+		return pflagTypeName(typeOf.Elem()) + "s"
+	case reflect.Map:
+		return pflagTypeName(typeOf.Key()) + "To" + pflagTypeName(typeOf.Elem())
+	default:
+		return typeOf.Kind().String()
+	}
+}
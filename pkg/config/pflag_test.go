@@ -0,0 +1,112 @@
+//go:build with_pflag
+
+package config_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindFlags(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string `usage:"database host"`
+		Port int
+	}
+
+	type Config struct {
+		Database *Database
+		Name     string        `flag:"app-name" short:"n"`
+		Hosts    []string      `sep:";"`
+		Timeout  time.Duration `usage:"request timeout"`
+		Verbose  bool          `short:"v"`
+	}
+
+	tests := []struct {
+		initial   Config
+		want      Config
+		name      string
+		arguments []string
+		wantErr   bool
+	}{
+		{
+			name:      "successful bind",
+			arguments: []string{"-n", "test-app", "--database-host=localhost", "-v", "--timeout", "5s"},
+			want: Config{
+				Database: &Database{Host: "localhost"},
+				Name:     "test-app",
+				Timeout:  5 * time.Second,
+				Verbose:  true,
+			},
+		},
+		{
+			name:      "repeated slice flags append",
+			arguments: []string{"--hosts", "a;b", "--hosts", "c"},
+			want:      Config{Hosts: []string{"a", "b", "c"}},
+		},
+		{
+			name:      "unset flags keep previous values",
+			initial:   Config{Name: "from-file", Database: &Database{Port: 5432}},
+			arguments: []string{"--database-host", "db"},
+			want:      Config{Name: "from-file", Database: &Database{Host: "db", Port: 5432}},
+		},
+		{
+			name:      "invalid value",
+			arguments: []string{"--database-port", "not-a-number"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flagSet.SetOutput(io.Discard)
+
+			target := tt.initial
+
+			err := config.BindFlags(&target, flagSet)
+			require.NoError(t, err)
+
+			err = flagSet.Parse(tt.arguments)
+			if tt.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, target)
+		})
+	}
+}
+
+func TestBindFlags_Usage(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Hosts   []string
+		Timeout time.Duration `usage:"request timeout"`
+	}
+
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+	err := config.BindFlags(&Config{Timeout: time.Second}, flagSet)
+	require.NoError(t, err)
+
+	timeout := flagSet.Lookup("timeout")
+	assert.Equal(t, "request timeout", timeout.Usage)
+	assert.Equal(t, "1s", timeout.DefValue)
+	assert.Equal(t, "duration", timeout.Value.Type())
+	assert.Equal(t, "strings", flagSet.Lookup("hosts").Value.Type())
+
+	err = config.BindFlags(&Config{}, flagSet)
+	require.ErrorIs(t, err, config.ErrInvalidTarget)
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/log"
+)
+
+// DefaultPollInterval is the interval of a WatchedSource without one.
+const DefaultPollInterval = 30 * time.Second
+
+var (
+	_ Source       = (*WatchedSource)(nil)
+	_ loaderSource = (*WatchedSource)(nil)
+)
+
+// WatchedSource decorates a source that cannot announce changes itself, such as a file or a remote endpoint,
+// by fetching it periodically. Its Watch method is a Trigger for a Watcher that only fires if the values
+// the source provides differ from the last fetch. Load fetches the source twice, once alone to record its values.
+type WatchedSource struct {
+	// Log receives failures of periodic fetches, which keep the last values.
+	// Default is slog.Default().
+	Log log.Logger
+
+	source Source
+	loader *Loader
+
+	// typeOf is the target type of the last Load, which periodic fetches are loaded into.
+	typeOf reflect.Type
+
+	// last holds the values of the last fetch, loaded into a fresh value of the target type.
+	last reflect.Value
+
+	interval time.Duration
+
+	// mutex guards the fields describing the last load.
+	mutex sync.Mutex
+}
+
+// WatchSource returns a WatchedSource fetching the source every interval.
+// A non-positive interval selects DefaultPollInterval.
+func WatchSource(source Source, interval time.Duration) *WatchedSource {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	return &WatchedSource{
+		Log:      slog.Default(),
+		source:   source,
+		interval: interval,
+	}
+}
+
+func (s *WatchedSource) Load(target any) error {
+	return s.loadWith(target, &Loader{})
+}
+
+// Watch fetches the source every interval and calls reload whenever its values changed since the last fetch.
+// The target type is taken from the last Load, so the configuration must be loaded before.
+// It blocks until the context is canceled, which is not reported as an error.
+func (s *WatchedSource) Watch(ctx context.Context, reload func()) error {
+	s.mutex.Lock()
+	started := s.typeOf != nil
+	s.mutex.Unlock()
+
+	if !started {
+		return fmt.Errorf("%w: watched source must be loaded before it is watched", ErrInvalidTarget)
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			changed, err := s.poll()
+			if err != nil {
+				s.Log.Warn("failed to fetch watched configuration source", "error", err)
+			} else if changed {
+				reload()
+			}
+		}
+	}
+}
+
+// fetch loads the source alone into a fresh value of the given type.
+func (s *WatchedSource) fetch(typeOf reflect.Type, loader *Loader) (reflect.Value, error) {
+	value := reflect.New(typeOf)
+
+	err := loader.load(s.source, value.Interface())
+	if err != nil {
+		return reflect.Value{}, err //nolint:wrapcheck // Errors are already wrapped in sources.
+	}
+
+	return value.Elem(), nil
+}
+
+func (s *WatchedSource) loadWith(target any, loader *Loader) error {
+	err := validatePointerToStruct(target)
+	if err != nil {
+		return err
+	}
+
+	typeOf := reflect.TypeOf(target).Elem()
+
+	last, err := s.fetch(typeOf, loader)
+	if err != nil {
+		return err
+	}
+
+	err = loader.load(s.source, target)
+	if err != nil {
+		return err //nolint:wrapcheck // Errors are already wrapped in sources.
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.last, s.typeOf, s.loader = last, typeOf, loader
+
+	return nil
+}
+
+// poll fetches the source and reports whether its values differ from the last fetch.
+func (s *WatchedSource) poll() (bool, error) {
+	s.mutex.Lock()
+	typeOf, loader := s.typeOf, s.loader
+	s.mutex.Unlock()
+
+	next, err := s.fetch(typeOf, loader)
+	if err != nil {
+		return false, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	changed := !reflect.DeepEqual(s.last.Interface(), next.Interface())
+	s.last = next
+
+	return changed, nil
+}
@@ -0,0 +1,60 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchedSource_Watch(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	writeFile := func(content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	}
+
+	writeFile(`{"name": "first"}`)
+
+	source := config.WatchSource(config.JSONSource{Path: file}, 5*time.Millisecond)
+
+	// Watching requires the target type of a previous load.
+	require.ErrorIs(t, source.Watch(t.Context(), func() {}), config.ErrInvalidTarget)
+
+	store := config.NewStore[MockConfig](nil)
+	watcher := config.NewWatcher(store, config.EnvSource{Prefix: "POLL"}, source)
+	require.NoError(t, watcher.Reload())
+
+	var reloads atomic.Int32
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- source.Watch(ctx, func() {
+			reloads.Add(1)
+			assert.NoError(t, watcher.Reload())
+		})
+	}()
+
+	// Unchanged content does not trigger a reload.
+	time.Sleep(30 * time.Millisecond)
+	assert.Zero(t, reloads.Load())
+
+	writeFile(`{"name": "second"}`)
+	require.Eventually(t, func() bool {
+		return store.Get().Name == "second"
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, int32(1), reloads.Load())
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"cmp"
+	"slices"
+)
+
+var (
+	_ Source       = (*prioritizedSource)(nil)
+	_ loaderSource = (*prioritizedSource)(nil)
+	_ Source       = (*conditionalSource)(nil)
+	_ loaderSource = (*conditionalSource)(nil)
+)
+
+// prioritizedSource assigns a priority to a source, see Priority.
+type prioritizedSource struct {
+	source   Source
+	priority int
+}
+
+// conditionalSource loads a source only if its condition holds, see When.
+type conditionalSource struct {
+	source    Source
+	condition func() bool
+}
+
+// Priority returns a Source that loads the source with the given priority. Load and MergeSources load sources
+// in ascending order of priority, so sources with a higher priority override those with a lower one.
+// Sources without a priority have priority 0; sources of equal priority keep their argument order.
+func Priority(priority int, source Source) Source { //nolint:ireturn // Composes like any Source.
+	return prioritizedSource{source: source, priority: priority}
+}
+
+// When returns a Source that only loads the source if the condition holds, e.g. to use a remote source
+// only if its address is configured. The condition is evaluated on every load, including reloads.
+func When(condition func() bool, source Source) Source { //nolint:ireturn // Composes like any Source.
+	return conditionalSource{source: source, condition: condition}
+}
+
+func (s conditionalSource) Load(target any) error {
+	return s.loadWith(target, &Loader{})
+}
+
+func (s conditionalSource) loadWith(target any, loader *Loader) error {
+	if !s.condition() {
+		return nil
+	}
+
+	return loader.load(s.source, target)
+}
+
+func (s prioritizedSource) Load(target any) error {
+	return s.loadWith(target, &Loader{})
+}
+
+func (s prioritizedSource) loadWith(target any, loader *Loader) error {
+	return loader.load(s.source, target)
+}
+
+// sortSources returns the sources in ascending order of priority, keeping the argument order of equal priorities.
+func sortSources(sources []Source) []Source {
+	sorted := slices.Clone(sources)
+	slices.SortStableFunc(sorted, func(a, b Source) int {
+		return cmp.Compare(sourcePriority(a), sourcePriority(b))
+	})
+
+	return sorted
+}
+
+// sourcePriority returns the priority of the outermost Priority wrapping the source, or 0.
+func sourcePriority(source Source) int {
+	for {
+		switch wrapped := source.(type) {
+		case prioritizedSource:
+			return wrapped.priority
+		case conditionalSource:
+			source = wrapped.source
+		default:
+			return 0
+		}
+	}
+}
+
+// unwrapSource returns the source wrapped by Priority and When.
+func unwrapSource(source Source) Source { //nolint:ireturn // Returns the wrapped source of any type.
+	for {
+		switch wrapped := source.(type) {
+		case prioritizedSource:
+			source = wrapped.source
+		case conditionalSource:
+			source = wrapped.source
+		default:
+			return source
+		}
+	}
+}
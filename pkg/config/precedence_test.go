@@ -0,0 +1,80 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.json":     `{"name": "base", "port": 8080}`,
+		"override.json": `{"name": "override"}`,
+		"remote.json":   `{"port": 9090}`,
+	}
+
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	source := func(name string) config.Source {
+		return config.JSONSource{Path: filepath.Join(dir, name)}
+	}
+
+	t.Setenv("APP_PORT", "7070")
+
+	tests := []struct {
+		want    *MockConfig
+		name    string
+		sources []config.Source
+	}{
+		{
+			name:    "argument order",
+			sources: []config.Source{source("base.json"), source("override.json")},
+			want:    &MockConfig{Name: "override", Port: 8080},
+		},
+		{
+			name:    "priority overrides argument order",
+			sources: []config.Source{config.Priority(10, source("override.json")), source("base.json")},
+			want:    &MockConfig{Name: "override", Port: 8080},
+		},
+		{
+			name: "negative priority loads first",
+			sources: []config.Source{
+				config.EnvSource{Prefix: "APP"},
+				config.Priority(-1, source("base.json")),
+			},
+			want: &MockConfig{Name: "base", Port: 7070},
+		},
+		{
+			name: "skipped condition",
+			sources: []config.Source{
+				source("base.json"),
+				config.When(func() bool { return false }, source("remote.json")),
+			},
+			want: &MockConfig{Name: "base", Port: 8080},
+		},
+		{
+			name: "prioritized condition",
+			sources: []config.Source{
+				config.When(func() bool { return true }, config.Priority(1, source("remote.json"))),
+				source("base.json"),
+			},
+			want: &MockConfig{Name: "base", Port: 9090},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &MockConfig{}
+
+			err := config.Load(target, tt.sources...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, target)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	_ Source       = (*ReaderSource)(nil)
+	_ loaderSource = (*ReaderSource)(nil)
+
+	// tomlKeyPattern matches a TOML key/value pair or table header at the start of a line.
+	tomlKeyPattern = regexp.MustCompile(`^(\[[^\]]+\]|[A-Za-z0-9_.\-"']+\s*=)`)
+)
+
+// ReaderSource loads configuration from a reader, e.g. piped into the process.
+// The reader is consumed by the first Load, so the source cannot be reloaded.
+type ReaderSource struct {
+	Reader io.Reader
+
+	// Format of the content. YAML and TOML are only available if built with their tags.
+	// Default is to detect the format from the content: documents starting with { are JSON,
+	// documents whose first line is a TOML key/value pair or table header are TOML, and all others YAML.
+	Format Format
+
+	// ExpandEnv expands ${VAR}, ${VAR:-default} and ${VAR-default} in string values with environment variables.
+	ExpandEnv bool
+
+	// Strict rejects keys that match no field of the target, see JSONSource.Strict.
+	Strict bool
+}
+
+// StdinSource returns a ReaderSource reading the configuration from standard input.
+func StdinSource() ReaderSource {
+	return ReaderSource{Reader: os.Stdin}
+}
+
+func (s ReaderSource) Load(target any) error {
+	return s.loadWith(target, &Loader{})
+}
+
+func (s ReaderSource) loadWith(target any, loader *Loader) error {
+	if s.Reader == nil {
+		return fmt.Errorf("%w: reader cannot be nil", ErrInvalidConfig)
+	}
+
+	data, err := io.ReadAll(s.Reader)
+	if err != nil {
+		return fmt.Errorf("%w: read config: %w", ErrConfigNotFound, err)
+	}
+
+	format := s.Format
+	if format == "" {
+		format = detectFormat(data)
+	}
+
+	formatCodec, ok := formatCodecs[format]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+
+	keyedCodec := formatCodec.withKey(loader.tag(formatCodec.tag))
+	keyedCodec.strict = s.Strict || loader.Strict
+
+	if rewrite := loader.rewriter(s.ExpandEnv); rewrite != nil {
+		data, err = keyedCodec.rewrite(data, rewrite)
+		if err != nil {
+			return fmt.Errorf("%w: unmarshal %s: %w", ErrInvalidConfig, format, err)
+		}
+	}
+
+	err = keyedCodec.decode(data, target)
+	if err != nil {
+		return fmt.Errorf("%w: unmarshal %s: %w", ErrInvalidConfig, format, err)
+	}
+
+	return nil
+}
+
+// detectFormat guesses the format of a document from its first line that is neither blank nor a comment.
+func detectFormat(data []byte) Format {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return FormatJSON
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if tomlKeyPattern.MatchString(line) {
+			return FormatTOML
+		}
+
+		break
+	}
+
+	return FormatYAML
+}
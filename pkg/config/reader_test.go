@@ -0,0 +1,65 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaderSource_Load(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		want    *MockConfig
+		wantErr error
+		name    string
+		content string
+		format  config.Format
+	}{
+		{
+			name:    "explicit format",
+			content: `{"name": "test-app", "port": 8080}`,
+			format:  config.FormatJSON,
+			want:    &MockConfig{Name: "test-app", Port: 8080},
+		},
+		{
+			name:    "detected JSON",
+			content: "\n  {\"name\": \"test-app\"}",
+			want:    &MockConfig{Name: "test-app"},
+		},
+		{
+			name:    "invalid content",
+			content: `{invalid json}`,
+			wantErr: config.ErrInvalidConfig,
+		},
+		{
+			name:    "unsupported format",
+			content: `{}`,
+			format:  "ini",
+			wantErr: config.ErrUnsupportedFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			target := &MockConfig{}
+
+			err := config.ReaderSource{Reader: strings.NewReader(tt.content), Format: tt.format}.Load(target)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, target)
+		})
+	}
+
+	err := config.ReaderSource{}.Load(&MockConfig{})
+	require.ErrorIs(t, err, config.ErrInvalidConfig)
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"time"
+)
+
+var (
+	_ fmt.Stringer   = (*RedactedConfig)(nil)
+	_ slog.LogValuer = (*RedactedConfig)(nil)
+)
+
+// RedactedConfig wraps a configuration to print and log it with secret fields masked.
+type RedactedConfig struct {
+	target any
+}
+
+// Redacted wraps the configuration for printing and logging, replacing the values of fields tagged
+// `secret:"true"` by RedactedValue. Its String method returns compact JSON named like Dump,
+// and as a slog.LogValuer it logs the configuration as a group of attributes named by the json tags.
+// The configuration itself is left intact. A Stringer of a configuration can be implemented with it:
+//
+//	func (c *Config) String() string { return config.Redacted(c).String() }
+func Redacted(target any) RedactedConfig {
+	return RedactedConfig{target: target}
+}
+
+func (r RedactedConfig) LogValue() slog.Value {
+	return redactedLogValue(reflect.ValueOf(r.target))
+}
+
+func (r RedactedConfig) String() string {
+	valueOf := reflect.Indirect(reflect.ValueOf(r.target))
+	if valueOf.Kind() != reflect.Struct {
+		return fmt.Sprintf("%%!config(%T)", r.target)
+	}
+
+	data, err := json.Marshal(dumpValue(valueOf, jsonCodec.tag, jsonCodec.tag).Interface())
+	if err != nil {
+		return fmt.Sprintf("%%!config(%v)", err)
+	}
+
+	return string(data)
+}
+
+// redactedLogValue converts the value into a slog value, turning structs into groups.
+func redactedLogValue(valueOf reflect.Value) slog.Value {
+	if valueOf.Kind() == reflect.Interface || valueOf.Kind() == reflect.Ptr {
+		if valueOf.IsNil() {
+			return slog.AnyValue(nil)
+		}
+
+		return redactedLogValue(valueOf.Elem())
+	}
+
+	switch {
+	case valueOf.Type() == reflect.TypeFor[time.Duration]():
+		return slog.DurationValue(time.Duration(valueOf.Int()))
+	case valueOf.Kind() == reflect.Struct && !isDumpLeaf(valueOf.Type()):
+		entries := dumpEntries(valueOf, jsonCodec.tag)
+
+		attrs := make([]slog.Attr, 0, len(entries))
+		for _, entry := range entries {
+			if entry.secret {
+				attrs = append(attrs, slog.String(entry.name, RedactedValue))
+			} else {
+				attrs = append(attrs, slog.Attr{Key: entry.name, Value: redactedLogValue(entry.field)})
+			}
+		}
+
+		return slog.GroupValue(attrs...)
+	case valueOf.Kind() == reflect.Slice || valueOf.Kind() == reflect.Array || valueOf.Kind() == reflect.Map:
+		// Collections may hold structs with secret fields, so they are converted like in Dump.
+		if dumped := dumpValue(valueOf, jsonCodec.tag, jsonCodec.tag); dumped.IsValid() {
+			return slog.AnyValue(dumped.Interface())
+		}
+
+		return slog.AnyValue(nil)
+	default:
+		return slog.AnyValue(valueOf.Interface())
+	}
+}
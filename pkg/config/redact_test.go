@@ -0,0 +1,74 @@
+package config_test
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type SecretCredentials struct {
+	User     string `json:"user"`
+	Password string `json:"password" secret:"true"`
+}
+
+type SecretConfig struct {
+	Database  *SecretCredentials  `json:"database"`
+	Token     string              `json:"token"   secret:"true"`
+	Unset     string              `json:"unset"   secret:"true"`
+	Upstreams []SecretCredentials `json:"upstreams"`
+	Timeout   time.Duration       `json:"timeout"`
+}
+
+func testSecretConfig() *SecretConfig {
+	return &SecretConfig{
+		Database:  &SecretCredentials{User: "app", Password: "hunter2"},
+		Upstreams: []SecretCredentials{{User: "api", Password: "s3cr3t"}},
+		Token:     "token",
+		Timeout:   time.Second,
+	}
+}
+
+func TestDump_Secret(t *testing.T) {
+	t.Parallel()
+
+	target := testSecretConfig()
+
+	data, err := config.Dump(target, config.FormatJSON)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+	assert.NotContains(t, string(data), "s3cr3t")
+	assert.Contains(t, string(data), `"token": "*****"`)
+	assert.Contains(t, string(data), `"unset": ""`)
+
+	// The configuration itself is left intact.
+	assert.Equal(t, "hunter2", target.Database.Password)
+}
+
+func TestRedacted(t *testing.T) {
+	t.Parallel()
+
+	target := testSecretConfig()
+
+	assert.JSONEq(t, `{
+		"database": {"user": "app", "password": "*****"},
+		"upstreams": [{"user": "api", "password": "*****"}],
+		"token": "*****",
+		"unset": "",
+		"timeout": "1s"
+	}`, fmt.Sprint(config.Redacted(target)))
+
+	var buf bytes.Buffer
+
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("loaded", "config", config.Redacted(target))
+	assert.Contains(t, buf.String(), `"config":{"database":{"user":"app","password":"*****"},`)
+	assert.Contains(t, buf.String(), `"upstreams":[{"user":"api","password":"*****"}]`)
+	assert.Contains(t, buf.String(), `"token":"*****","unset":"",`)
+	assert.Contains(t, buf.String(), `"timeout":1000000000}`)
+	assert.NotContains(t, buf.String(), "hunter2")
+}
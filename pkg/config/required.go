@@ -0,0 +1,80 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var ErrRequired = errors.New("config: required field missing")
+
+// checkRequired reports every field tagged `required:"true"` that is still zero after all sources were loaded.
+// Errors name the environment variable with the given prefix, as a hint where to set it.
+func (l *Loader) checkRequired(target any, prefix string) error {
+	var errs []error
+
+	check := func(field reflect.Value, fieldType reflect.StructField, path, envName string) {
+		required, _ := strconv.ParseBool(fieldType.Tag.Get("required"))
+		if required && field.IsZero() {
+			errs = append(errs, fmt.Errorf("%w: %s (env %s)", ErrRequired, path, envName))
+		}
+	}
+
+	walkFields(reflect.ValueOf(target).Elem(), "", prefix, l.tag("env"), check)
+
+	return errors.Join(errs...)
+}
+
+// envPrefix returns the prefix of the first EnvSource, so errors can name the environment variable of a field.
+func envPrefix(sources []Source) string {
+	for _, source := range sources {
+		if envSource, ok := unwrapSource(source).(EnvSource); ok {
+			return envSource.prefix()
+		}
+	}
+
+	return ""
+}
+
+// walkFields calls fn for every exported field, descending into nested structs and non-nil pointers to structs.
+// Fields are identified by their dotted Go path and the environment variable EnvSource reads them from,
+// whose name is taken from the tag envKey.
+func walkFields(
+	valueOf reflect.Value,
+	path, envPrefix, envKey string,
+	fn func(field reflect.Value, fieldType reflect.StructField, path, envName string),
+) {
+	typeOf := valueOf.Type()
+
+	for i := range valueOf.NumField() {
+		field := valueOf.Field(i)
+		fieldType := typeOf.Field(i)
+
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		envTag, _ := lookupTag(fieldType, envKey)
+
+		envName := createEnvName(envPrefix, fieldType.Name, envTag)
+		if isSquashed(fieldType, envTag, envKey) {
+			envName = envPrefix
+		}
+
+		fieldPath := fieldType.Name
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+
+		fn(field, fieldType, fieldPath, envName)
+
+		if field.Kind() == reflect.Ptr && !field.IsNil() {
+			field = field.Elem()
+		}
+
+		if field.Kind() == reflect.Struct {
+			walkFields(field, fieldPath, envName, envKey, fn)
+		}
+	}
+}
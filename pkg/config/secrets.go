@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultSecretsPath is the directory Docker and Kubernetes conventionally mount secrets into.
+const DefaultSecretsPath = "/run/secrets"
+
+var (
+	_ Source       = (*SecretsDirSource)(nil)
+	_ loaderSource = (*SecretsDirSource)(nil)
+)
+
+// SecretsDirSource loads configuration from a directory of secret files, one value per file.
+// File names are matched case-insensitively against the variable names of EnvSource, e.g. a file
+// database_password sets the field Database.Password. Contents are trimmed of surrounding whitespace.
+// Hidden files, such as the ..data links of Kubernetes volumes, and directories are skipped.
+type SecretsDirSource struct {
+	// Path is the directory holding the secret files.
+	// Default is DefaultSecretsPath.
+	Path string
+
+	// Prefix is an optional application prefix of the file names like EnvSource.Prefix.
+	Prefix string
+
+	// Optional lets Load succeed without loading anything if the directory does not exist.
+	Optional bool
+}
+
+func (s SecretsDirSource) Load(target any) error {
+	return s.loadWith(target, &Loader{})
+}
+
+//nolint:wrapcheck // Errors are already wrapped in EnvSource.
+func (s SecretsDirSource) loadWith(target any, loader *Loader) error {
+	err := validatePointerToStruct(target)
+	if err != nil {
+		return err
+	}
+
+	path := s.Path
+	if path == "" {
+		path = DefaultSecretsPath
+	}
+
+	entries, err := os.ReadDir(path)
+	if errors.Is(err, fs.ErrNotExist) && s.Optional {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: read secrets directory: %w", ErrConfigNotFound, err)
+	}
+
+	values := make(map[string]string, len(entries))
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		file := filepath.Join(path, entry.Name())
+
+		// Secrets of Kubernetes volumes are links, which are resolved to check for directories.
+		info, err := os.Stat(file)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("%w: read secret %s: %w", ErrInvalidConfig, entry.Name(), err)
+		}
+
+		values[strings.ToUpper(entry.Name())] = strings.TrimSpace(string(data))
+	}
+
+	return EnvSource{Prefix: s.Prefix, values: values}.loadWith(target, loader)
+}
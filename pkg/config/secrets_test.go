@@ -0,0 +1,79 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsDirSource_Load(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Password string
+	}
+
+	type Config struct {
+		Database *Database
+		Token    string `env:"API_TOKEN"`
+		Name     string
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"app_database_password": "s3cr3t\n",
+		"APP_API_TOKEN":         " token ",
+		".hidden":               "ignored",
+		"other_name":            "ignored",
+	}
+
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "app_name"), 0o700))
+
+	tests := []struct {
+		want    *Config
+		wantErr error
+		name    string
+		source  config.SecretsDirSource
+	}{
+		{
+			name:   "secret files",
+			source: config.SecretsDirSource{Path: dir, Prefix: "app"},
+			want:   &Config{Database: &Database{Password: "s3cr3t"}, Token: "token"},
+		},
+		{
+			name:    "directory not found",
+			source:  config.SecretsDirSource{Path: filepath.Join(dir, "non-existent")},
+			wantErr: config.ErrConfigNotFound,
+		},
+		{
+			name:   "optional directory not found",
+			source: config.SecretsDirSource{Path: filepath.Join(dir, "non-existent"), Optional: true},
+			want:   &Config{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			target := &Config{}
+
+			err := tt.source.Load(target)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, target)
+		})
+	}
+}
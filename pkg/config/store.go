@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Subscriber is notified after a Store switched to a new snapshot.
+// The previous snapshot is nil on the first update of an empty store.
+type Subscriber[T any] func(previous, current *T)
+
+// Store holds the current configuration snapshot behind an atomic pointer.
+// Readers always observe a complete, validated configuration, even while a reload is in progress.
+// Snapshots must be treated as read-only; updates replace the snapshot as a whole.
+type Store[T any] struct {
+	// Loader loads the snapshots of Load and of watchers of the store.
+	// Default is the zero Loader, which behaves like Load.
+	Loader *Loader
+
+	current atomic.Pointer[T]
+
+	subscribers []subscription[T]
+
+	// mutex serializes updates and guards the subscribers.
+	mutex sync.Mutex
+
+	nextID uint64
+}
+
+type subscription[T any] struct {
+	fn Subscriber[T]
+	id uint64
+}
+
+// NewStore creates a new Store holding the given initial snapshot, which may be nil.
+func NewStore[T any](initial *T) *Store[T] {
+	store := &Store[T]{}
+	store.current.Store(initial)
+
+	return store
+}
+
+// Get returns the current snapshot without locking.
+func (s *Store[T]) Get() *T {
+	return s.current.Load()
+}
+
+// Load loads a fresh snapshot from the sources, applying defaults and validation like Load,
+// and replaces the current snapshot only if loading succeeds. T must implement Validatable through its pointer.
+func (s *Store[T]) Load(sources ...Source) error {
+	next, err := s.loadSnapshot(sources)
+	if err != nil {
+		return err
+	}
+
+	s.Swap(next)
+
+	return nil
+}
+
+// Subscribe registers a function that is called after every update.
+// The returned function removes the subscription.
+func (s *Store[T]) Subscribe(fn Subscriber[T]) (unsubscribe func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.subscribers = append(s.subscribers, subscription[T]{fn: fn, id: id})
+
+	return func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		s.subscribers = slices.DeleteFunc(s.subscribers, func(sub subscription[T]) bool {
+			return sub.id == id
+		})
+	}
+}
+
+// Swap replaces the current snapshot, notifies all subscribers in subscription order and returns the previous snapshot.
+// Readers switch to the new snapshot atomically.
+// Subscribers must not call Swap, Load or Subscribe of the same store.
+func (s *Store[T]) Swap(next *T) (previous *T) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous = s.current.Swap(next)
+
+	for _, subscriber := range s.subscribers {
+		subscriber.fn(previous, next)
+	}
+
+	return previous
+}
+
+// loadSnapshot loads a fresh, validated snapshot from the sources with the loader of the store.
+func (s *Store[T]) loadSnapshot(sources []Source) (*T, error) {
+	next := new(T)
+
+	target, ok := any(next).(Validatable)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T does not implement Validatable", ErrInvalidTarget, next)
+	}
+
+	loader := s.Loader
+	if loader == nil {
+		loader = &Loader{}
+	}
+
+	err := loader.Load(target, sources...)
+	if err != nil {
+		return nil, err
+	}
+
+	return next, nil
+}
@@ -0,0 +1,106 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	store := config.NewStore[MockConfig](nil)
+	assert.Nil(t, store.Get())
+
+	var calls [][2]*MockConfig
+
+	unsubscribe := store.Subscribe(func(previous, current *MockConfig) {
+		calls = append(calls, [2]*MockConfig{previous, current})
+	})
+
+	first := &MockConfig{Name: "first"}
+	assert.Nil(t, store.Swap(first))
+	assert.Same(t, first, store.Get())
+
+	unsubscribe()
+	unsubscribe()
+
+	second := &MockConfig{Name: "second"}
+	assert.Same(t, first, store.Swap(second))
+	assert.Same(t, second, store.Get())
+
+	assert.Equal(t, [][2]*MockConfig{{nil, first}}, calls)
+}
+
+func TestStore_Load(t *testing.T) {
+	t.Parallel()
+
+	validFile := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(validFile, []byte(`{"name": "test-app", "port": 8080}`), 0o600)
+	require.NoError(t, err)
+
+	initial := &MockConfig{Name: "initial"}
+	store := config.NewStore(initial)
+
+	err = store.Load(config.JSONSource{Path: "non-existent"})
+	require.ErrorIs(t, err, config.ErrConfigNotFound)
+	assert.Same(t, initial, store.Get())
+
+	err = store.Load(config.JSONSource{Path: validFile})
+	require.NoError(t, err)
+	assert.Equal(t, &MockConfig{Name: "test-app", Port: 8080}, store.Get())
+
+	err = config.NewStore[struct{}](nil).Load()
+	require.ErrorIs(t, err, config.ErrInvalidTarget)
+
+	// The loader of the store applies to Load.
+	store.Loader = &config.Loader{Strict: true}
+	err = os.WriteFile(validFile, []byte(`{"name": "test-app", "prot": 8080}`), 0o600)
+	require.NoError(t, err)
+
+	err = store.Load(config.JSONSource{Path: validFile})
+	require.ErrorIs(t, err, config.ErrUnknownKey)
+	assert.Equal(t, &MockConfig{Name: "test-app", Port: 8080}, store.Get())
+}
+
+func TestStore_Swap(t *testing.T) {
+	t.Parallel()
+
+	first := &MockConfig{Name: "first"}
+	store := config.NewStore(first)
+
+	var notified *MockConfig
+
+	store.Subscribe(func(_, current *MockConfig) {
+		notified = current
+	})
+
+	second := &MockConfig{Name: "second"}
+	assert.Same(t, first, store.Swap(second))
+	assert.Same(t, second, store.Get())
+	assert.Same(t, second, notified)
+}
+
+func TestStore_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	store := config.NewStore(&MockConfig{})
+
+	var waitGroup sync.WaitGroup
+
+	for i := range 8 {
+		waitGroup.Go(func() {
+			store.Swap(&MockConfig{Port: i})
+		})
+		waitGroup.Go(func() {
+			assert.NotNil(t, store.Get())
+		})
+	}
+
+	waitGroup.Wait()
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var ErrUnknownKey = errors.New("config: unknown key")
+
+// checkKeys reports every key of the raw document that no field of the target is decoded from.
+func (c codec) checkKeys(data []byte, target any) error {
+	typeOf := reflect.TypeOf(target)
+	if typeOf.Kind() != reflect.Ptr || typeOf.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	var raw map[string]any
+
+	err := c.unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	unknown := c.unknownKeys(typeOf.Elem(), raw, "")
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownKey, strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+// knownFields maps the lower-cased names a struct type is decoded from to the types of their fields.
+// Fields are named by the native tag or their Go name, and additionally by the shared or replaced tag.
+// Embedded structs without a name are flattened into the parent.
+func (c codec) knownFields(typeOf reflect.Type, fields map[string]reflect.Type) {
+	for i := range typeOf.NumField() {
+		fieldType := typeOf.Field(i)
+
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		native, _, _ := strings.Cut(fieldType.Tag.Get(c.tag), ",")
+
+		shared, _ := lookupTag(fieldType, c.key)
+
+		if fieldType.Anonymous && native == "" && shared == "" && isStructOrStructPtr(fieldType.Type) {
+			embedded := fieldType.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+
+			c.knownFields(embedded, fields)
+
+			continue
+		}
+
+		if native == "" {
+			native = fieldType.Name
+		}
+
+		if native != "-" {
+			fields[strings.ToLower(native)] = fieldType.Type
+		}
+
+		if shared != "" && shared != "-" {
+			fields[strings.ToLower(shared)] = fieldType.Type
+		}
+	}
+}
+
+// unknownKeys returns the dotted paths of the keys in the raw map that match no field of the struct type,
+// matching keys case-insensitively like the decoders do.
+func (c codec) unknownKeys(typeOf reflect.Type, raw map[string]any, path string) []string {
+	fields := map[string]reflect.Type{}
+	c.knownFields(typeOf, fields)
+
+	var unknown []string
+
+	for _, key := range slices.Sorted(maps.Keys(raw)) {
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+
+		fieldType, ok := fields[strings.ToLower(key)]
+		if !ok {
+			unknown = append(unknown, keyPath)
+
+			continue
+		}
+
+		unknown = append(unknown, c.unknownNested(fieldType, raw[key], keyPath)...)
+	}
+
+	return unknown
+}
+
+// unknownNested descends into the elements of slices and maps and into nested structs.
+func (c codec) unknownNested(typeOf reflect.Type, value any, path string) []string {
+	for typeOf.Kind() == reflect.Ptr {
+		typeOf = typeOf.Elem()
+	}
+
+	var unknown []string
+
+	switch value := value.(type) {
+	case map[string]any:
+		switch typeOf.Kind() {
+		case reflect.Struct:
+			if !isDumpLeaf(typeOf) {
+				unknown = c.unknownKeys(typeOf, value, path)
+			}
+		case reflect.Map:
+			for _, key := range slices.Sorted(maps.Keys(value)) {
+				unknown = append(unknown, c.unknownNested(typeOf.Elem(), value[key], path+"."+key)...)
+			}
+		default:
+		}
+	case []any:
+		if typeOf.Kind() == reflect.Slice || typeOf.Kind() == reflect.Array {
+			for i, elem := range value {
+				unknown = append(unknown, c.unknownNested(typeOf.Elem(), elem, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+
+	return unknown
+}
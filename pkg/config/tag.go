@@ -0,0 +1,251 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TagName is the shared struct tag consulted when a source-specific tag (env, flag, json, toml, yaml) is absent.
+// It allows a single `config:"name"` annotation to serve every source.
+const TagName = "config"
+
+// codec describes how a file source decodes and re-encodes its format.
+type codec struct {
+	unmarshal func(data []byte, target any) error
+	marshal   func(value any) ([]byte, error)
+	tag       string
+
+	// key is the tag fields are matched by instead of the native tag, see Loader.Tags.
+	// The native decoder keeps honoring the native tag. Default is the native tag.
+	key string
+
+	// strict rejects keys that match no field of the target, see JSONSource.Strict.
+	strict bool
+
+	// tableRoot is set for formats whose documents must be tables, such as TOML.
+	// Values are then wrapped in a single-key table before being re-encoded.
+	tableRoot bool
+}
+
+// lookupTag returns the field name declared in the given tag namespace,
+// falling back to the shared config tag. Options after a comma are dropped.
+func lookupTag(field reflect.StructField, key string) (name string, ok bool) {
+	tag, ok := field.Tag.Lookup(key)
+	if !ok {
+		tag, ok = field.Tag.Lookup(TagName)
+	}
+
+	name, _, _ = strings.Cut(tag, ",")
+
+	return name, ok
+}
+
+// withKey returns a copy of the codec matching fields by the given tag.
+func (c codec) withKey(key string) codec {
+	c.key = key
+
+	return c
+}
+
+// decode unmarshals data into target using the codec's native tags and afterwards
+// fills fields that only carry a shared config tag. Note that `config:"-"` only
+// excludes a field from shared-tag resolution; use the native tag to hide it from the decoder.
+func (c codec) decode(data []byte, target any) error {
+	if c.key == "" {
+		c.key = c.tag
+	}
+
+	if c.strict {
+		err := c.checkKeys(data, target)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := c.unmarshal(data, target)
+	if err != nil {
+		return err
+	}
+
+	valueOf := reflect.ValueOf(target)
+	if valueOf.Kind() != reflect.Ptr || valueOf.IsNil() || valueOf.Elem().Kind() != reflect.Struct ||
+		!c.hasSharedTags(valueOf.Elem().Type(), map[reflect.Type]bool{}) {
+		return nil
+	}
+
+	var raw map[string]any
+
+	err = c.unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	return c.applySharedTags(valueOf.Elem(), raw)
+}
+
+// applySharedTags walks the struct and assigns raw values to fields whose name is taken from the shared config tag,
+// or from the replaced tag of the codec key.
+func (c codec) applySharedTags(valueOf reflect.Value, raw map[string]any) error {
+	typeOf := valueOf.Type()
+
+	for i := range valueOf.NumField() {
+		field := valueOf.Field(i)
+		fieldType := typeOf.Field(i)
+
+		if !field.CanSet() {
+			continue
+		}
+
+		_, native := fieldType.Tag.Lookup(c.tag)
+		_, replaced := fieldType.Tag.Lookup(c.key)
+		replaced = replaced && c.key != c.tag
+
+		name, tagged := lookupTag(fieldType, c.key)
+		if name == "-" {
+			continue
+		}
+
+		// Embedded structs without an explicit name are flattened into the parent.
+		if fieldType.Anonymous && name == "" && field.Kind() == reflect.Struct {
+			err := c.applySharedTags(field, raw)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if name == "" {
+			name = fieldType.Name
+		}
+
+		value, ok := lookupKey(raw, name)
+		if !ok {
+			continue
+		}
+
+		// The native decoder did not see fields that are named by the shared or a replaced tag only.
+		if tagged && (!native || replaced) {
+			err := c.assign(field, value)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", fieldType.Name, err)
+			}
+		}
+
+		nested, isMap := value.(map[string]any)
+		if !isMap || !isStructOrStructPtr(field.Type()) {
+			continue
+		}
+
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+
+			field = field.Elem()
+		}
+
+		err := c.applySharedTags(field, nested)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// assign re-encodes a raw value and decodes it into the field.
+func (c codec) assign(field reflect.Value, value any) error {
+	if c.tableRoot {
+		return c.assignWrapped(field, value)
+	}
+
+	data, err := c.marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return c.unmarshal(data, field.Addr().Interface())
+}
+
+// assignWrapped re-encodes a raw value as the only key of a table and decodes it
+// through a single-field struct, keeping the current field value as the base.
+func (c codec) assignWrapped(field reflect.Value, value any) error {
+	const key = "value"
+
+	data, err := c.marshal(map[string]any{key: value})
+	if err != nil {
+		return err
+	}
+
+	wrapper := reflect.New(reflect.StructOf([]reflect.StructField{{
+		Name: "Value",
+		Type: field.Type(),
+		Tag:  reflect.StructTag(c.tag + `:"` + key + `"`),
+	}})).Elem()
+	wrapper.Field(0).Set(field)
+
+	err = c.unmarshal(data, wrapper.Addr().Interface())
+	if err != nil {
+		return err
+	}
+
+	field.Set(wrapper.Field(0))
+
+	return nil
+}
+
+// hasSharedTags reports whether the struct type or any nested struct relies on the shared config tag
+// or a replaced tag.
+func (c codec) hasSharedTags(typeOf reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[typeOf] {
+		return false
+	}
+
+	seen[typeOf] = true
+
+	for i := range typeOf.NumField() {
+		fieldType := typeOf.Field(i)
+
+		if _, replaced := fieldType.Tag.Lookup(c.key); replaced && c.key != c.tag {
+			return true
+		}
+
+		_, native := fieldType.Tag.Lookup(c.tag)
+		if _, shared := fieldType.Tag.Lookup(TagName); shared && !native {
+			return true
+		}
+
+		elem := fieldType.Type
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+
+		if elem.Kind() == reflect.Struct && c.hasSharedTags(elem, seen) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isStructOrStructPtr(typeOf reflect.Type) bool {
+	return typeOf.Kind() == reflect.Struct ||
+		(typeOf.Kind() == reflect.Ptr && typeOf.Elem().Kind() == reflect.Struct)
+}
+
+// lookupKey finds a key in the raw map, preferring an exact match over a case-insensitive one.
+func lookupKey(raw map[string]any, name string) (any, bool) {
+	if value, ok := raw[name]; ok {
+		return value, true
+	}
+
+	for key, value := range raw {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
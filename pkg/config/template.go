@@ -0,0 +1,66 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// renderTemplate executes the value as a text/template with the functions of templateFuncs.
+// Values without an action are returned unchanged.
+func renderTemplate(value string) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+
+	tmpl, err := template.New("value").Option("missingkey=error").Funcs(templateFuncs()).Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: parse template: %w", ErrInvalidConfig, err)
+	}
+
+	var result strings.Builder
+
+	err = tmpl.Execute(&result, nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: render template: %w", ErrInvalidConfig, err)
+	}
+
+	return result.String(), nil
+}
+
+// templateFuncs returns the functions available in templates:
+//   - env returns the value of an environment variable, e.g. {{ env "HOSTNAME" }}:9090.
+//   - file returns the content of a file without surrounding whitespace, e.g. {{ file "/run/secrets/token" }}.
+//   - default returns the fallback if the piped value is empty, e.g. {{ env "PORT" | default "8080" }}.
+//   - b64dec decodes standard base64, e.g. {{ env "TOKEN_B64" | b64dec }}.
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"env": os.Getenv,
+		"file": func(name string) (string, error) {
+			data, err := os.ReadFile(path.Clean(name))
+			if err != nil {
+				return "", err //nolint:wrapcheck // Reported by template execution.
+			}
+
+			return strings.TrimSpace(string(data)), nil
+		},
+		"default": func(fallback, value string) string {
+			if value == "" {
+				return fallback
+			}
+
+			return value
+		},
+		"b64dec": func(value string) (string, error) {
+			data, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return "", err //nolint:wrapcheck // Reported by template execution.
+			}
+
+			return string(data), nil
+		},
+	}
+}
@@ -0,0 +1,64 @@
+//go:build with_toml
+
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+var (
+	_ Source       = (*TOMLSource)(nil)
+	_ loaderSource = (*TOMLSource)(nil)
+
+	//nolint:gochecknoglobals // Stateless codec shared by all TOML sources.
+	tomlCodec = codec{unmarshal: toml.Unmarshal, marshal: toml.Marshal, tag: "toml", tableRoot: true}
+)
+
+//nolint:gochecknoinits // Registers the optional format for DirSource and Dump.
+func init() {
+	formatCodecs[FormatTOML] = tomlCodec
+	fileFormats[".toml"] = func(path string) Source { return TOMLSource{Path: path} }
+}
+
+// TOMLSource loads configuration from a TOML file.
+// Fields are matched by their toml tag, falling back to the shared config tag.
+type TOMLSource struct {
+	Path string
+
+	// ExpandEnv expands ${VAR}, ${VAR:-default} and ${VAR-default} in string values with environment variables.
+	ExpandEnv bool
+
+	// Strict rejects keys that match no field of the target, see JSONSource.Strict.
+	Strict bool
+}
+
+func (s TOMLSource) Load(target any) error {
+	return s.loadWith(target, &Loader{})
+}
+
+func (s TOMLSource) loadWith(target any, loader *Loader) error {
+	keyedCodec := tomlCodec.withKey(loader.tag(tomlCodec.tag))
+	keyedCodec.strict = s.Strict || loader.Strict
+
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return fmt.Errorf("%w: read TOML file: %w", ErrConfigNotFound, err)
+	}
+
+	if rewrite := loader.rewriter(s.ExpandEnv); rewrite != nil {
+		data, err = keyedCodec.rewrite(data, rewrite)
+		if err != nil {
+			return fmt.Errorf("%w: unmarshal TOML: %w", ErrInvalidConfig, err)
+		}
+	}
+
+	err = keyedCodec.decode(data, target)
+	if err != nil {
+		return fmt.Errorf("%w: unmarshal TOML: %w", ErrInvalidConfig, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+//go:build with_toml
+
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOMLSource_Load(t *testing.T) {
+	t.Parallel()
+
+	// Create test files.
+	validFile := filepath.Join(t.TempDir(), "config.toml")
+	err := os.WriteFile(validFile, []byte("name = \"test-app\"\nport = 8080"), 0o600)
+	require.NoError(t, err)
+
+	invalidFile := filepath.Join(t.TempDir(), "invalid.toml")
+	err = os.WriteFile(invalidFile, []byte(`invalid toml`), 0o600)
+	require.NoError(t, err)
+
+	testFileSourceLoad(t, func(path string) config.Source {
+		return config.TOMLSource{
+			Path: path,
+		}
+	}, validFile, invalidFile)
+}
+
+func TestTOMLSource_Load_SharedTag(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string `config:"hostname"`
+		Port int    `toml:"port"`
+	}
+
+	type Config struct {
+		Database *Database `config:"db"`
+		Started  time.Time `config:"started"`
+		Name     string    `config:"appName"   toml:"name"`
+		Level    string    `config:"logLevel"`
+	}
+
+	file := filepath.Join(t.TempDir(), "config.toml")
+	err := os.WriteFile(file, []byte(`
+name = "test-app"
+appName = "ignored"
+logLevel = "debug"
+started = 2024-05-27T07:32:00Z
+
+[db]
+hostname = "localhost"
+port = 5432
+`), 0o600)
+	require.NoError(t, err)
+
+	target := &Config{}
+	err = config.TOMLSource{Path: file}.Load(target)
+	require.NoError(t, err)
+	assert.Equal(t, &Config{
+		Database: &Database{Host: "localhost", Port: 5432},
+		Started:  time.Date(2024, time.May, 27, 7, 32, 0, 0, time.UTC),
+		Name:     "test-app",
+		Level:    "debug",
+	}, target)
+}
+
+func TestDump_TOML(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string `toml:"host"`
+	}
+
+	type Config struct {
+		Name     string        `toml:"name"`
+		Timeout  time.Duration `config:"timeout"`
+		Database Database      `toml:"database"`
+	}
+
+	data, err := config.Dump(&Config{Name: "app", Timeout: time.Second, Database: Database{Host: "db"}}, config.FormatTOML)
+	require.NoError(t, err)
+	assert.Equal(t, "name = 'app'\ntimeout = '1s'\n\n[database]\nhost = 'db'\n", string(data))
+}
+
+func TestReaderSource_Load_TOML(t *testing.T) {
+	t.Parallel()
+
+	target := &MockConfig{}
+	err := config.ReaderSource{Reader: strings.NewReader("# comment\nname = \"test-app\"\nport = 8080")}.Load(target)
+	require.NoError(t, err)
+	assert.Equal(t, &MockConfig{Name: "test-app", Port: 8080}, target)
+}
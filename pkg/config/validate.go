@@ -0,0 +1,239 @@
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/spacecafe/go-parts/pkg/typeconv"
+)
+
+var (
+	ErrConstraint = errors.New("config: constraint violated")
+
+	// patternCache caches compiled patterns of validate tags.
+	//
+	//nolint:gochecknoglobals // Process-wide cache of immutable regular expressions.
+	patternCache sync.Map
+)
+
+// ValidateTags checks the constraints declared by validate tags, which Load does automatically.
+// Rules are separated by commas, e.g. `validate:"min=1,max=65535"`:
+//   - min and max bound numbers and durations, which are parsed like the field itself, e.g. min=1s,
+//     and the length of strings, slices and maps.
+//   - oneof lists the allowed values separated by spaces, e.g. oneof=debug info warn error.
+//   - pattern requires strings to match a regular expression. It consumes the rest of the tag, so it must come last.
+//
+// Nil pointers are not validated; combine the tags with `required:"true"` for mandatory fields.
+// All violations are reported together.
+func ValidateTags(target any) error {
+	err := validatePointerToStruct(target)
+	if err != nil {
+		return err
+	}
+
+	return (&Loader{}).validateTags(target, "")
+}
+
+// validateTags implements ValidateTags, naming the environment variables with the given prefix.
+func (l *Loader) validateTags(target any, prefix string) error {
+	var errs []error
+
+	check := func(field reflect.Value, fieldType reflect.StructField, path, envName string) {
+		tag := fieldType.Tag.Get("validate")
+		if tag == "" || tag == "-" {
+			return
+		}
+
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				return
+			}
+
+			field = field.Elem()
+		}
+
+		for tag != "" {
+			var rule string
+
+			if strings.HasPrefix(tag, "pattern=") {
+				rule, tag = tag, ""
+			} else {
+				rule, tag, _ = strings.Cut(tag, ",")
+			}
+
+			name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+			violation, err := checkRule(l.converter(), field, name, arg)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("validate tag of %s: %w", path, err))
+			} else if violation != "" {
+				errs = append(errs, fmt.Errorf("%w: %s (env %s) %s", ErrConstraint, path, envName, violation))
+			}
+		}
+	}
+
+	walkFields(reflect.ValueOf(target).Elem(), "", prefix, l.tag("env"), check)
+
+	return errors.Join(errs...)
+}
+
+// validateNested calls Validate on every nested struct and non-nil pointer to struct implementing Validatable,
+// prefixing failures with the field path, and finally on the value itself.
+func validateNested(valueOf reflect.Value, path string) []error {
+	errs := validateFields(valueOf, path)
+
+	if validatable, ok := valueOf.Addr().Interface().(Validatable); ok {
+		err := validatable.Validate()
+		if err != nil && path != "" {
+			err = fmt.Errorf("%s: %w", path, err)
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// validateFields validates the nested structs of the value like validateNested. Embedded structs are not
+// validated on their own if the value is Validatable, as it promotes or wraps their method.
+func validateFields(valueOf reflect.Value, path string) []error {
+	var errs []error
+
+	_, isValidatable := valueOf.Addr().Interface().(Validatable)
+	typeOf := valueOf.Type()
+
+	for i := range valueOf.NumField() {
+		field := valueOf.Field(i)
+		fieldType := typeOf.Field(i)
+
+		if !fieldType.IsExported() || !isStructOrStructPtr(field.Type()) {
+			continue
+		}
+
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+
+			field = field.Elem()
+		}
+
+		fieldPath := fieldType.Name
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+
+		if fieldType.Anonymous && isValidatable {
+			errs = append(errs, validateFields(field, fieldPath)...)
+		} else {
+			errs = append(errs, validateNested(field, fieldPath)...)
+		}
+	}
+
+	return errs
+}
+
+// checkRule returns a description of the violated rule, or an empty string if the field satisfies it.
+// Bounds are parsed with the converter, like the values of the fields.
+func checkRule(converter *typeconv.Converter, field reflect.Value, name, arg string) (string, error) {
+	switch name {
+	case "min":
+		return checkBound(converter, field, arg, -1, "at least")
+	case "max":
+		return checkBound(converter, field, arg, 1, "at most")
+	case "oneof":
+		value := fmt.Sprint(field.Interface())
+		if !slices.Contains(strings.Fields(arg), value) {
+			return fmt.Sprintf("must be one of [%s], got %q", arg, value), nil
+		}
+
+		return "", nil
+	case "pattern":
+		if field.Kind() != reflect.String {
+			return "", fmt.Errorf("%w: pattern requires a string, got %s", ErrInvalidTarget, field.Type())
+		}
+
+		pattern, err := compilePattern(arg)
+		if err != nil {
+			return "", err
+		}
+
+		if !pattern.MatchString(field.String()) {
+			return fmt.Sprintf("must match %s, got %q", arg, field.String()), nil
+		}
+
+		return "", nil
+	default:
+		return "", fmt.Errorf("%w: unknown validate rule %q", ErrInvalidTarget, name)
+	}
+}
+
+// checkBound compares the field, or its length for strings and collections, against the bound.
+// The bound is violated if the comparison yields the given sign.
+func checkBound(
+	converter *typeconv.Converter, field reflect.Value, arg string, sign int, description string,
+) (string, error) {
+	kind := field.Kind()
+
+	if kind == reflect.String || kind == reflect.Slice || kind == reflect.Map || kind == reflect.Array {
+		bound, err := typeconv.ConvertTo[int](arg)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid length bound %q: %w", ErrInvalidTarget, arg, err)
+		}
+
+		if cmp.Compare(field.Len(), bound) == sign {
+			return fmt.Sprintf("must have a length of %s %d, got %d", description, bound, field.Len()), nil
+		}
+
+		return "", nil
+	}
+
+	bound := reflect.New(field.Type()).Elem()
+
+	err := converter.Convert(bound, arg)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid bound %q: %w", ErrInvalidTarget, arg, err)
+	}
+
+	var result int
+
+	switch {
+	case field.CanInt():
+		result = cmp.Compare(field.Int(), bound.Int())
+	case field.CanUint():
+		result = cmp.Compare(field.Uint(), bound.Uint())
+	case field.CanFloat():
+		result = cmp.Compare(field.Float(), bound.Float())
+	default:
+		return "", fmt.Errorf("%w: bounds require a number or length, got %s", ErrInvalidTarget, field.Type())
+	}
+
+	if result == sign {
+		return fmt.Sprintf("must be %s %s, got %v", description, arg, field.Interface()), nil
+	}
+
+	return "", nil
+}
+
+func compilePattern(expr string) (*regexp.Regexp, error) {
+	if cached, ok := patternCache.Load(expr); ok {
+		return cached.(*regexp.Regexp), nil //nolint:forcetypeassert // The cache only holds compiled patterns.
+	}
+
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid pattern: %w", ErrInvalidTarget, err)
+	}
+
+	patternCache.Store(expr, pattern)
+
+	return pattern, nil
+}
@@ -0,0 +1,132 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTags(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string `validate:"min=1"`
+	}
+
+	type Config struct {
+		Database *Database
+		Level    string        `validate:"oneof=debug info warn error"`
+		Name     string        `validate:"min=3,pattern=^[a-z]{1,8}$"`
+		Hosts    []string      `validate:"max=2"`
+		Timeout  time.Duration `validate:"min=1s,max=1m"`
+		Port     int           `validate:"min=1,max=65535"`
+		Ratio    float64       `validate:"max=1"`
+		Workers  uint          `validate:"min=1"`
+	}
+
+	valid := Config{
+		Level:   "info",
+		Name:    "app",
+		Hosts:   []string{"a", "b"},
+		Timeout: time.Second,
+		Port:    8080,
+		Ratio:   0.5,
+		Workers: 4,
+	}
+
+	tests := []struct {
+		modify  func(c *Config)
+		name    string
+		wantErr []string
+	}{
+		{
+			name:   "valid",
+			modify: func(_ *Config) {},
+		},
+		{
+			name: "violations",
+			modify: func(c *Config) {
+				c.Database = &Database{}
+				c.Level = "trace"
+				c.Name = "APPLICATION"
+				c.Hosts = []string{"a", "b", "c"}
+				c.Timeout = time.Hour
+				c.Port = 0
+				c.Ratio = 1.5
+				c.Workers = 0
+			},
+			wantErr: []string{
+				"Database.Host (env DATABASE_HOST) must have a length of at least 1, got 0",
+				"Level (env LEVEL) must be one of [debug info warn error], got \"trace\"",
+				"Name (env NAME) must match ^[a-z]{1,8}$, got \"APPLICATION\"",
+				"Hosts (env HOSTS) must have a length of at most 2, got 3",
+				"Timeout (env TIMEOUT) must be at most 1m, got 1h0m0s",
+				"Port (env PORT) must be at least 1, got 0",
+				"Ratio (env RATIO) must be at most 1, got 1.5",
+				"Workers (env WORKERS) must be at least 1, got 0",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			target := valid
+			tt.modify(&target)
+
+			err := config.ValidateTags(&target)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, config.ErrConstraint)
+
+			for _, want := range tt.wantErr {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestValidateTags_InvalidRule(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Name    string `validate:"unknown=1"`
+		Pattern string `validate:"pattern=["`
+		Port    int    `validate:"min=one"`
+		Enabled bool   `validate:"pattern=^t"`
+	}
+
+	err := config.ValidateTags(&Config{})
+	require.ErrorIs(t, err, config.ErrInvalidTarget)
+	assert.NotErrorIs(t, err, config.ErrConstraint)
+	assert.Contains(t, err.Error(), "validate tag of Name")
+	assert.Contains(t, err.Error(), "validate tag of Pattern")
+	assert.Contains(t, err.Error(), "validate tag of Port")
+	assert.Contains(t, err.Error(), "validate tag of Enabled")
+}
+
+type ValidatedConfig struct {
+	Name string `validate:"min=3"`
+	Port int    `required:"true"  validate:"max=65535"`
+}
+
+func (c *ValidatedConfig) Validate() error {
+	return nil
+}
+
+func TestLoad_ValidateTags(t *testing.T) {
+	t.Setenv("APP_NAME", "ab")
+
+	err := config.Load(&ValidatedConfig{}, config.EnvSource{Prefix: "APP"})
+	require.ErrorIs(t, err, config.ErrValidation)
+	require.ErrorIs(t, err, config.ErrRequired)
+	require.ErrorIs(t, err, config.ErrConstraint)
+	assert.Contains(t, err.Error(), "Name (env APP_NAME) must have a length of at least 3")
+}
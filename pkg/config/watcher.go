@@ -0,0 +1,120 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+
+	"github.com/spacecafe/go-parts/pkg/log"
+)
+
+// Trigger announces that the configuration may have changed by calling reload, e.g. KubernetesSource.Watch.
+// It blocks until the context is canceled; cancellation is not reported as an error.
+type Trigger func(ctx context.Context, reload func()) error
+
+// Watcher reloads the configuration of a Store from its sources on demand or whenever a trigger fires.
+// Every reload applies defaults and validation like Load; an invalid configuration is logged and discarded,
+// so the store keeps serving the last valid snapshot. Subscribers are only notified if the snapshot changed.
+type Watcher[T any] struct {
+	// Log receives reload failures of triggered reloads.
+	// Default is slog.Default().
+	Log log.Logger
+
+	store   *Store[T]
+	sources []Source
+
+	// mutex serializes reloads, so a slow reload cannot overwrite the result of a later one.
+	mutex sync.Mutex
+}
+
+// NewWatcher creates a new Watcher that reloads the store from the given sources.
+func NewWatcher[T any](store *Store[T], sources ...Source) *Watcher[T] {
+	return &Watcher[T]{
+		Log:     slog.Default(),
+		store:   store,
+		sources: sources,
+	}
+}
+
+// SignalTrigger returns a Trigger that fires whenever the process receives one of the signals.
+// Default is SIGHUP, the conventional signal to reload a configuration.
+func SignalTrigger(signals ...os.Signal) Trigger {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	return func(ctx context.Context, reload func()) error {
+		signalCh := make(chan os.Signal, 1)
+		signal.Notify(signalCh, signals...)
+
+		defer signal.Stop(signalCh)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-signalCh:
+				reload()
+			}
+		}
+	}
+}
+
+// OnChange registers a function that is called with the previous and the current snapshot after every change.
+// The returned function removes the subscription.
+func (w *Watcher[T]) OnChange(fn Subscriber[T]) (unsubscribe func()) {
+	return w.store.Subscribe(fn)
+}
+
+// Reload loads a fresh snapshot from the sources and swaps it into the store if it differs from the current one.
+// The current snapshot is kept if loading or validation fails.
+func (w *Watcher[T]) Reload() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	next, err := w.store.loadSnapshot(w.sources)
+	if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(w.store.Get(), next) {
+		return nil
+	}
+
+	w.store.Swap(next)
+
+	return nil
+}
+
+// Run starts the triggers and reloads the configuration whenever one of them fires.
+// It blocks until the context is canceled and all triggers returned, and reports the errors of failed triggers.
+// A failing trigger does not stop the others.
+func (w *Watcher[T]) Run(ctx context.Context, triggers ...Trigger) error {
+	var (
+		waitGroup sync.WaitGroup
+		errs      = make([]error, len(triggers))
+	)
+
+	for i, trigger := range triggers {
+		waitGroup.Go(func() {
+			errs[i] = trigger(ctx, w.reload)
+		})
+	}
+
+	waitGroup.Wait()
+
+	return errors.Join(errs...)
+}
+
+// reload is passed to triggers and logs failures, as triggers cannot handle them.
+func (w *Watcher[T]) reload() {
+	err := w.Reload()
+	if err != nil {
+		w.Log.Warn("failed to reload configuration, keeping the current one", "error", err)
+	}
+}
@@ -0,0 +1,86 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher_Reload(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	writeFile := func(content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	}
+
+	store := config.NewStore[MockConfig](nil)
+	watcher := config.NewWatcher(store, config.JSONSource{Path: file})
+
+	var changes [][2]*MockConfig
+
+	watcher.OnChange(func(previous, current *MockConfig) {
+		changes = append(changes, [2]*MockConfig{previous, current})
+	})
+
+	writeFile(`{"name": "first"}`)
+	require.NoError(t, watcher.Reload())
+
+	// An unchanged configuration does not notify subscribers.
+	require.NoError(t, watcher.Reload())
+
+	writeFile(`{invalid json}`)
+	require.ErrorIs(t, watcher.Reload(), config.ErrInvalidConfig)
+	assert.Equal(t, &MockConfig{Name: "first"}, store.Get())
+
+	writeFile(`{"name": "second"}`)
+	require.NoError(t, watcher.Reload())
+
+	assert.Equal(t, [][2]*MockConfig{
+		{nil, {Name: "first"}},
+		{{Name: "first"}, {Name: "second"}},
+	}, changes)
+}
+
+func TestWatcher_Run(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{invalid json}`), 0o600))
+
+	store := config.NewStore(&MockConfig{Name: "initial"})
+	watcher := config.NewWatcher(store, config.JSONSource{Path: file})
+	watcher.Log = slog.New(slog.DiscardHandler)
+
+	errTrigger := errors.New("trigger failed")
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	err := watcher.Run(ctx,
+		func(_ context.Context, reload func()) error {
+			// A failed reload keeps the current snapshot.
+			reload()
+
+			require.NoError(t, os.WriteFile(file, []byte(`{"name": "reloaded"}`), 0o600))
+			reload()
+
+			return errTrigger
+		},
+		func(ctx context.Context, _ func()) error {
+			cancel()
+			<-ctx.Done()
+
+			return nil
+		},
+	)
+	require.ErrorIs(t, err, errTrigger)
+	assert.Equal(t, &MockConfig{Name: "reloaded"}, store.Get())
+}
@@ -9,20 +9,54 @@ import (
 	"github.com/goccy/go-yaml"
 )
 
-var _ Source = (*YAMLSource)(nil)
+var (
+	_ Source       = (*YAMLSource)(nil)
+	_ loaderSource = (*YAMLSource)(nil)
+
+	//nolint:gochecknoglobals // Stateless codec shared by all YAML sources.
+	yamlCodec = codec{unmarshal: yaml.Unmarshal, marshal: yaml.Marshal, tag: "yaml"}
+)
+
+//nolint:gochecknoinits // Registers the optional format for DirSource and Dump.
+func init() {
+	formatCodecs[FormatYAML] = yamlCodec
+	fileFormats[".yaml"] = func(path string) Source { return YAMLSource{Path: path} }
+	fileFormats[".yml"] = func(path string) Source { return YAMLSource{Path: path} }
+}
 
 // YAMLSource loads configuration from a YAML file.
+// Fields are matched by their yaml tag, falling back to the shared config tag.
 type YAMLSource struct {
 	Path string
+
+	// ExpandEnv expands ${VAR}, ${VAR:-default} and ${VAR-default} in string values with environment variables.
+	ExpandEnv bool
+
+	// Strict rejects keys that match no field of the target, see JSONSource.Strict.
+	Strict bool
 }
 
 func (s YAMLSource) Load(target any) error {
+	return s.loadWith(target, &Loader{})
+}
+
+func (s YAMLSource) loadWith(target any, loader *Loader) error {
+	keyedCodec := yamlCodec.withKey(loader.tag(yamlCodec.tag))
+	keyedCodec.strict = s.Strict || loader.Strict
+
 	data, err := os.ReadFile(s.Path)
 	if err != nil {
 		return fmt.Errorf("%w: read YAML file: %w", ErrConfigNotFound, err)
 	}
 
-	err = yaml.Unmarshal(data, target)
+	if rewrite := loader.rewriter(s.ExpandEnv); rewrite != nil {
+		data, err = keyedCodec.rewrite(data, rewrite)
+		if err != nil {
+			return fmt.Errorf("%w: unmarshal YAML: %w", ErrInvalidConfig, err)
+		}
+	}
+
+	err = keyedCodec.decode(data, target)
 	if err != nil {
 		return fmt.Errorf("%w: unmarshal YAML: %w", ErrInvalidConfig, err)
 	}
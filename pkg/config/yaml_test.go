@@ -5,9 +5,12 @@ package config_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -29,3 +32,72 @@ func TestYAMLSource_Load(t *testing.T) {
 		}
 	}, validFile, invalidFile)
 }
+
+func TestDirSource_Load_YAML(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "10-defaults.json"), []byte(`{"name": "default"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "20-site.yml"), []byte("port: 9090"), 0o600))
+
+	target := &MockConfig{}
+	err := config.DirSource{Path: dir}.Load(target)
+	require.NoError(t, err)
+	assert.Equal(t, &MockConfig{Name: "default", Port: 9090}, target)
+}
+
+func TestDump_YAML(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Database *struct {
+			Host string `yaml:"host"`
+		} `yaml:"database"`
+		Name    string        `yaml:"name"`
+		Timeout time.Duration `config:"timeout"`
+	}
+
+	target := &Config{Name: "app", Timeout: time.Second}
+	target.Database = &struct {
+		Host string `yaml:"host"`
+	}{Host: "db"}
+
+	data, err := config.Dump(target, config.FormatYAML)
+	require.NoError(t, err)
+	assert.Equal(t, "database:\n  host: db\nname: app\ntimeout: 1s\n", string(data))
+}
+
+func TestYAMLSource_Load_ExpandEnv(t *testing.T) {
+	t.Setenv("EXPAND_NAME", "test-app")
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("name: ${EXPAND_NAME}\nport: ${EXPAND_PORT:-8080}"), 0o600))
+
+	target := &MockConfig{}
+	err := config.YAMLSource{Path: file, ExpandEnv: true}.Load(target)
+	require.NoError(t, err)
+	assert.Equal(t, &MockConfig{Name: "test-app", Port: 8080}, target)
+}
+
+func TestYAMLSource_Load_Strict(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("name: app\nprot: 8080\n"), 0o600))
+
+	err := config.YAMLSource{Path: file, Strict: true}.Load(&MockConfig{})
+	require.ErrorIs(t, err, config.ErrUnknownKey)
+	assert.ErrorContains(t, err, "prot")
+
+	err = (&config.Loader{Strict: true}).Load(&MockConfig{}, config.YAMLSource{Path: file})
+	require.ErrorIs(t, err, config.ErrUnknownKey)
+}
+
+func TestReaderSource_Load_YAML(t *testing.T) {
+	t.Parallel()
+
+	target := &MockConfig{}
+	err := config.ReaderSource{Reader: strings.NewReader("# comment\nname: test-app\nport: 8080\n")}.Load(target)
+	require.NoError(t, err)
+	assert.Equal(t, &MockConfig{Name: "test-app", Port: 8080}, target)
+}
@@ -0,0 +1,61 @@
+package httpserver
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// maxLogLevelSize is the maximum size of a log level sent to the admin server.
+const maxLogLevelSize = 64
+
+// AdminServer serves operational endpoints on their own address, isolated from the public handler,
+// so they are never exposed on the public port. It serves runtime profiles below DefaultPprofPrefix and,
+// with HandleLogLevel, the log level. Further endpoints like metrics and middlewares like authentication
+// are added to its Router.
+type AdminServer struct {
+	*HTTPServer
+
+	Router *Router
+}
+
+// NewAdminServer creates an AdminServer listening on the address of the configuration.
+// Options replacing the handler are ignored, as the AdminServer serves its Router.
+func NewAdminServer(cfg *Config, opts ...Option) *AdminServer {
+	obj := &AdminServer{Router: NewRouter()}
+	obj.HTTPServer = New(cfg, append(slices.Clip(opts), WithHandler(obj.Router))...)
+
+	obj.Router.Handle("GET "+DefaultPprofPrefix+"/", newProfiler(obj.HTTPServer, DefaultPprofPrefix))
+
+	return obj
+}
+
+// HandleLogLevel serves the level at /loglevel: GET returns it and PUT replaces it with the level in the body,
+// e.g. "debug" or "warn+2".
+func (s *AdminServer) HandleLogLevel(level *slog.LevelVar) {
+	s.Router.HandleFunc("GET /loglevel", func(resp http.ResponseWriter, _ *http.Request) {
+		resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = io.WriteString(resp, level.Level().String())
+	})
+
+	s.Router.HandleFunc("PUT /loglevel", func(resp http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(req.Body, maxLogLevelSize))
+		if err != nil {
+			http.Error(resp, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		err = level.UnmarshalText([]byte(strings.TrimSpace(string(body))))
+		if err != nil {
+			http.Error(resp, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		s.Log.Info("changed log level", "level", level.Level().String())
+		resp.WriteHeader(http.StatusNoContent)
+	})
+}
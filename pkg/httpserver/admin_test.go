@@ -0,0 +1,96 @@
+package httpserver_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminServer(t *testing.T) {
+	t.Parallel()
+
+	level := &slog.LevelVar{}
+
+	cfg := &httpserver.Config{}
+	cfg.SetDefaults()
+
+	admin := httpserver.NewAdminServer(cfg, httpserver.WithLogger(&mockLogger{}))
+	admin.HandleLogLevel(level)
+	admin.Router.HandleFunc("GET /metrics", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("requests 1"))
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantBody   string
+		wantStatus int
+	}{
+		{name: "profile index", method: http.MethodGet, target: "/debug/pprof/", wantStatus: http.StatusOK, wantBody: "heap"},
+		{
+			name:       "goroutine profile",
+			method:     http.MethodGet,
+			target:     "/debug/pprof/goroutine?debug=1",
+			wantStatus: http.StatusOK,
+			wantBody:   "goroutine profile",
+		},
+		{name: "unknown profile", method: http.MethodGet, target: "/debug/pprof/unknown", wantStatus: http.StatusNotFound},
+		{name: "trace", method: http.MethodGet, target: "/debug/pprof/trace?seconds=0.01", wantStatus: http.StatusOK},
+		{
+			name:       "profile exceeding write timeout",
+			method:     http.MethodGet,
+			target:     "/debug/pprof/profile?seconds=60",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "custom endpoint",
+			method:     http.MethodGet,
+			target:     "/metrics",
+			wantStatus: http.StatusOK,
+			wantBody:   "requests",
+		},
+		{name: "log level", method: http.MethodGet, target: "/loglevel", wantStatus: http.StatusOK, wantBody: "INFO"},
+		{
+			name:       "invalid log level",
+			method:     http.MethodPut,
+			target:     "/loglevel",
+			body:       "loud",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			admin.Server.Handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestAdminServer_HandleLogLevel(t *testing.T) {
+	t.Parallel()
+
+	level := &slog.LevelVar{}
+
+	admin := httpserver.NewAdminServer(&httpserver.Config{}, httpserver.WithLogger(&mockLogger{}))
+	admin.HandleLogLevel(level)
+
+	rec := httptest.NewRecorder()
+	admin.Server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader("debug\n")))
+
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, slog.LevelDebug, level.Level())
+}
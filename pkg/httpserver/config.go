@@ -3,9 +3,12 @@ package httpserver
 import (
 	"errors"
 	"fmt"
+	"net"
+	"net/netip"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +22,13 @@ const (
 	DefaultWriteTimeout      = time.Second * 30
 	DefaultIdleTimeout       = time.Second * 120
 	DefaultPort              = 8080
+
+	// DefaultACMEChallengeAddress is the address of the HTTP-01 challenge listener, which must be reachable on port 80.
+	DefaultACMEChallengeAddress = ":80"
+
+	// minMaxReadFrameSize and maxMaxReadFrameSize bound the frame size of HTTP/2, see RFC 9113 section 4.2.
+	minMaxReadFrameSize = 1 << 14
+	maxMaxReadFrameSize = 1<<24 - 1
 )
 
 var (
@@ -35,11 +45,31 @@ var (
 	ErrMissingKeyFile = errors.New(
 		"httpserver key file must be specified if cert file is specified",
 	)
-	ErrUnreadableCertFile       = errors.New("httpserver cert file must be readable")
-	ErrUnreadableKeyFile        = errors.New("httpserver key file must be readable")
-	ErrInvalidReadTimeout       = errors.New("httpserver read timeout must be positive")
-	ErrInvalidReadHeaderTimeout = errors.New("httpserver read header timeout must be positive")
-	ErrInvalidPort              = errors.New("httpserver port must be between 1 and 65535")
+	ErrUnreadableCertFile         = errors.New("httpserver cert file must be readable")
+	ErrUnreadableKeyFile          = errors.New("httpserver key file must be readable")
+	ErrInvalidReadTimeout         = errors.New("httpserver read timeout must be positive")
+	ErrInvalidReadHeaderTimeout   = errors.New("httpserver read header timeout must be positive")
+	ErrInvalidPort                = errors.New("httpserver port must be between 0 and 65535")
+	ErrInvalidTLSProfile          = errors.New("httpserver TLS profile must be modern, intermediate or old")
+	ErrInvalidMaxRequestBodyBytes = errors.New("httpserver max request body bytes must not be negative")
+	ErrInvalidForceStopTimeout    = errors.New("httpserver force stop timeout must not be negative")
+	ErrInvalidStopDelay           = errors.New("httpserver stop delay must not be negative")
+	ErrInvalidMaxConnections      = errors.New("httpserver max connections must not be negative")
+	ErrInvalidAddress             = errors.New("httpserver addresses must be host:port pairs with a valid port")
+	ErrConflictingACME            = errors.New("httpserver ACME domains must not be combined with cert and key files")
+	ErrInvalidACMEDomain          = errors.New("httpserver ACME domains must be non-empty host names")
+	ErrConflictingRedirect        = errors.New(
+		"httpserver redirect address must not be set while the ACME challenge listener redirects",
+	)
+	ErrInvalidHSTSMaxAge           = errors.New("httpserver HSTS max age must not be negative")
+	ErrInvalidMaxHeaderBytes       = errors.New("httpserver max header bytes must not be negative")
+	ErrInvalidMaxConcurrentStreams = errors.New("httpserver HTTP/2 max concurrent streams must not be negative")
+	ErrInvalidMaxReadFrameSize     = errors.New("httpserver HTTP/2 max read frame size must be between 16384 and 16777215")
+	ErrConflictingHTTP2            = errors.New("httpserver H2C must not be enabled while HTTP/2 is disabled")
+	ErrInvalidTrustedProxy         = errors.New(
+		"httpserver trusted proxies must be CIDRs and are required by the PROXY protocol",
+	)
+	ErrInvalidProxyHeaderTimeout = errors.New("httpserver proxy header timeout must not be negative")
 )
 
 // Config defines the essential parameters for serving an http Server.
@@ -47,7 +77,7 @@ type Config struct {
 	// Host represents network host address.
 	Host string `json:"host" yaml:"host"`
 
-	// BasePath represents the prefixed path in the URL.
+	// BasePath represents the prefixed path in the URL. The handler is served below it with the prefix stripped.
 	BasePath string `json:"basePath" yaml:"basePath"`
 
 	// CertFile represents the path to the certificate file.
@@ -56,6 +86,23 @@ type Config struct {
 	// KeyFile represents the path to the key file.
 	KeyFile string `json:"keyFile" yaml:"keyFile"`
 
+	// TLSProfile represents the preset of TLS versions, cipher suites and curves, one of TLSProfileModern,
+	// TLSProfileIntermediate and TLSProfileOld. Default is to require TLS 1.2 and leave the rest to Go.
+	TLSProfile string `json:"tlsProfile" yaml:"tlsProfile"`
+
+	// Addresses represents further host:port pairs served with the same handler besides Host and Port,
+	// e.g. "[::]:8080" for dual-stack or an extra port on localhost.
+	Addresses []string `json:"addresses" yaml:"addresses"`
+
+	// ACME obtains certificates automatically, e.g. from Let's Encrypt, if domains are configured.
+	ACME ACMEConfig `json:"acme" yaml:"acme"`
+
+	// Redirect sends plain HTTP clients to the TLS address and tells browsers to stay there.
+	Redirect RedirectConfig `json:"redirect" yaml:"redirect"`
+
+	// Listener tunes the sockets of all listeners.
+	Listener ListenerConfig `json:"listener" yaml:"listener"`
+
 	// ReadTimeout represents the maximum duration before timing out read of the request.
 	ReadTimeout time.Duration `json:"readTimeout" yaml:"readTimeout"`
 
@@ -68,12 +115,123 @@ type Config struct {
 	// IdleTimeout represents the maximum amount of time to wait for the next request when keep-alive is enabled.
 	IdleTimeout time.Duration `json:"idleTimeout" yaml:"idleTimeout"`
 
-	// Port specifies the port to be used for connections.
+	// Port specifies the port to be used for connections. Zero lets the system pick a free port,
+	// which HTTPServer.Port reports after Start.
 	Port int `json:"port" yaml:"port"`
 
+	// MaxRequestBodyBytes represents the maximum size of request bodies accepted by all handlers.
+	// Larger requests are rejected with 413 if they announce their length and fail to read beyond it otherwise.
+	// Zero means unlimited.
+	MaxRequestBodyBytes int64 `json:"maxRequestBodyBytes" yaml:"maxRequestBodyBytes"`
+
+	// MaxHeaderBytes represents the maximum size of request headers including the request line.
+	// Zero selects http.DefaultMaxHeaderBytes.
+	MaxHeaderBytes int `json:"maxHeaderBytes" yaml:"maxHeaderBytes"`
+
+	// HTTP2 tunes the limits of HTTP/2 connections.
+	HTTP2 HTTP2Config `json:"http2" yaml:"http2"`
+
+	// ForceStopTimeout represents the time Stop waits for active requests to finish before it closes
+	// the remaining connections. Zero waits as long as the context of Stop allows and leaves them open.
+	ForceStopTimeout time.Duration `json:"forceStopTimeout" yaml:"forceStopTimeout"`
+
+	// StopDelay represents the time Stop keeps serving after the readiness probe of WithHealth failed,
+	// so load balancers stop routing before the listeners close. Zero stops right away.
+	StopDelay time.Duration `json:"stopDelay" yaml:"stopDelay"`
+
+	// MaxConnections represents the maximum number of connections served at the same time across all addresses.
+	// Further connections are accepted once others close. Zero means unlimited.
+	MaxConnections int `json:"maxConnections" yaml:"maxConnections"`
+
 	// EnableH2C indicates whether HTTP/2 Cleartext (H2C) protocol support is enabled for the Server.
 	// Use this only if you have configured a reverse proxy that terminates TLS.
 	EnableH2C bool `json:"enableH2C" yaml:"enableH2C"`
+
+	// DisableHTTP2 restricts the Server to HTTP/1.1, e.g. for proxies or legacy clients failing on HTTP/2.
+	// It cannot be combined with EnableH2C. WithProtocols takes precedence.
+	DisableHTTP2 bool `json:"disableHTTP2" yaml:"disableHTTP2"`
+
+	// OCSPStapling staples OCSP responses fetched from the responder named by the certificate of CertFile
+	// to TLS handshakes and refreshes them before they expire. CertFile must contain the issuer certificate
+	// after the leaf. It is ignored for certificates obtained by ACME.
+	OCSPStapling bool `json:"ocspStapling" yaml:"ocspStapling"`
+}
+
+// ListenerConfig defines the socket options of listeners and the connections they accept.
+// The length of the accept backlog cannot be set; it is taken from the system, e.g. net.core.somaxconn on Linux.
+type ListenerConfig struct {
+	// TrustedProxies lists the CIDRs of the load balancers sending PROXY protocol headers, e.g. "10.0.0.0/8".
+	// Connections from other peers are served as they are.
+	TrustedProxies []string `json:"trustedProxies" yaml:"trustedProxies"`
+
+	// KeepAlive represents the period of TCP keep-alive probes on accepted connections.
+	// Zero selects the default of 15 seconds, a negative value disables keep-alive.
+	KeepAlive time.Duration `json:"keepAlive" yaml:"keepAlive"`
+
+	// ProxyHeaderTimeout represents the time a trusted proxy has to send the PROXY protocol header.
+	// Zero selects DefaultProxyHeaderTimeout.
+	ProxyHeaderTimeout time.Duration `json:"proxyHeaderTimeout" yaml:"proxyHeaderTimeout"`
+
+	// DisableNoDelay indicates whether TCP_NODELAY is cleared on accepted connections,
+	// so small writes are batched by Nagle's algorithm at the cost of latency.
+	DisableNoDelay bool `json:"disableNoDelay" yaml:"disableNoDelay"`
+
+	// ReusePort indicates whether SO_REUSEPORT is set, so several processes can listen on the same address.
+	// It is only supported on Linux and BSD systems.
+	ReusePort bool `json:"reusePort" yaml:"reusePort"`
+
+	// ProxyProtocol indicates whether connections from TrustedProxies start with a PROXY protocol header of
+	// version 1 or 2, e.g. behind AWS NLB or HAProxy in TCP mode. The client address of the header replaces the
+	// remote address of the connection. Requests on connections of trusted proxies without valid header fail.
+	ProxyProtocol bool `json:"proxyProtocol" yaml:"proxyProtocol"`
+}
+
+// HTTP2Config defines the limits of HTTP/2 connections. Zero values select the defaults of net/http.
+type HTTP2Config struct {
+	// MaxConcurrentStreams represents the maximum number of streams a client may open at the same time
+	// on a connection. Default is 250.
+	MaxConcurrentStreams int `json:"maxConcurrentStreams" yaml:"maxConcurrentStreams"`
+
+	// MaxReadFrameSize represents the largest frame the server reads, between 16 KiB and 16 MiB - 1 byte.
+	// Default is 1 MiB.
+	MaxReadFrameSize int `json:"maxReadFrameSize" yaml:"maxReadFrameSize"`
+}
+
+// ACMEConfig defines how certificates are obtained from an ACME provider like Let's Encrypt.
+type ACMEConfig struct {
+	// CacheDir represents the directory certificates and the account key are stored in across restarts.
+	// Without it, certificates are requested again after every restart, which quickly hits rate limits.
+	CacheDir string `json:"cacheDir" yaml:"cacheDir"`
+
+	// Email represents the contact address of the account, used by the provider to warn about expiring certificates.
+	Email string `json:"email" yaml:"email"`
+
+	// ChallengeAddress represents the address of the listener answering HTTP-01 challenges, which also
+	// redirects other requests to HTTPS. It can be disabled with "-" if port 80 is served elsewhere.
+	ChallengeAddress string `json:"challengeAddress" yaml:"challengeAddress"`
+
+	// DirectoryURL represents the directory endpoint of the ACME provider. Default is Let's Encrypt production.
+	DirectoryURL string `json:"directoryURL" yaml:"directoryURL"`
+
+	// Domains lists the host names certificates are requested for. ACME is disabled if empty.
+	Domains []string `json:"domains" yaml:"domains"`
+}
+
+// RedirectConfig defines the companion listener redirecting plain HTTP to HTTPS and the HSTS header.
+type RedirectConfig struct {
+	// Address represents the address of the listener answering plain HTTP requests with a permanent redirect
+	// to the same host and path on the port of the server, e.g. ":80". It is disabled if empty.
+	Address string `json:"address" yaml:"address"`
+
+	// HSTSMaxAge represents how long browsers remember to use only HTTPS, sent in the Strict-Transport-Security
+	// header of all responses over TLS. It is truncated to seconds. Zero sends no header.
+	HSTSMaxAge time.Duration `json:"hstsMaxAge" yaml:"hstsMaxAge"`
+
+	// HSTSIncludeSubdomains indicates whether the HSTS policy extends to all subdomains.
+	HSTSIncludeSubdomains bool `json:"hstsIncludeSubdomains" yaml:"hstsIncludeSubdomains"`
+
+	// HSTSPreload indicates whether the host consents to be included in the HSTS preload lists of browsers.
+	HSTSPreload bool `json:"hstsPreload" yaml:"hstsPreload"`
 }
 
 // SetDefaults initializes the default values for the relevant fields in the struct.
@@ -85,6 +243,7 @@ func (r *Config) SetDefaults() {
 	r.IdleTimeout = DefaultIdleTimeout
 	r.Port = DefaultPort
 	r.EnableH2C = false
+	r.ACME.ChallengeAddress = DefaultACMEChallengeAddress
 }
 
 // Validate ensures the all necessary configurations are filled and within valid confines.
@@ -107,10 +266,67 @@ func (r *Config) Validate() error {
 		return ErrInvalidReadHeaderTimeout
 	}
 
-	if r.Port <= 0 || r.Port > 65535 {
+	if r.Port < 0 || r.Port > 65535 {
 		return ErrInvalidPort
 	}
 
+	if _, ok := tlsProfiles()[r.TLSProfile]; r.TLSProfile != "" && !ok {
+		return fmt.Errorf("%w: %q", ErrInvalidTLSProfile, r.TLSProfile)
+	}
+
+	if r.MaxRequestBodyBytes < 0 {
+		return ErrInvalidMaxRequestBodyBytes
+	}
+
+	if r.ForceStopTimeout < 0 {
+		return ErrInvalidForceStopTimeout
+	}
+
+	if r.StopDelay < 0 {
+		return ErrInvalidStopDelay
+	}
+
+	if r.MaxConnections < 0 {
+		return ErrInvalidMaxConnections
+	}
+
+	if r.EnableH2C && r.DisableHTTP2 {
+		return ErrConflictingHTTP2
+	}
+
+	if r.MaxHeaderBytes < 0 {
+		return ErrInvalidMaxHeaderBytes
+	}
+
+	if r.HTTP2.MaxConcurrentStreams < 0 {
+		return ErrInvalidMaxConcurrentStreams
+	}
+
+	if size := r.HTTP2.MaxReadFrameSize; size != 0 && (size < minMaxReadFrameSize || size > maxMaxReadFrameSize) {
+		return ErrInvalidMaxReadFrameSize
+	}
+
+	for _, address := range r.Addresses {
+		err = validateAddress(address)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = r.Listener.validate()
+	if err != nil {
+		return err
+	}
+
+	err = r.validateRedirect()
+	if err != nil {
+		return err
+	}
+
+	if len(r.ACME.Domains) > 0 {
+		return r.validateACME()
+	}
+
 	if r.CertFile == "" && r.KeyFile == "" {
 		return nil
 	}
@@ -145,3 +361,69 @@ func (r *Config) Validate() error {
 
 	return nil
 }
+
+// validateACME ensures ACME is the only source of certificates and all domains are named.
+func (r *Config) validateACME() error {
+	if r.CertFile != "" || r.KeyFile != "" {
+		return ErrConflictingACME
+	}
+
+	for _, domain := range r.ACME.Domains {
+		if strings.TrimSpace(domain) == "" || strings.ContainsAny(domain, "/: ") {
+			return ErrInvalidACMEDomain
+		}
+	}
+
+	return nil
+}
+
+// validateRedirect ensures the redirect listener is a valid address and does not duplicate the ACME listener.
+func (r *Config) validateRedirect() error {
+	if r.Redirect.HSTSMaxAge < 0 {
+		return ErrInvalidHSTSMaxAge
+	}
+
+	if r.Redirect.Address == "" {
+		return nil
+	}
+
+	if len(r.ACME.Domains) > 0 && r.ACME.ChallengeAddress != "-" {
+		return ErrConflictingRedirect
+	}
+
+	return validateAddress(r.Redirect.Address)
+}
+
+// validate ensures the trusted proxies are CIDRs and named if the PROXY protocol is enabled.
+func (r *ListenerConfig) validate() error {
+	if r.ProxyHeaderTimeout < 0 {
+		return ErrInvalidProxyHeaderTimeout
+	}
+
+	if r.ProxyProtocol && len(r.TrustedProxies) == 0 {
+		return ErrInvalidTrustedProxy
+	}
+
+	for _, cidr := range r.TrustedProxies {
+		_, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidTrustedProxy, err)
+		}
+	}
+
+	return nil
+}
+
+// validateAddress ensures the address is a host:port pair with a valid port.
+func validateAddress(address string) error {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidAddress, err)
+	}
+
+	if number, err := strconv.Atoi(port); err != nil || number <= 0 || number > 65535 {
+		return fmt.Errorf("%w: %s", ErrInvalidAddress, address)
+	}
+
+	return nil
+}
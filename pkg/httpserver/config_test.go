@@ -0,0 +1,297 @@
+package httpserver_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate_ACME(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		acme    httpserver.ACMEConfig
+		withTLS bool
+	}{
+		{
+			name: "valid domains",
+			acme: httpserver.ACMEConfig{Domains: []string{"example.com", "www.example.com"}},
+		},
+		{
+			name:    "conflicting cert files",
+			acme:    httpserver.ACMEConfig{Domains: []string{"example.com"}},
+			withTLS: true,
+			wantErr: httpserver.ErrConflictingACME,
+		},
+		{
+			name:    "empty domain",
+			acme:    httpserver.ACMEConfig{Domains: []string{"example.com", " "}},
+			wantErr: httpserver.ErrInvalidACMEDomain,
+		},
+		{
+			name:    "domain with port",
+			acme:    httpserver.ACMEConfig{Domains: []string{"example.com:443"}},
+			wantErr: httpserver.ErrInvalidACMEDomain,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &httpserver.Config{}
+			cfg.SetDefaults()
+			cfg.ACME.Domains = tt.acme.Domains
+
+			if tt.withTLS {
+				cfg.CertFile, cfg.KeyFile = generateTestCert(t)
+			}
+
+			err := cfg.Validate()
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_Addresses(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr   error
+		name      string
+		addresses []string
+	}{
+		{
+			name:      "dual-stack addresses",
+			addresses: []string{"0.0.0.0:8080", "[::]:8080"},
+		},
+		{
+			name:      "missing port",
+			addresses: []string{"localhost"},
+			wantErr:   httpserver.ErrInvalidAddress,
+		},
+		{
+			name:      "invalid port",
+			addresses: []string{"localhost:99999"},
+			wantErr:   httpserver.ErrInvalidAddress,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &httpserver.Config{}
+			cfg.SetDefaults()
+			cfg.Addresses = tt.addresses
+
+			err := cfg.Validate()
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_Limits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr        error
+		name           string
+		http2          httpserver.HTTP2Config
+		maxHeaderBytes int
+	}{
+		{
+			name:           "valid limits",
+			maxHeaderBytes: 1 << 16,
+			http2:          httpserver.HTTP2Config{MaxConcurrentStreams: 100, MaxReadFrameSize: 1 << 14},
+		},
+		{name: "negative max header bytes", maxHeaderBytes: -1, wantErr: httpserver.ErrInvalidMaxHeaderBytes},
+		{
+			name:    "negative max concurrent streams",
+			http2:   httpserver.HTTP2Config{MaxConcurrentStreams: -1},
+			wantErr: httpserver.ErrInvalidMaxConcurrentStreams,
+		},
+		{
+			name:    "max read frame size too small",
+			http2:   httpserver.HTTP2Config{MaxReadFrameSize: 1024},
+			wantErr: httpserver.ErrInvalidMaxReadFrameSize,
+		},
+		{
+			name:    "max read frame size too large",
+			http2:   httpserver.HTTP2Config{MaxReadFrameSize: 1 << 24},
+			wantErr: httpserver.ErrInvalidMaxReadFrameSize,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &httpserver.Config{}
+			cfg.SetDefaults()
+			cfg.MaxHeaderBytes = tt.maxHeaderBytes
+			cfg.HTTP2 = tt.http2
+
+			err := cfg.Validate()
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_ProxyProtocol(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr  error
+		name     string
+		listener httpserver.ListenerConfig
+	}{
+		{
+			name:     "trusted proxies",
+			listener: httpserver.ListenerConfig{ProxyProtocol: true, TrustedProxies: []string{"10.0.0.0/8", "::1/128"}},
+		},
+		{
+			name:     "missing trusted proxies",
+			listener: httpserver.ListenerConfig{ProxyProtocol: true},
+			wantErr:  httpserver.ErrInvalidTrustedProxy,
+		},
+		{
+			name:     "invalid trusted proxy",
+			listener: httpserver.ListenerConfig{ProxyProtocol: true, TrustedProxies: []string{"10.0.0.1"}},
+			wantErr:  httpserver.ErrInvalidTrustedProxy,
+		},
+		{
+			name:     "negative header timeout",
+			listener: httpserver.ListenerConfig{ProxyHeaderTimeout: -time.Second},
+			wantErr:  httpserver.ErrInvalidProxyHeaderTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &httpserver.Config{}
+			cfg.SetDefaults()
+			cfg.Listener = tt.listener
+
+			err := cfg.Validate()
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_Protocols(t *testing.T) {
+	t.Parallel()
+
+	cfg := &httpserver.Config{}
+	cfg.SetDefaults()
+	cfg.DisableHTTP2 = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.EnableH2C = true
+	require.ErrorIs(t, cfg.Validate(), httpserver.ErrConflictingHTTP2)
+}
+
+func TestConfig_Validate_Redirect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr  error
+		name     string
+		acme     []string
+		redirect httpserver.RedirectConfig
+	}{
+		{
+			name:     "redirect with HSTS",
+			redirect: httpserver.RedirectConfig{Address: ":80", HSTSMaxAge: time.Hour},
+		},
+		{
+			name:     "invalid address",
+			redirect: httpserver.RedirectConfig{Address: "localhost"},
+			wantErr:  httpserver.ErrInvalidAddress,
+		},
+		{
+			name:     "negative HSTS max age",
+			redirect: httpserver.RedirectConfig{HSTSMaxAge: -time.Second},
+			wantErr:  httpserver.ErrInvalidHSTSMaxAge,
+		},
+		{
+			name:     "conflicting ACME challenge listener",
+			redirect: httpserver.RedirectConfig{Address: ":80"},
+			acme:     []string{"example.com"},
+			wantErr:  httpserver.ErrConflictingRedirect,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &httpserver.Config{}
+			cfg.SetDefaults()
+			cfg.Redirect = tt.redirect
+			cfg.ACME.Domains = tt.acme
+
+			err := cfg.Validate()
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_TLSProfile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		profile string
+	}{
+		{name: "default", profile: ""},
+		{name: "modern", profile: httpserver.TLSProfileModern},
+		{name: "intermediate", profile: httpserver.TLSProfileIntermediate},
+		{name: "old", profile: httpserver.TLSProfileOld},
+		{name: "unknown", profile: "strict", wantErr: httpserver.ErrInvalidTLSProfile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &httpserver.Config{}
+			cfg.SetDefaults()
+			cfg.TLSProfile = tt.profile
+
+			err := cfg.Validate()
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
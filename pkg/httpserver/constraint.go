@@ -0,0 +1,102 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// constraint restricts the values of a wildcard to those matching the expression.
+type constraint struct {
+	expression *regexp.Regexp
+	name       string
+}
+
+// Match returns a route middleware answering requests with 404 unless the predicate reports true, so they are
+// treated like requests no route matched, e.g. r.Get("/files/{name}", handler, httpserver.Match(isPublic)).
+func Match(predicate func(req *http.Request) bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			if !predicate(req) {
+				http.NotFound(resp, req)
+
+				return
+			}
+
+			next.ServeHTTP(resp, req)
+		})
+	}
+}
+
+// constrainPattern removes the constraints of the wildcards of the pattern, e.g. {id:[0-9]+} or {path...:.+\.md},
+// and returns the pattern for ServeMux together with a middleware answering requests with 404 whose path values
+// do not match the whole expression, or nil if the pattern has no constraints. It panics on invalid expressions
+// like ServeMux does on invalid patterns, which Handle explains.
+func constrainPattern(pattern string) (string, Middleware) {
+	var (
+		result      strings.Builder
+		constraints []constraint
+	)
+
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			break
+		}
+
+		end := closingBrace(pattern, start)
+		if end < 0 {
+			break
+		}
+
+		result.WriteString(pattern[:start])
+
+		name, expression, found := strings.Cut(pattern[start+1:end], ":")
+		if found {
+			compiled, err := regexp.Compile("^(?:" + expression + ")$")
+			if err != nil {
+				panic(fmt.Sprintf("httpserver: invalid constraint of wildcard %q in pattern: %v", name, err))
+			}
+
+			constraints = append(constraints, constraint{name: strings.TrimSuffix(name, "..."), expression: compiled})
+		}
+
+		result.WriteString("{" + name + "}")
+		pattern = pattern[end+1:]
+	}
+
+	if len(constraints) == 0 {
+		return result.String() + pattern, nil
+	}
+
+	return result.String() + pattern, Match(func(req *http.Request) bool {
+		for _, constraint := range constraints {
+			if !constraint.expression.MatchString(req.PathValue(constraint.name)) {
+				return false
+			}
+		}
+
+		return true
+	})
+}
+
+// closingBrace returns the index of the brace closing the one at start, skipping braces nested in the
+// expression, e.g. of repetitions like [0-9]{4}, or -1 if it is not closed.
+func closingBrace(pattern string, start int) int {
+	depth := 0
+
+	for i := start; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
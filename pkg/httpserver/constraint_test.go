@@ -0,0 +1,110 @@
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_Handle_Constraints(t *testing.T) {
+	t.Parallel()
+
+	router := httpserver.NewRouter()
+	router.Route("/{tenant:[a-z]+}", func(r *httpserver.Router) {
+		r.Get("/users/{id:[0-9]+}", func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte(req.PathValue("tenant") + ":" + req.PathValue("id")))
+		}, tagMiddleware("route"))
+		r.Get("/years/{year:[0-9]{4}}/{$}", func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte(req.PathValue("year")))
+		})
+		r.Get("/docs/{path...:.+\\.md}", func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte(req.PathValue("path")))
+		})
+		r.Get("/any/{name}", func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte(req.PathValue("name")))
+		})
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		want       string
+		wantStatus int
+	}{
+		{name: "matching values", path: "/acme/users/42", wantStatus: http.StatusOK, want: "acme:42"},
+		{name: "failing wildcard", path: "/acme/users/abc", wantStatus: http.StatusNotFound},
+		{name: "failing prefix", path: "/ACME/users/42", wantStatus: http.StatusNotFound},
+		{name: "nested braces", path: "/acme/years/2024/", wantStatus: http.StatusOK, want: "2024"},
+		{name: "failing repetition", path: "/acme/years/24/", wantStatus: http.StatusNotFound},
+		{name: "remaining segments", path: "/acme/docs/a/b.md", wantStatus: http.StatusOK, want: "a/b.md"},
+		{name: "failing remaining segments", path: "/acme/docs/a/b.txt", wantStatus: http.StatusNotFound},
+		{name: "unconstrained wildcard", path: "/acme/any/x", wantStatus: http.StatusOK, want: "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, http.NoBody))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.want, rec.Body.String())
+			}
+		})
+	}
+
+	t.Run("constraints before route middlewares", func(t *testing.T) {
+		t.Parallel()
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/acme/users/abc", http.NoBody))
+		assert.Empty(t, strings.Join(rec.Header().Values("X-Chain"), ","))
+	})
+
+	t.Run("invalid expression", func(t *testing.T) {
+		t.Parallel()
+
+		err := registrationPanic(func() {
+			httpserver.NewRouter().Get("/users/{id:[0-9}", func(http.ResponseWriter, *http.Request) {})
+		})
+		require.ErrorIs(t, err, httpserver.ErrInvalidRoute)
+	})
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	visible := httpserver.Match(func(req *http.Request) bool {
+		return !strings.HasPrefix(req.PathValue("name"), ".")
+	})
+
+	router := httpserver.NewRouter()
+	router.Get("/files/{name}", func(http.ResponseWriter, *http.Request) {}, visible)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{name: "accepted", path: "/files/readme", wantStatus: http.StatusOK},
+		{name: "rejected", path: "/files/.env", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, http.NoBody))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
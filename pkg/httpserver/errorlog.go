@@ -0,0 +1,54 @@
+package httpserver
+
+import (
+	"io"
+	stdlog "log"
+	"strings"
+)
+
+const (
+	// tlsHandshakeErrorPrefix starts the messages of failed TLS handshakes of http.Server.
+	tlsHandshakeErrorPrefix = "http: TLS handshake error from "
+
+	// panicPrefix starts the messages of panics http.Server recovered from.
+	panicPrefix = "http: panic serving "
+)
+
+var _ io.Writer = (*errorLogWriter)(nil)
+
+// errorLogWriter routes the error log of http.Server through the logger of the HTTPServer with structured fields,
+// so TLS handshake failures and recovered panics do not bypass it. It counts TLS handshake errors for Metrics.
+type errorLogWriter struct {
+	server *HTTPServer
+}
+
+// newErrorLog returns a standard logger writing to the logger of the server.
+func newErrorLog(server *HTTPServer) *stdlog.Logger {
+	return stdlog.New(&errorLogWriter{server: server}, "", 0)
+}
+
+// Write logs a message of http.Server, failed TLS handshakes at Warn level and recovered panics and all
+// other messages at Error level.
+func (w *errorLogWriter) Write(data []byte) (int, error) {
+	message := strings.TrimSpace(string(data))
+
+	switch {
+	case strings.HasPrefix(message, tlsHandshakeErrorPrefix):
+		remote, err, _ := strings.Cut(strings.TrimPrefix(message, tlsHandshakeErrorPrefix), ": ")
+
+		if w.server.metrics != nil {
+			w.server.metrics.tlsHandshakeErrors.Add(1)
+		}
+
+		w.server.Log.Warn("failed TLS handshake", "remote", remote, "error", err)
+	case strings.HasPrefix(message, panicPrefix):
+		remote, rest, _ := strings.Cut(strings.TrimPrefix(message, panicPrefix), ": ")
+		err, stack, _ := strings.Cut(rest, "\n")
+
+		w.server.Log.Error("recovered panic serving HTTP request", "remote", remote, "error", err, "stack", stack)
+	default:
+		w.server.Log.Error("HTTP server error", "error", strings.TrimPrefix(message, "http: "))
+	}
+
+	return len(data), nil
+}
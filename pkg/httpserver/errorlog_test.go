@@ -0,0 +1,81 @@
+package httpserver_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPServer_ErrorLog(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		message   string
+		wantLevel string
+		wantMsg   string
+		wantArgs  []any
+	}{
+		{
+			name:      "TLS handshake error",
+			message:   "http: TLS handshake error from 127.0.0.1:5678: EOF",
+			wantLevel: "warn",
+			wantMsg:   "failed TLS handshake",
+			wantArgs:  []any{"remote", "127.0.0.1:5678", "error", "EOF"},
+		},
+		{
+			name:      "recovered panic",
+			message:   "http: panic serving 127.0.0.1:5678: boom\ngoroutine 1 [running]:",
+			wantLevel: "error",
+			wantMsg:   "recovered panic serving HTTP request",
+			wantArgs:  []any{"remote", "127.0.0.1:5678", "error", "boom", "stack", "goroutine 1 [running]:"},
+		},
+		{
+			name:      "other error",
+			message:   "http: Accept error: too many open files; retrying in 5ms",
+			wantLevel: "error",
+			wantMsg:   "HTTP server error",
+			wantArgs:  []any{"error", "Accept error: too many open files; retrying in 5ms"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := &levelLogger{}
+			server := httpserver.New(&httpserver.Config{}, httpserver.WithLogger(logger))
+
+			server.Server.ErrorLog.Print(tt.message)
+
+			require.Len(t, logger.records, 1)
+			assert.Equal(t, levelRecord{level: tt.wantLevel, msg: tt.wantMsg, args: tt.wantArgs}, logger.records[0])
+		})
+	}
+}
+
+type levelRecord struct {
+	level string
+	msg   string
+	args  []any
+}
+
+type levelLogger struct {
+	records []levelRecord
+	mutex   sync.Mutex
+}
+
+func (l *levelLogger) Debug(msg string, args ...any) { l.record("debug", msg, args) }
+func (l *levelLogger) Error(msg string, args ...any) { l.record("error", msg, args) }
+func (l *levelLogger) Info(msg string, args ...any)  { l.record("info", msg, args) }
+func (l *levelLogger) Warn(msg string, args ...any)  { l.record("warn", msg, args) }
+
+func (l *levelLogger) record(level, msg string, args []any) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.records = append(l.records, levelRecord{level: level, msg: msg, args: args})
+}
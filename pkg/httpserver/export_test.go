@@ -0,0 +1,20 @@
+package httpserver
+
+// ExplainRoutePanic returns the error a router panics with if registering the pattern after the existing one
+// makes the ServeMux panic with the message. The routes are reported as registered at existing.go:1 and new.go:1.
+func ExplainRoutePanic(existing, pattern, message string) (err error) {
+	registry := newRouteRegistry()
+	registry.add(existing, registration{pattern: existing, site: "existing.go:1"})
+
+	defer func() {
+		err, _ = recover().(error)
+	}()
+
+	func() {
+		defer registry.explainPanic(registration{pattern: pattern, site: "new.go:1"})
+
+		panic(message)
+	}()
+
+	return nil
+}
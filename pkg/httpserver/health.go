@@ -0,0 +1,89 @@
+package httpserver
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/spacecafe/go-parts/pkg/shutdown"
+)
+
+const (
+	// LivenessPath is the path of the liveness probe served with WithHealth.
+	LivenessPath = "/healthz"
+
+	// ReadinessPath is the path of the readiness probe served with WithHealth.
+	ReadinessPath = "/readyz"
+)
+
+var _ shutdown.StatePublisher = (*Health)(nil)
+
+// Health serves liveness and readiness probes. The liveness probe succeeds as long as the server responds;
+// the readiness probe fails with 503 once the server is not ready anymore. As a shutdown.StatePublisher of the
+// Shutdown, readiness fails as soon as it starts draining or stopping, so load balancers stop routing
+// before the connections are closed.
+type Health struct {
+	ready atomic.Bool
+}
+
+// NewHealth creates a Health that is ready.
+func NewHealth() *Health {
+	obj := &Health{}
+	obj.ready.Store(true)
+
+	return obj
+}
+
+// PublishState marks the Health as ready only while the Shutdown is running.
+func (h *Health) PublishState(state shutdown.State) {
+	h.SetReady(state == shutdown.StateRunning)
+}
+
+// Ready reports whether the readiness probe succeeds.
+func (h *Health) Ready() bool {
+	return h.ready.Load()
+}
+
+// SetReady changes the result of the readiness probe, e.g. while the application warms up.
+func (h *Health) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+// wrap serves the probes at their paths in front of the handler, independent of the base path.
+func (h *Health) wrap(handler http.Handler) http.Handler {
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			handler.ServeHTTP(resp, req)
+
+			return
+		}
+
+		switch req.URL.Path {
+		case LivenessPath:
+			writeProbe(resp, true)
+		case ReadinessPath:
+			writeProbe(resp, h.Ready())
+		default:
+			handler.ServeHTTP(resp, req)
+		}
+	})
+}
+
+// writeProbe answers a probe with 200 if it succeeds and with 503 otherwise.
+func writeProbe(resp http.ResponseWriter, ok bool) {
+	resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	resp.Header().Set("Cache-Control", "no-store")
+
+	if !ok {
+		resp.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = io.WriteString(resp, "not ready\n")
+
+		return
+	}
+
+	_, _ = io.WriteString(resp, "ok\n")
+}
@@ -0,0 +1,102 @@
+package httpserver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/spacecafe/go-parts/pkg/shutdown"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		target     string
+		state      shutdown.State
+		wantStatus int
+	}{
+		{name: "live while running", target: "/healthz", state: shutdown.StateRunning, wantStatus: http.StatusOK},
+		{name: "ready while running", target: "/readyz", state: shutdown.StateRunning, wantStatus: http.StatusOK},
+		{name: "live while draining", target: "/healthz", state: shutdown.StateDraining, wantStatus: http.StatusOK},
+		{
+			name:       "not ready while draining",
+			target:     "/readyz",
+			state:      shutdown.StateDraining,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "not ready while stopping",
+			target:     "/readyz",
+			state:      shutdown.StateStopping,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "probes outside base path",
+			target:     "/api/readyz",
+			state:      shutdown.StateRunning,
+			wantStatus: http.StatusTeapot,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			health := httpserver.NewHealth()
+			health.PublishState(tt.state)
+
+			server := httpserver.New(
+				&httpserver.Config{BasePath: "/api"},
+				httpserver.WithHealth(health),
+				httpserver.WithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusTeapot)
+				})),
+			)
+
+			rec := httptest.NewRecorder()
+			server.Server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, http.NoBody))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestHealth_StopDelay(t *testing.T) {
+	t.Parallel()
+
+	health := httpserver.NewHealth()
+
+	server := httpserver.New(
+		&httpserver.Config{Host: "127.0.0.1", Port: 8095, StopDelay: 200 * time.Millisecond},
+		httpserver.WithLogger(&mockLogger{}),
+		httpserver.WithHealth(health),
+	)
+
+	require.NoError(t, server.Start(context.Background()))
+
+	stopped := make(chan error, 1)
+
+	go func() {
+		stopped <- server.Stop(context.Background())
+	}()
+
+	// The readiness probe fails while the server keeps serving during the delay.
+	assert.Eventually(t, func() bool { return !health.Ready() }, time.Second, 10*time.Millisecond)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://127.0.0.1:8095/readyz", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	require.NoError(t, <-stopped)
+}
@@ -0,0 +1,36 @@
+package httpserver
+
+import "net"
+
+// hooks holds the callbacks registered by WithOnStart, WithOnStop, WithOnStopped and WithOnError,
+// which are called in registration order.
+type hooks struct {
+	start   []func(addr net.Addr)
+	stop    []func()
+	stopped []func(err error)
+	errors  []func(err error)
+}
+
+func (h *hooks) runError(err error) {
+	for _, hook := range h.errors {
+		hook(err)
+	}
+}
+
+func (h *hooks) runStart(addr net.Addr) {
+	for _, hook := range h.start {
+		hook(addr)
+	}
+}
+
+func (h *hooks) runStop() {
+	for _, hook := range h.stop {
+		hook()
+	}
+}
+
+func (h *hooks) runStopped(err error) {
+	for _, hook := range h.stopped {
+		hook(err)
+	}
+}
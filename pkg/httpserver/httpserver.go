@@ -1,24 +1,42 @@
 package httpserver
 
 import (
+	"cmp"
 	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/spacecafe/go-parts/pkg/log"
 	"github.com/spacecafe/go-parts/pkg/shutdown"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
 )
 
-const StartupCheckTimeout = 100 * time.Millisecond
+const (
+	StartupCheckTimeout = 100 * time.Millisecond
+
+	// DrainProgressInterval is the interval in which Stop logs the requests it still waits for.
+	DrainProgressInterval = time.Second
+)
 
 var (
-	_ shutdown.Trackable = (*HTTPServer)(nil)
+	_ shutdown.Trackable   = (*HTTPServer)(nil)
+	_ shutdown.Inheritable = (*HTTPServer)(nil)
+	_ shutdown.Failable    = (*HTTPServer)(nil)
 
-	ErrInvalidContext = errors.New("httpserver: context must not be nil or cancelled")
+	ErrInvalidContext       = errors.New("httpserver: context must not be nil or cancelled")
+	ErrReusePortUnsupported = errors.New("httpserver: SO_REUSEPORT is not supported on this platform")
 )
 
 type HTTPServer struct {
@@ -28,90 +46,782 @@ type HTTPServer struct {
 	Log log.Logger
 
 	Server *http.Server
+
+	// health serves the probes and is marked as not ready by Stop if set.
+	health *Health
+
+	// metrics records and serves the metrics of the server if set.
+	metrics *Metrics
+
+	// pprof serves the runtime profiles if set.
+	pprof *mount
+
+	// listener is served by Server instead of listening on Host and Port if set.
+	listener net.Listener
+
+	// listenControl is called on every socket before it is bound.
+	listenControl func(network, address string, conn syscall.RawConn) error
+
+	// additionalServers serve the additional addresses of the configuration with the settings of Server.
+	additionalServers []*http.Server
+
+	// inheritable holds the listeners opened by Start by their name for InheritFiles.
+	inheritable map[string]*net.TCPListener
+
+	// addr is the address Server listens on since Start.
+	addr net.Addr
+
+	// connections holds the http.ConnState of every open connection.
+	connections sync.Map
+
+	// challengeServer answers ACME HTTP-01 challenges if certificates are obtained by ACME.
+	challengeServer *http.Server
+
+	// redirectServer redirects plain HTTP requests to the server if Config.Redirect.Address is set.
+	redirectServer *http.Server
+
+	// stapler staples OCSP responses to the certificate of CertFile if OCSPStapling is enabled.
+	stapler *ocspStapler
+
+	// errCh receives the first error of a server failing after Start returned.
+	errCh chan error
+
+	// hooks are called on the lifecycle events of the server.
+	hooks hooks
+
+	// mutex guards inheritable, addr and stapler.
+	mutex sync.Mutex
+
+	// activeRequests counts the requests being handled.
+	activeRequests atomic.Int64
+
+	// openConnections counts the connections accepted and not yet closed.
+	openConnections atomic.Int64
+
+	// connectionLimitHits counts how often a connection waited because MaxConnections was reached.
+	connectionLimitHits atomic.Uint64
 }
 
 func New(cfg *Config, opts ...Option) *HTTPServer {
 	protocols := &http.Protocols{}
 	protocols.SetHTTP1(true)
-	protocols.SetHTTP2(true)
+	protocols.SetHTTP2(!cfg.DisableHTTP2)
 	protocols.SetUnencryptedHTTP2(cfg.EnableH2C)
 
 	obj := &HTTPServer{
-		cfg: cfg,
-		Log: slog.Default(),
+		cfg:   cfg,
+		Log:   slog.Default(),
+		errCh: make(chan error, 1),
 		Server: &http.Server{
 			Addr:              fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
 			ReadTimeout:       cfg.ReadTimeout,
 			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
 			WriteTimeout:      cfg.WriteTimeout,
 			IdleTimeout:       cfg.IdleTimeout,
+			MaxHeaderBytes:    cfg.MaxHeaderBytes,
 			Protocols:         protocols,
+			HTTP2: &http.HTTP2Config{
+				MaxConcurrentStreams: cfg.HTTP2.MaxConcurrentStreams,
+				MaxReadFrameSize:     cfg.HTTP2.MaxReadFrameSize,
+			},
 		},
 	}
 
+	obj.Server.ConnState = obj.trackConnection
+	obj.Server.ErrorLog = newErrorLog(obj)
+
+	// The certificate and key files are loaded by Start.
 	if cfg.CertFile != "" && cfg.KeyFile != "" {
-		obj.Server.TLSConfig = &tls.Config{
-			Certificates: []tls.Certificate{{
-				Certificate: [][]byte{[]byte(cfg.CertFile)},
-				PrivateKey:  []byte(cfg.KeyFile),
-			}},
-			MinVersion: tls.VersionTLS12,
-		}
+		obj.Server.TLSConfig = &tls.Config{} //nolint:gosec // The minimum version is set by the profile.
+		applyTLSProfile(obj.Server.TLSConfig, cfg.TLSProfile)
+	}
+
+	if len(cfg.ACME.Domains) > 0 {
+		obj.configureACME()
 	}
 
 	for _, opt := range opts {
 		opt(obj)
 	}
 
+	// Clients must not negotiate HTTP/2 by ALPN if it is not served.
+	if obj.Server.TLSConfig != nil && !obj.Server.Protocols.HTTP2() {
+		obj.Server.TLSConfig = obj.Server.TLSConfig.Clone()
+		obj.Server.TLSConfig.NextProtos = slices.DeleteFunc(
+			slices.Clone(obj.Server.TLSConfig.NextProtos),
+			func(proto string) bool { return proto == "h2" },
+		)
+	}
+
+	if cfg.Redirect.Address != "" {
+		obj.configureRedirect()
+	}
+
+	if cfg.MaxRequestBodyBytes > 0 {
+		obj.Server.Handler = limitRequestBody(cfg.MaxRequestBodyBytes, obj.Server.Handler)
+	}
+
+	if cfg.BasePath != "" {
+		obj.Server.Handler = mountBasePath(cfg.BasePath, obj.Server.Handler)
+	}
+
+	if obj.pprof != nil {
+		obj.Server.Handler = obj.pprof.wrap(obj.Server.Handler)
+	}
+
+	if obj.metrics != nil {
+		obj.Server.Handler = obj.metrics.wrap(obj.Server.Handler)
+	}
+
+	if obj.health != nil {
+		obj.Server.Handler = obj.health.wrap(obj.Server.Handler)
+	}
+
+	if cfg.Redirect.HSTSMaxAge > 0 {
+		obj.Server.Handler = strictTransportSecurity(&cfg.Redirect, obj.Server.Handler)
+	}
+
+	obj.Server.Handler = obj.trackRequests(obj.Server.Handler)
+
 	return obj
 }
 
+// ActiveRequests returns the number of requests being handled.
+func (s *HTTPServer) ActiveRequests() int64 {
+	return s.activeRequests.Load()
+}
+
+// Addr returns the address the server listens on since Start, e.g. to learn the port picked by the system
+// for port zero. It returns nil before Start.
+func (s *HTTPServer) Addr() net.Addr {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.addr
+}
+
+// ConnectionLimitHits returns how often a new connection had to wait because MaxConnections was reached,
+// e.g. to export it as a metric.
+func (s *HTTPServer) ConnectionLimitHits() uint64 {
+	return s.connectionLimitHits.Load()
+}
+
+// Err returns a channel receiving the first error of a server failing after Start returned, e.g. because
+// its listener broke, so the application can react. shutdown.Shutdown.Track shuts down on it.
+// Failures during Start are returned by Start instead. The channel is never closed.
+func (s *HTTPServer) Err() <-chan error {
+	return s.errCh
+}
+
+// InheritFiles returns the sockets of all listeners opened by Start, so the process replacing this one on
+// shutdown.Shutdown.Restart serves the same addresses without refusing connections.
+func (s *HTTPServer) InheritFiles() (map[string]*os.File, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	files := make(map[string]*os.File, len(s.inheritable))
+
+	for name, listener := range s.inheritable {
+		file, err := listener.File()
+		if err != nil {
+			for _, opened := range files {
+				_ = opened.Close()
+			}
+
+			return nil, fmt.Errorf("httpserver: failed to hand over listener %s: %w", name, err)
+		}
+
+		files[name] = file
+	}
+
+	return files, nil
+}
+
+// OCSPStatus returns the state of OCSP stapling, e.g. to monitor the staple. It is zero unless
+// OCSPStapling is enabled and Start loaded a certificate naming an OCSP responder.
+func (s *HTTPServer) OCSPStatus() OCSPStatus {
+	stapler := s.currentStapler()
+	if stapler == nil {
+		return OCSPStatus{}
+	}
+
+	stapler.mutex.RLock()
+	defer stapler.mutex.RUnlock()
+
+	return stapler.status
+}
+
+// OpenConnections returns the number of connections accepted and not yet closed, including idle ones.
+func (s *HTTPServer) OpenConnections() int64 {
+	return s.openConnections.Load()
+}
+
+// Port returns the TCP port the server listens on since Start, or zero before Start.
+func (s *HTTPServer) Port() int {
+	if addr, ok := s.Addr().(*net.TCPAddr); ok {
+		return addr.Port
+	}
+
+	return 0
+}
+
+// Start listens on all addresses of the configuration and serves them in the background with the same handler.
+// Server is served on the listener of WithListener instead of Host and Port if given.
+// If any listener cannot be opened or fails right away, all of them are closed and their errors are returned.
 func (s *HTTPServer) Start(ctx context.Context) error {
 	if ctx == nil || ctx.Err() != nil {
 		return ErrInvalidContext
 	}
 
-	errCh := make(chan error, 1)
+	err := s.configureOCSP()
+	if err != nil {
+		return err
+	}
+
+	s.additionalServers = s.newAdditionalServers()
+	servers := s.servers()
+
+	listeners, err := s.listenAll(servers)
+	if err != nil {
+		return err
+	}
+
+	if stapler := s.currentStapler(); stapler != nil {
+		stapler.start()
+	}
+
+	if s.cfg.MaxConnections > 0 {
+		limiter := newConnLimiter(s.cfg.MaxConnections, s.Log, &s.connectionLimitHits)
+		for i, server := range servers {
+			if server != s.challengeServer && server != s.redirectServer {
+				listeners[i] = limiter.wrap(listeners[i])
+			}
+		}
+	}
+
+	errCh := make(chan error, len(servers))
 
-	go func() {
+	for i, server := range servers {
 		s.Log.Info(
 			"starting HTTP server",
-			"host", s.cfg.Host,
-			"port", s.cfg.Port,
-			"protocols", s.Server.Protocols.String(),
+			"address", listeners[i].Addr().String(),
+			"protocols", server.Protocols.String(),
 		)
 
-		if s.Server.TLSConfig == nil {
-			errCh <- s.Server.ListenAndServe()
-		} else {
-			errCh <- s.Server.ListenAndServeTLS("", "")
-		}
-	}()
+		go func() {
+			var err error
+			if server.TLSConfig == nil {
+				err = server.Serve(listeners[i])
+			} else {
+				certFile, keyFile := s.certFiles(server)
+				err = server.ServeTLS(listeners[i], certFile, keyFile)
+			}
+
+			// ServeTLS leaves the listener open if the certificates cannot be loaded.
+			_ = listeners[i].Close()
+			errCh <- err
+		}()
+	}
 
 	// Wait briefly to catch early initialization errors.
-	select {
-	case err := <-errCh:
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			return err
-		}
+	timeout := time.After(StartupCheckTimeout)
 
-		return nil
-	case <-time.After(StartupCheckTimeout):
-		go func() {
-			err := <-errCh
+	for remaining := len(servers); remaining > 0; remaining-- {
+		select {
+		case err := <-errCh:
 			if err != nil && !errors.Is(err, http.ErrServerClosed) {
-				s.Log.Error("failed to run HTTP server", "error", err)
-			} else {
-				s.Log.Info("stopped HTTP server")
+				if stapler := s.currentStapler(); stapler != nil {
+					stapler.stop()
+				}
+
+				return closeAll(servers, errCh, remaining-1, err)
 			}
+		case <-timeout:
+			go s.logErrors(errCh, remaining)
+
+			s.hooks.runStart(s.Addr())
+
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// Stop closes the listeners and waits for active requests to finish until the context is done,
+// logging the remaining requests every DrainProgressInterval. Connections still open after ForceStopTimeout
+// are closed forcefully.
+func (s *HTTPServer) Stop(ctx context.Context) error {
+	s.hooks.runStop()
+
+	if s.health != nil {
+		s.health.SetReady(false)
+	}
+
+	if s.cfg.StopDelay > 0 {
+		s.Log.Info("delaying stop of HTTP server", "delay", s.cfg.StopDelay)
+		sleepContext(ctx, s.cfg.StopDelay)
+	}
+
+	s.Log.Info("stopping HTTP server", "requests", s.ActiveRequests(), "connections", s.OpenConnections())
+
+	if stapler := s.currentStapler(); stapler != nil {
+		stapler.stop()
+	}
+
+	if s.cfg.ForceStopTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.ForceStopTimeout)
+		defer cancel()
+	}
+
+	servers := s.servers()
+	errCh := make(chan error, len(servers))
+
+	for _, server := range servers {
+		go func() {
+			errCh <- server.Shutdown(ctx)
 		}()
+	}
+
+	ticker := time.NewTicker(DrainProgressInterval)
+	defer ticker.Stop()
+
+	var errs []error
+
+	for remaining := len(servers); remaining > 0; {
+		select {
+		case err := <-errCh:
+			errs = append(errs, err)
+			remaining--
+		case <-ticker.C:
+			s.Log.Info(
+				"waiting for HTTP requests to finish",
+				"requests", s.ActiveRequests(),
+				"connections", s.OpenConnections(),
+			)
+		}
+	}
+
+	if s.cfg.ForceStopTimeout > 0 && ctx.Err() != nil {
+		s.Log.Warn("closing remaining HTTP connections", "requests", s.ActiveRequests())
+
+		for _, server := range servers {
+			errs = append(errs, server.Close())
+		}
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		err = fmt.Errorf("httpserver: failed to stop HTTP server: %w", err)
+	}
+
+	s.hooks.runStopped(err)
+
+	return err
+}
+
+// certFiles returns the certificate and key files the server loads, which are none if they are stapled.
+func (s *HTTPServer) certFiles(server *http.Server) (string, string) {
+	if server.TLSConfig != nil && server.TLSConfig.GetCertificate != nil && s.currentStapler() != nil {
+		return "", ""
+	}
+
+	return s.cfg.CertFile, s.cfg.KeyFile
+}
+
+// configureACME obtains the certificates of the server from the ACME provider of the configuration
+// and prepares the listener answering HTTP-01 challenges.
+func (s *HTTPServer) configureACME() {
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(s.cfg.ACME.Domains...),
+		Email:      s.cfg.ACME.Email,
+	}
+
+	if s.cfg.ACME.CacheDir != "" {
+		manager.Cache = autocert.DirCache(s.cfg.ACME.CacheDir)
+	}
 
+	if s.cfg.ACME.DirectoryURL != "" {
+		manager.Client = &acme.Client{DirectoryURL: s.cfg.ACME.DirectoryURL}
+	}
+
+	s.Server.TLSConfig = manager.TLSConfig()
+	applyTLSProfile(s.Server.TLSConfig, s.cfg.TLSProfile)
+
+	if s.cfg.ACME.ChallengeAddress == "-" {
+		return
+	}
+
+	address := s.cfg.ACME.ChallengeAddress
+	if address == "" {
+		address = DefaultACMEChallengeAddress
+	}
+
+	protocols := &http.Protocols{}
+	protocols.SetHTTP1(true)
+
+	s.challengeServer = &http.Server{
+		Addr:              address,
+		Protocols:         protocols,
+		Handler:           manager.HTTPHandler(nil),
+		ReadTimeout:       s.cfg.ReadTimeout,
+		ReadHeaderTimeout: s.cfg.ReadHeaderTimeout,
+		WriteTimeout:      s.cfg.WriteTimeout,
+		IdleTimeout:       s.cfg.IdleTimeout,
+	}
+}
+
+// configureOCSP loads the certificate for OCSP stapling and serves it through tls.Config.GetCertificate.
+func (s *HTTPServer) configureOCSP() error {
+	if !s.cfg.OCSPStapling || s.cfg.CertFile == "" || s.Server.TLSConfig == nil || s.currentStapler() != nil {
 		return nil
 	}
+
+	stapler, err := newOCSPStapler(s)
+	if err != nil || stapler == nil {
+		return err
+	}
+
+	s.Server.TLSConfig = s.Server.TLSConfig.Clone()
+	s.Server.TLSConfig.GetCertificate = stapler.getCertificate
+
+	s.mutex.Lock()
+	s.stapler = stapler
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// configureRedirect prepares the listener redirecting plain HTTP requests to the port of the server.
+func (s *HTTPServer) configureRedirect() {
+	protocols := &http.Protocols{}
+	protocols.SetHTTP1(true)
+
+	s.redirectServer = &http.Server{
+		Addr:              s.cfg.Redirect.Address,
+		Protocols:         protocols,
+		Handler:           redirectHandler(s.Port),
+		ReadTimeout:       s.cfg.ReadTimeout,
+		ReadHeaderTimeout: s.cfg.ReadHeaderTimeout,
+		WriteTimeout:      s.cfg.WriteTimeout,
+		IdleTimeout:       s.cfg.IdleTimeout,
+		ErrorLog:          s.Server.ErrorLog,
+	}
 }
 
-func (s *HTTPServer) Stop(ctx context.Context) error {
-	s.Log.Info("stopping HTTP server")
+// connectionStates counts the open connections by their state.
+func (s *HTTPServer) connectionStates() map[http.ConnState]int64 {
+	states := map[http.ConnState]int64{}
+
+	s.connections.Range(func(_, state any) bool {
+		states[state.(http.ConnState)]++ //nolint:forcetypeassert // Only states are stored.
+
+		return true
+	})
+
+	return states
+}
+
+// currentStapler returns the stapler configured by Start, or nil.
+func (s *HTTPServer) currentStapler() *ocspStapler {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.stapler
+}
+
+// listen opens a listener on the address, taking over the socket of the process this one replaced if possible.
+func (s *HTTPServer) listen(listenConfig *net.ListenConfig, address string) (net.Listener, error) {
+	if file := shutdown.InheritedFile(inheritedName(address)); file != nil {
+		defer func() {
+			_ = file.Close()
+		}()
+
+		listener, err := net.FileListener(file)
+		if err == nil {
+			s.Log.Info("inherited listener", "address", address)
+
+			return listener, nil
+		}
+
+		s.Log.Warn("failed to inherit listener", "address", address, "error", err)
+	}
+
+	return listenConfig.Listen(context.Background(), "tcp", address) //nolint:wrapcheck // Wrapped by listenAll.
+}
+
+// listenAll opens a listener per server; Server uses the listener of WithListener instead if given.
+// If one fails, the listeners opened so far are closed.
+func (s *HTTPServer) listenAll(servers []*http.Server) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(servers))
+	if s.listener != nil {
+		listeners = append(listeners, s.listener)
+	}
+
+	listenConfig := s.listenConfig()
+
+	for _, server := range servers[len(listeners):] {
+		listener, err := s.listen(listenConfig, server.Addr)
+		if err != nil {
+			for _, opened := range listeners {
+				_ = opened.Close()
+			}
+
+			return nil, fmt.Errorf("httpserver: failed to listen on %s: %w", server.Addr, err)
+		}
+
+		listeners = append(listeners, listener)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.inheritable = make(map[string]*net.TCPListener, len(listeners))
+	s.addr = listeners[0].Addr()
+
+	for i, listener := range listeners {
+		if tcpListener, ok := listener.(*net.TCPListener); ok {
+			s.inheritable[inheritedName(servers[i].Addr)] = tcpListener
+		}
+
+		if s.cfg.Listener.DisableNoDelay {
+			listeners[i] = &delayListener{Listener: listener}
+		}
+
+		if s.cfg.Listener.ProxyProtocol {
+			listeners[i] = newProxyListener(listeners[i], s.cfg.Listener.TrustedProxies, s.cfg.Listener.ProxyHeaderTimeout)
+		}
+	}
+
+	return listeners, nil
+}
+
+// listenConfig returns the socket settings of Config.Listener and WithListenControl.
+func (s *HTTPServer) listenConfig() *net.ListenConfig {
+	listenConfig := &net.ListenConfig{KeepAlive: s.cfg.Listener.KeepAlive}
+
+	if s.cfg.Listener.ReusePort || s.listenControl != nil {
+		listenConfig.Control = func(network, address string, conn syscall.RawConn) error {
+			if s.cfg.Listener.ReusePort {
+				err := reusePort(conn)
+				if err != nil {
+					return err
+				}
+			}
+
+			if s.listenControl != nil {
+				return s.listenControl(network, address, conn)
+			}
+
+			return nil
+		}
+	}
+
+	return listenConfig
+}
 
-	return fmt.Errorf("httpserver: failed to stop HTTP server: %w", s.Server.Shutdown(ctx))
+// logErrors reports how the given number of servers stopped, once they have.
+func (s *HTTPServer) logErrors(errCh <-chan error, count int) {
+	for range count {
+		err := <-errCh
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.Log.Error("failed to run HTTP server", "error", err)
+			s.hooks.runError(err)
+
+			select {
+			case s.errCh <- err:
+			default:
+			}
+		} else {
+			s.Log.Info("stopped HTTP server")
+		}
+	}
+}
+
+// newAdditionalServers returns a server per additional address, sharing the handler and settings of Server.
+func (s *HTTPServer) newAdditionalServers() []*http.Server {
+	servers := make([]*http.Server, 0, len(s.cfg.Addresses))
+
+	for _, address := range s.cfg.Addresses {
+		servers = append(servers, &http.Server{
+			Addr:              address,
+			Handler:           s.Server.Handler,
+			TLSConfig:         s.Server.TLSConfig,
+			ReadTimeout:       s.Server.ReadTimeout,
+			ReadHeaderTimeout: s.Server.ReadHeaderTimeout,
+			WriteTimeout:      s.Server.WriteTimeout,
+			IdleTimeout:       s.Server.IdleTimeout,
+			MaxHeaderBytes:    s.Server.MaxHeaderBytes,
+			ErrorLog:          s.Server.ErrorLog,
+			ConnState:         s.Server.ConnState,
+			BaseContext:       s.Server.BaseContext,
+			ConnContext:       s.Server.ConnContext,
+			Protocols:         s.Server.Protocols,
+			HTTP2:             s.Server.HTTP2,
+		})
+	}
+
+	return servers
+}
+
+// servers returns all servers managed by Start and Stop.
+func (s *HTTPServer) servers() []*http.Server {
+	servers := append([]*http.Server{s.Server}, s.additionalServers...)
+	if s.challengeServer != nil {
+		servers = append(servers, s.challengeServer)
+	}
+
+	if s.redirectServer != nil {
+		servers = append(servers, s.redirectServer)
+	}
+
+	return servers
+}
+
+// trackConnection records the open connections of the servers and their state as their http.Server.ConnState hook.
+func (s *HTTPServer) trackConnection(conn net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		s.openConnections.Add(1)
+		s.connections.Store(conn, state)
+	case http.StateHijacked, http.StateClosed:
+		s.openConnections.Add(-1)
+		s.connections.Delete(conn)
+	case http.StateActive, http.StateIdle:
+		s.connections.Store(conn, state)
+	}
+}
+
+// trackRequests wraps the handler to count the requests being handled.
+func (s *HTTPServer) trackRequests(handler http.Handler) http.Handler {
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		s.activeRequests.Add(1)
+		defer s.activeRequests.Add(-1)
+
+		handler.ServeHTTP(resp, req)
+	})
+}
+
+// closeAll closes all servers after one failed and returns its error together with the errors of the
+// given number of servers still running.
+func closeAll(servers []*http.Server, errCh <-chan error, running int, err error) error {
+	errs := []error{err}
+
+	for _, server := range servers {
+		_ = server.Close()
+	}
+
+	for range running {
+		err := <-errCh
+		if !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// inheritedName names the listener of the address among the files handed over on restart.
+func inheritedName(address string) string {
+	return "httpserver:" + address
+}
+
+// limitRequestBody rejects requests announcing a body larger than the limit with 413 and keeps the handler
+// from reading beyond it otherwise, see http.MaxBytesReader.
+func limitRequestBody(limit int64, handler http.Handler) http.Handler {
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+
+	limited := http.MaxBytesHandler(handler, limit)
+
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.ContentLength > limit {
+			resp.Header().Set("Connection", "close")
+			http.Error(resp, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+
+			return
+		}
+
+		limited.ServeHTTP(resp, req)
+	})
+}
+
+// mergeTLSConfig completes a copy of the given TLS settings with the settings of the TLS profile it leaves unset,
+// the certificates of the base settings built from the configuration, unless it provides its own,
+// and the ALPN protocol of ACME challenges.
+func mergeTLSConfig(config, base *tls.Config, profile string) *tls.Config {
+	defaults := &tls.Config{} //nolint:gosec // The minimum version is set by the profile.
+	applyTLSProfile(defaults, profile)
+
+	merged := config.Clone()
+	merged.MinVersion = cmp.Or(merged.MinVersion, defaults.MinVersion)
+
+	if merged.CipherSuites == nil {
+		merged.CipherSuites = defaults.CipherSuites
+	}
+
+	if merged.CurvePreferences == nil {
+		merged.CurvePreferences = defaults.CurvePreferences
+	}
+
+	if base == nil {
+		return merged
+	}
+
+	if len(merged.Certificates) == 0 && merged.GetCertificate == nil {
+		merged.GetCertificate = base.GetCertificate
+	}
+
+	if len(merged.NextProtos) == 0 {
+		merged.NextProtos = slices.Clone(base.NextProtos)
+	} else if slices.Contains(base.NextProtos, acme.ALPNProto) && !slices.Contains(merged.NextProtos, acme.ALPNProto) {
+		merged.NextProtos = append(merged.NextProtos, acme.ALPNProto)
+	}
+
+	return merged
+}
+
+// mountBasePath serves the handler below the base path with the prefix stripped from the request path.
+// The base path itself is redirected to its slashed form; requests outside it are not found.
+func mountBasePath(basePath string, handler http.Handler) http.Handler {
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+
+	stripped := http.StripPrefix(basePath, handler)
+
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == basePath:
+			target := basePath + "/"
+			if req.URL.RawQuery != "" {
+				target += "?" + req.URL.RawQuery
+			}
+
+			http.Redirect(resp, req, target, http.StatusMovedPermanently)
+		case strings.HasPrefix(req.URL.Path, basePath+"/"):
+			stripped.ServeHTTP(resp, req)
+		default:
+			http.NotFound(resp, req)
+		}
+	})
+}
+
+// sleepContext waits for the duration or until the context is done.
+func sleepContext(ctx context.Context, duration time.Duration) {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
 }
@@ -5,12 +5,21 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
+	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
@@ -62,6 +71,57 @@ func TestHTTPServer_Start(t *testing.T) {
 			ctx:     context.Background(),
 			wantErr: nil,
 		},
+		{
+			name: "server startup with ACME succeeds",
+			server: httpserver.New(
+				&httpserver.Config{
+					Port: 8082,
+					ACME: httpserver.ACMEConfig{Domains: []string{"example.com"}, ChallengeAddress: "127.0.0.1:0"},
+				},
+				httpserver.WithLogger(&mockLogger{}),
+			),
+			ctx:     context.Background(),
+			wantErr: nil,
+		},
+		{
+			name: "server startup with ACME fails on challenge address",
+			server: httpserver.New(
+				&httpserver.Config{
+					Port: 8083,
+					ACME: httpserver.ACMEConfig{Domains: []string{"example.com"}, ChallengeAddress: "127.0.0.1:99999"},
+				},
+				httpserver.WithLogger(&mockLogger{}),
+			),
+			ctx:     context.Background(),
+			wantErr: &net.AddrError{},
+		},
+		{
+			name: "server startup with additional addresses succeeds",
+			server: httpserver.New(
+				&httpserver.Config{Host: "127.0.0.1", Port: 8084, Addresses: []string{"localhost:0"}},
+				httpserver.WithLogger(&mockLogger{}),
+			),
+			ctx:     context.Background(),
+			wantErr: nil,
+		},
+		{
+			name: "server startup fails on additional address",
+			server: httpserver.New(
+				&httpserver.Config{Host: "127.0.0.1", Port: 8085, Addresses: []string{"127.0.0.1:99999"}},
+				httpserver.WithLogger(&mockLogger{}),
+			),
+			ctx:     context.Background(),
+			wantErr: &net.AddrError{},
+		},
+		{
+			name: "server startup fails on missing cert file",
+			server: httpserver.New(
+				&httpserver.Config{Port: 8088, CertFile: certFile + ".missing", KeyFile: keyFile},
+				httpserver.WithLogger(&mockLogger{}),
+			),
+			ctx:     context.Background(),
+			wantErr: os.ErrNotExist,
+		},
 		{
 			name: "server startup fails immediately",
 			server: httpserver.New(
@@ -92,7 +152,652 @@ func TestHTTPServer_Start(t *testing.T) {
 			}
 
 			if err == nil {
-				assert.NoError(t, tt.server.Server.Shutdown(context.Background()))
+				assert.NoError(t, tt.server.Stop(context.Background()))
+			}
+		})
+	}
+}
+
+func TestHTTPServer_Start_Addresses(t *testing.T) {
+	t.Parallel()
+
+	server := httpserver.New(
+		&httpserver.Config{Host: "127.0.0.1", Port: 8086, Addresses: []string{"127.0.0.1:8087"}},
+		httpserver.WithLogger(&mockLogger{}),
+		httpserver.WithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})),
+	)
+
+	require.NoError(t, server.Start(context.Background()))
+
+	for _, address := range []string{"127.0.0.1:8086", "127.0.0.1:8087"} {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+address, nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusTeapot, resp.StatusCode, address)
+	}
+
+	require.NoError(t, server.Stop(context.Background()))
+}
+
+func TestHTTPServer_Start_WithListener(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := httpserver.New(
+		&httpserver.Config{Host: "127.0.0.1", Port: 99999},
+		httpserver.WithLogger(&mockLogger{}),
+		httpserver.WithListener(listener),
+		httpserver.WithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})),
+	)
+
+	require.NoError(t, server.Start(context.Background()))
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+listener.Addr().String(), nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+
+	require.NoError(t, server.Stop(context.Background()))
+}
+
+func TestHTTPServer_Start_WithTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	certFile, keyFile := generateTestCert(t)
+
+	server := httpserver.New(
+		&httpserver.Config{Host: "127.0.0.1", Port: 8089, CertFile: certFile, KeyFile: keyFile},
+		httpserver.WithLogger(&mockLogger{}),
+		httpserver.WithTLSConfig(&tls.Config{MaxVersion: tls.VersionTLS12}), //nolint:gosec // Required for testing.
+		httpserver.WithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})),
+	)
+
+	require.NoError(t, server.Start(context.Background()))
+
+	certPEM, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(certPEM))
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:    roots,
+		ServerName: "localhost",
+		MinVersion: tls.VersionTLS12,
+	}}}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://127.0.0.1:8089", nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+	assert.Equal(t, uint16(tls.VersionTLS12), resp.TLS.Version)
+
+	require.NoError(t, server.Stop(context.Background()))
+}
+
+func TestHTTPServer_Start_WithBaseContext(t *testing.T) {
+	t.Parallel()
+
+	type contextKey string
+
+	server := httpserver.New(
+		&httpserver.Config{Host: "127.0.0.1", Addresses: []string{"127.0.0.1:8098"}},
+		httpserver.WithLogger(&mockLogger{}),
+		httpserver.WithBaseContext(context.WithValue(context.Background(), contextKey("app"), "base")),
+		httpserver.WithConnContext(func(ctx context.Context, conn net.Conn) context.Context {
+			return context.WithValue(ctx, contextKey("conn"), conn.LocalAddr().String())
+		}),
+		httpserver.WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprintf(w, "%v %v", r.Context().Value(contextKey("app")), r.Context().Value(contextKey("conn")))
+		})),
+	)
+
+	require.NoError(t, server.Start(context.Background()))
+
+	for _, address := range []string{server.Addr().String(), "127.0.0.1:8098"} {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+address, nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		assert.Equal(t, "base "+address, string(body))
+	}
+
+	require.NoError(t, server.Stop(context.Background()))
+}
+
+func TestHTTPServer_Hooks(t *testing.T) {
+	t.Parallel()
+
+	var (
+		events []string
+		mutex  sync.Mutex
+	)
+
+	record := func(event string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		events = append(events, event)
+	}
+
+	listener, err := (&net.ListenConfig{}).Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := httpserver.New(
+		&httpserver.Config{Host: "127.0.0.1"},
+		httpserver.WithLogger(&mockLogger{}),
+		httpserver.WithListener(listener),
+		httpserver.WithOnStart(func(addr net.Addr) { record("start " + addr.String()) }),
+		httpserver.WithOnStop(func() { record("stop") }),
+		httpserver.WithOnStopped(func(err error) { record(fmt.Sprintf("stopped %v", err)) }),
+		httpserver.WithOnError(func(err error) { record("error " + err.Error()) }),
+	)
+
+	require.NoError(t, server.Start(context.Background()))
+
+	// Closing the listener makes Serve fail after Start returned.
+	require.NoError(t, listener.Close())
+
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return len(events) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, server.Stop(context.Background()))
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	require.Len(t, events, 4)
+	assert.Equal(t, "start "+listener.Addr().String(), events[0])
+	assert.Contains(t, events[1], "error ")
+	assert.Equal(t, []string{"stop", "stopped <nil>"}, events[2:])
+}
+
+func TestHTTPServer_Err(t *testing.T) {
+	t.Parallel()
+
+	listener, err := (&net.ListenConfig{}).Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := httpserver.New(
+		&httpserver.Config{Host: "127.0.0.1"},
+		httpserver.WithLogger(&mockLogger{}),
+		httpserver.WithListener(listener),
+	)
+
+	require.NoError(t, server.Start(context.Background()))
+	assert.Empty(t, server.Err())
+
+	require.NoError(t, listener.Close())
+
+	select {
+	case err := <-server.Err():
+		require.ErrorIs(t, err, net.ErrClosed)
+	case <-time.After(time.Second):
+		t.Fatal("server failure was not reported")
+	}
+
+	require.NoError(t, server.Stop(context.Background()))
+}
+
+func TestHTTPServer_Start_Redirect(t *testing.T) {
+	t.Parallel()
+
+	certFile, keyFile := generateTestCert(t)
+
+	server := httpserver.New(
+		&httpserver.Config{
+			Host:     "127.0.0.1",
+			CertFile: certFile,
+			KeyFile:  keyFile,
+			Redirect: httpserver.RedirectConfig{
+				Address:               "127.0.0.1:8097",
+				HSTSMaxAge:            time.Hour,
+				HSTSIncludeSubdomains: true,
+			},
+		},
+		httpserver.WithLogger(&mockLogger{}),
+		httpserver.WithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})),
+	)
+
+	require.NoError(t, server.Start(context.Background()))
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true, //nolint:gosec // The redirect target is not verified here.
+			MinVersion:         tls.VersionTLS12,
+		}},
+	}
+
+	req, err := http.NewRequestWithContext(
+		context.Background(), http.MethodGet, "http://localhost:8097/path?query=1", nil,
+	)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusMovedPermanently, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Strict-Transport-Security"))
+
+	location := fmt.Sprintf("https://localhost:%d/path?query=1", server.Port())
+	assert.Equal(t, location, resp.Header.Get("Location"))
+
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodGet, location, nil)
+	require.NoError(t, err)
+
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+	assert.Equal(t, "max-age=3600; includeSubDomains", resp.Header.Get("Strict-Transport-Security"))
+
+	require.NoError(t, server.Stop(context.Background()))
+}
+
+func TestHTTPServer_Addr(t *testing.T) {
+	t.Parallel()
+
+	server := httpserver.New(
+		&httpserver.Config{Host: "127.0.0.1", Port: 0},
+		httpserver.WithLogger(&mockLogger{}),
+		httpserver.WithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})),
+	)
+
+	assert.Nil(t, server.Addr())
+	assert.Zero(t, server.Port())
+
+	require.NoError(t, server.Start(context.Background()))
+
+	require.NotZero(t, server.Port())
+	assert.Equal(t, fmt.Sprintf("127.0.0.1:%d", server.Port()), server.Addr().String())
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+server.Addr().String(), nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+
+	require.NoError(t, server.Stop(context.Background()))
+}
+
+func TestHTTPServer_Start_MaxConnections(t *testing.T) {
+	t.Parallel()
+
+	server := httpserver.New(
+		&httpserver.Config{Host: "127.0.0.1", Port: 8090, MaxConnections: 1},
+		httpserver.WithLogger(&mockLogger{}),
+		httpserver.WithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})),
+	)
+
+	require.NoError(t, server.Start(context.Background()))
+
+	// The first connection takes the only slot until it is closed.
+	conn, err := (&net.Dialer{}).DialContext(context.Background(), "tcp", "127.0.0.1:8090")
+	require.NoError(t, err)
+
+	statusCh := make(chan int, 1)
+
+	go func() {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://127.0.0.1:8090", nil)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			statusCh <- 0
+
+			return
+		}
+
+		_ = resp.Body.Close()
+		statusCh <- resp.StatusCode
+	}()
+
+	assert.Eventually(t, func() bool { return server.ConnectionLimitHits() == 1 }, time.Second, 10*time.Millisecond)
+	assert.Empty(t, statusCh)
+
+	require.NoError(t, conn.Close())
+	assert.Equal(t, http.StatusTeapot, <-statusCh)
+
+	require.NoError(t, server.Stop(context.Background()))
+}
+
+func TestHTTPServer_Start_Listener(t *testing.T) {
+	t.Parallel()
+
+	var controlled atomic.Int32
+
+	newServer := func() *httpserver.HTTPServer {
+		return httpserver.New(
+			&httpserver.Config{
+				Host: "127.0.0.1",
+				Port: 8091,
+				Listener: httpserver.ListenerConfig{
+					KeepAlive:      time.Minute,
+					DisableNoDelay: true,
+					ReusePort:      true,
+				},
+			},
+			httpserver.WithLogger(&mockLogger{}),
+			httpserver.WithListenControl(func(_, _ string, _ syscall.RawConn) error {
+				controlled.Add(1)
+
+				return nil
+			}),
+		)
+	}
+
+	// Both servers listen on the same address with SO_REUSEPORT.
+	first, second := newServer(), newServer()
+	require.NoError(t, first.Start(context.Background()))
+	require.NoError(t, second.Start(context.Background()))
+	assert.Equal(t, int32(2), controlled.Load())
+
+	require.NoError(t, first.Stop(context.Background()))
+	require.NoError(t, second.Stop(context.Background()))
+}
+
+func TestHTTPServer_InheritFiles(t *testing.T) {
+	t.Parallel()
+
+	server := httpserver.New(
+		&httpserver.Config{Host: "127.0.0.1", Port: 8092, Listener: httpserver.ListenerConfig{DisableNoDelay: true}},
+		httpserver.WithLogger(&mockLogger{}),
+	)
+
+	files, err := server.InheritFiles()
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	require.NoError(t, server.Start(context.Background()))
+
+	files, err = server.InheritFiles()
+	require.NoError(t, err)
+	require.Contains(t, files, "httpserver:127.0.0.1:8092")
+
+	// The handed over socket serves the same address.
+	listener, err := net.FileListener(files["httpserver:127.0.0.1:8092"])
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:8092", listener.Addr().String())
+
+	require.NoError(t, listener.Close())
+	require.NoError(t, files["httpserver:127.0.0.1:8092"].Close())
+	require.NoError(t, server.Stop(context.Background()))
+}
+
+func TestHTTPServer_Stop(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr          error
+		name             string
+		port             int
+		forceStopTimeout time.Duration
+	}{
+		{name: "waits for active requests", port: 8093, forceStopTimeout: 0},
+		{
+			name:             "closes connections after force stop timeout",
+			port:             8094,
+			forceStopTimeout: 50 * time.Millisecond,
+			wantErr:          context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			release := make(chan struct{})
+			started := make(chan struct{})
+
+			server := httpserver.New(
+				&httpserver.Config{Host: "127.0.0.1", Port: tt.port, ForceStopTimeout: tt.forceStopTimeout},
+				httpserver.WithLogger(&mockLogger{}),
+				httpserver.WithHandler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					close(started)
+					select {
+					case <-release:
+					case <-r.Context().Done():
+					}
+				})),
+			)
+
+			require.NoError(t, server.Start(context.Background()))
+
+			go func() {
+				req, _ := http.NewRequestWithContext(
+					context.Background(), http.MethodGet, fmt.Sprintf("http://127.0.0.1:%d", tt.port), nil,
+				)
+
+				resp, err := http.DefaultClient.Do(req)
+				if err == nil {
+					_ = resp.Body.Close()
+				}
+			}()
+
+			<-started
+			assert.Equal(t, int64(1), server.ActiveRequests())
+			assert.Equal(t, int64(1), server.OpenConnections())
+
+			if tt.forceStopTimeout == 0 {
+				time.AfterFunc(100*time.Millisecond, func() { close(release) })
+			}
+
+			err := server.Stop(context.Background())
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+
+			assert.Eventually(t, func() bool {
+				return server.ActiveRequests() == 0 && server.OpenConnections() == 0
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestNew_MaxRequestBodyBytes(t *testing.T) {
+	t.Parallel()
+
+	server := httpserver.New(
+		&httpserver.Config{MaxRequestBodyBytes: 4},
+		httpserver.WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+
+				return
+			}
+
+			_, _ = w.Write(body)
+		})),
+	)
+
+	tests := []struct {
+		body          io.Reader
+		name          string
+		contentLength int64
+		wantStatus    int
+	}{
+		{name: "body within limit", body: strings.NewReader("1234"), contentLength: 4, wantStatus: http.StatusOK},
+		{
+			name:          "announced body exceeds limit",
+			body:          strings.NewReader("12345"),
+			contentLength: 5,
+			wantStatus:    http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:          "streamed body exceeds limit",
+			body:          strings.NewReader("12345"),
+			contentLength: -1,
+			wantStatus:    http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/", tt.body)
+			req.ContentLength = tt.contentLength
+
+			rec := httptest.NewRecorder()
+			server.Server.Handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestNew_TLSProfile(t *testing.T) {
+	t.Parallel()
+
+	certFile, keyFile := generateTestCert(t)
+
+	tests := []struct {
+		name             string
+		profile          string
+		opts             []httpserver.Option
+		wantMinVersion   uint16
+		wantCipherSuites int
+	}{
+		{name: "default", wantMinVersion: tls.VersionTLS12},
+		{name: "modern", profile: httpserver.TLSProfileModern, wantMinVersion: tls.VersionTLS13},
+		{
+			name:             "intermediate",
+			profile:          httpserver.TLSProfileIntermediate,
+			wantMinVersion:   tls.VersionTLS12,
+			wantCipherSuites: 6,
+		},
+		{name: "old", profile: httpserver.TLSProfileOld, wantMinVersion: tls.VersionTLS10, wantCipherSuites: 18},
+		{
+			name:             "custom settings take precedence",
+			profile:          httpserver.TLSProfileIntermediate,
+			opts:             []httpserver.Option{httpserver.WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS13})},
+			wantMinVersion:   tls.VersionTLS13,
+			wantCipherSuites: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httpserver.New(
+				&httpserver.Config{CertFile: certFile, KeyFile: keyFile, TLSProfile: tt.profile},
+				tt.opts...,
+			)
+
+			require.NotNil(t, server.Server.TLSConfig)
+			assert.Equal(t, tt.wantMinVersion, server.Server.TLSConfig.MinVersion)
+			assert.Len(t, server.Server.TLSConfig.CipherSuites, tt.wantCipherSuites)
+		})
+	}
+}
+
+func TestNew_Limits(t *testing.T) {
+	t.Parallel()
+
+	server := httpserver.New(&httpserver.Config{
+		MaxHeaderBytes: 1 << 16,
+		HTTP2:          httpserver.HTTP2Config{MaxConcurrentStreams: 100, MaxReadFrameSize: 1 << 14},
+	})
+
+	assert.Equal(t, 1<<16, server.Server.MaxHeaderBytes)
+	require.NotNil(t, server.Server.HTTP2)
+	assert.Equal(t, 100, server.Server.HTTP2.MaxConcurrentStreams)
+	assert.Equal(t, 1<<14, server.Server.HTTP2.MaxReadFrameSize)
+}
+
+func TestNew_DisableHTTP2(t *testing.T) {
+	t.Parallel()
+
+	server := httpserver.New(&httpserver.Config{DisableHTTP2: true})
+
+	assert.True(t, server.Server.Protocols.HTTP1())
+	assert.False(t, server.Server.Protocols.HTTP2())
+	assert.False(t, server.Server.Protocols.UnencryptedHTTP2())
+}
+
+func TestNew_BasePath(t *testing.T) {
+	t.Parallel()
+
+	server := httpserver.New(
+		&httpserver.Config{BasePath: "/api"},
+		httpserver.WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.URL.Path))
+		})),
+	)
+
+	tests := []struct {
+		name         string
+		target       string
+		wantBody     string
+		wantLocation string
+		wantStatus   int
+	}{
+		{name: "route below base path", target: "/api/users", wantStatus: http.StatusOK, wantBody: "/users"},
+		{name: "root below base path", target: "/api/", wantStatus: http.StatusOK, wantBody: "/"},
+		{
+			name:         "base path is redirected",
+			target:       "/api?page=2",
+			wantStatus:   http.StatusMovedPermanently,
+			wantLocation: "/api/?page=2",
+		},
+		{name: "similar prefix", target: "/apiv2/users", wantStatus: http.StatusNotFound},
+		{name: "outside base path", target: "/users", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			server.Server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, http.NoBody))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantLocation, rec.Header().Get("Location"))
+
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
 			}
 		})
 	}
@@ -0,0 +1,63 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// MaxBodySize returns a route middleware limiting the request body to the number of bytes, e.g.
+// r.Post("/upload", handler, httpserver.MaxBodySize(32<<20)). Requests announcing a larger body are answered
+// with 413 right away; reading beyond the limit fails with *http.MaxBytesError, on which the server closes the
+// connection after the response.
+func MaxBodySize(limit int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			if req.ContentLength > limit {
+				http.Error(resp, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+
+				return
+			}
+
+			req.Body = http.MaxBytesReader(resp, req.Body, limit)
+			next.ServeHTTP(resp, req)
+		})
+	}
+}
+
+// Timeout returns a route middleware replacing the read and write timeouts of the server for the route,
+// e.g. r.Get("/export", handler, httpserver.Timeout(10*time.Minute)) for long-running exports, or to tighten
+// them for small APIs. The context of the request is canceled once the timeout passes and reading the request
+// or writing the response fails. Unlike http.TimeoutHandler, responses are not buffered, so they can be streamed.
+// If the handler returns in time, the write deadline of the server is restored, since servers without
+// WriteTimeout would keep the one of the route for later requests on the connection otherwise.
+func Timeout(timeout time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			now := time.Now()
+			deadline := now.Add(timeout)
+
+			var restore time.Time
+			if server, ok := req.Context().Value(http.ServerContextKey).(*http.Server); ok && server.WriteTimeout > 0 {
+				restore = now.Add(server.WriteTimeout)
+			}
+
+			// Writers not supporting deadlines, e.g. of tests, are still bound by the context.
+			controller := http.NewResponseController(resp)
+			_ = controller.SetReadDeadline(deadline)
+			_ = controller.SetWriteDeadline(deadline)
+
+			// A passed deadline is kept, so the response held back by the server fails as intended.
+			defer func() {
+				if time.Now().Before(deadline) {
+					_ = controller.SetWriteDeadline(restore)
+				}
+			}()
+
+			ctx, cancel := context.WithDeadline(req.Context(), deadline)
+			defer cancel()
+
+			next.ServeHTTP(resp, req.WithContext(ctx))
+		})
+	}
+}
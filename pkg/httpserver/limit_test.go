@@ -0,0 +1,131 @@
+package httpserver_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	t.Parallel()
+
+	router := httpserver.NewRouter()
+	router.Post("/upload", func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+
+			return
+		}
+	}, httpserver.MaxBodySize(4))
+
+	tests := []struct {
+		body       io.Reader
+		name       string
+		wantStatus int
+	}{
+		{name: "within limit", body: strings.NewReader("abcd"), wantStatus: http.StatusOK},
+		{name: "announced too large", body: strings.NewReader("abcde"), wantStatus: http.StatusRequestEntityTooLarge},
+		{
+			name:       "read beyond limit",
+			body:       io.MultiReader(strings.NewReader("abc"), strings.NewReader("de")),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/upload", tt.body))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	t.Parallel()
+
+	router := httpserver.NewRouter()
+	router.Get("/fast", func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok := r.Context().Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+		w.WriteHeader(http.StatusNoContent)
+	}, httpserver.Timeout(time.Minute))
+	router.Get("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte("late"))
+	}, httpserver.Timeout(50*time.Millisecond))
+
+	server := httpserver.NewTestServer(router)
+	t.Cleanup(server.Close)
+
+	resp, err := server.Client.Get(server.URL + "/fast")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// The write deadline has passed, so the connection is closed without response.
+	resp, err = server.Client.Get(server.URL + "/slow")
+	if err == nil {
+		_ = resp.Body.Close()
+	}
+
+	assert.Error(t, err)
+}
+
+func TestTimeout_KeepAlive(t *testing.T) {
+	t.Parallel()
+
+	router := httpserver.NewRouter()
+	router.Get("/short", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}, httpserver.Timeout(50*time.Millisecond))
+	router.Get("/later", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	// The server has no WriteTimeout, so it never replaces the write deadline of the connection itself.
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	var reused []bool
+
+	trace := &httptrace.ClientTrace{GotConn: func(info httptrace.GotConnInfo) { reused = append(reused, info.Reused) }}
+	get := func(path string) (int, string) {
+		req, err := http.NewRequestWithContext(httptrace.WithClientTrace(t.Context(), trace), http.MethodGet,
+			server.URL+path, http.NoBody)
+		require.NoError(t, err)
+
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp.StatusCode, string(body)
+	}
+
+	status, _ := get("/short")
+	assert.Equal(t, http.StatusNoContent, status)
+
+	// The second request on the connection is served after the deadline of the first one passed.
+	time.Sleep(100 * time.Millisecond)
+
+	status, body := get("/later")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ok", body)
+	assert.Equal(t, []bool{false, true}, reused)
+}
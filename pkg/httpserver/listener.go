@@ -0,0 +1,139 @@
+package httpserver
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/spacecafe/go-parts/pkg/log"
+)
+
+var (
+	_ net.Listener = (*limitListener)(nil)
+	_ net.Conn     = (*limitConn)(nil)
+	_ net.Listener = (*delayListener)(nil)
+)
+
+// connLimiter bounds the connections open at the same time across all listeners it wraps.
+type connLimiter struct {
+	log log.Logger
+
+	// hits counts how often Accept had to wait for a connection to close.
+	hits *atomic.Uint64
+
+	semaphore chan struct{}
+
+	// limited is set while the limit is reached, so it is logged once instead of for every connection.
+	limited atomic.Bool
+}
+
+// limitListener defers accepting connections while its connLimiter is exhausted,
+// so clients queue in the backlog of the socket instead of overloading the handlers.
+type limitListener struct {
+	net.Listener
+
+	limiter *connLimiter
+
+	// done is closed with the listener to stop waiting for a free slot.
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// delayListener clears TCP_NODELAY on accepted connections, which Go sets by default.
+type delayListener struct {
+	net.Listener
+}
+
+// limitConn frees its slot of the connLimiter when it is closed.
+type limitConn struct {
+	net.Conn
+
+	release func()
+}
+
+func newConnLimiter(limit int, logger log.Logger, hits *atomic.Uint64) *connLimiter {
+	return &connLimiter{
+		log:       logger,
+		hits:      hits,
+		semaphore: make(chan struct{}, limit),
+	}
+}
+
+// acquire takes a slot, waiting until one is free or done is closed. It reports whether a slot was taken.
+func (l *connLimiter) acquire(done <-chan struct{}) bool {
+	select {
+	case l.semaphore <- struct{}{}:
+		l.limited.Store(false)
+
+		return true
+	default:
+	}
+
+	l.hits.Add(1)
+
+	if !l.limited.Swap(true) {
+		l.log.Warn("reached maximum concurrent connections, deferring new connections", "limit", cap(l.semaphore))
+	}
+
+	select {
+	case l.semaphore <- struct{}{}:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+func (l *connLimiter) release() {
+	<-l.semaphore
+}
+
+// wrap returns the listener limited by the connLimiter.
+func (l *connLimiter) wrap(listener net.Listener) *limitListener {
+	return &limitListener{Listener: listener, limiter: l, done: make(chan struct{})}
+}
+
+//nolint:wrapcheck // Errors of the listener are passed through, so http.Server recognizes them.
+func (l *limitListener) Accept() (net.Conn, error) {
+	if !l.limiter.acquire(l.done) {
+		return nil, net.ErrClosed
+	}
+
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		l.limiter.release()
+
+		return nil, err
+	}
+
+	return &limitConn{Conn: conn, release: sync.OnceFunc(l.limiter.release)}, nil
+}
+
+//nolint:wrapcheck // Errors of the listener are passed through, so http.Server recognizes them.
+func (l *limitListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.done)
+	})
+
+	return l.Listener.Close()
+}
+
+//nolint:wrapcheck // Errors of the connection are passed through unchanged.
+func (c *limitConn) Close() error {
+	c.release()
+
+	return c.Conn.Close()
+}
+
+//nolint:wrapcheck // Errors of the listener are passed through, so http.Server recognizes them.
+func (l *delayListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		_ = tcpConn.SetNoDelay(false)
+	}
+
+	return conn, nil
+}
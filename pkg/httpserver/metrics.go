@@ -0,0 +1,250 @@
+package httpserver
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MetricsPath is the path the metrics of WithMetrics are served at.
+const MetricsPath = "/metrics"
+
+var (
+	_ http.Handler = (*Metrics)(nil)
+
+	// DefaultDurationBuckets are the upper bounds in seconds of the request duration histogram,
+	// matching the default buckets of the Prometheus client libraries.
+	//
+	//nolint:gochecknoglobals // Read-only defaults of the histogram.
+	DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+)
+
+// MetricsCollector writes further metrics in the Prometheus text exposition format, e.g. of the application.
+type MetricsCollector interface {
+	// CollectMetrics writes the samples of the collector including their HELP and TYPE lines.
+	CollectMetrics(w io.Writer) error
+}
+
+// MetricsCollectorFunc adapts an ordinary function to the MetricsCollector interface.
+type MetricsCollectorFunc func(w io.Writer) error
+
+// Metrics exposes the metrics of an HTTPServer in the Prometheus text exposition format: requests in flight,
+// connections by state, TLS handshake errors and the duration of requests by status code.
+// Collectors registered with Register are appended, so a single endpoint serves all metrics of the application.
+type Metrics struct {
+	server *HTTPServer
+
+	// durations holds the request duration histogram by status code.
+	durations map[int]*Histogram
+
+	collectors []MetricsCollector
+
+	// tlsHandshakeErrors counts the failed TLS handshakes reported by the servers.
+	tlsHandshakeErrors atomic.Uint64
+
+	// mutex guards durations and collectors.
+	mutex sync.Mutex
+}
+
+// Histogram counts observations in cumulative buckets for the Prometheus text exposition format, e.g. for
+// a MetricsCollector. It is not safe for concurrent use; collectors guard it along with their other state.
+type Histogram struct {
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+}
+
+// CollectMetrics calls f(w).
+func (f MetricsCollectorFunc) CollectMetrics(w io.Writer) error {
+	return f(w)
+}
+
+// NewMetrics creates Metrics, which are bound to a server by WithMetrics.
+func NewMetrics() *Metrics {
+	return &Metrics{durations: map[int]*Histogram{}}
+}
+
+// NewHistogram creates a histogram with the upper bounds of the buckets, e.g. DefaultDurationBuckets.
+func NewHistogram(buckets []float64) *Histogram {
+	return &Histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 {
+	return h.count
+}
+
+// Observe records the value.
+func (h *Histogram) Observe(value float64) {
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+
+	h.count++
+	h.sum += value
+}
+
+// Write writes the bucket, sum and count samples of the histogram named name with the labels, e.g.
+// `code="200"`, which may be empty.
+func (h *Histogram) Write(w io.Writer, name, labels string) {
+	prefix := ""
+	if labels != "" {
+		prefix = labels + ","
+	}
+
+	for i, bound := range h.buckets {
+		fmt.Fprintf(w, "%s_bucket{%sle=%q} %d\n", name, prefix, strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+	}
+
+	fmt.Fprintf(w, "%s_bucket{%sle=\"+Inf\"} %d\n", name, prefix, h.count)
+
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels, h.count)
+}
+
+// Register appends the metrics of the collector to the exposition.
+func (m *Metrics) Register(collector MetricsCollector) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.collectors = append(m.collectors, collector)
+}
+
+// ServeHTTP writes all metrics.
+func (m *Metrics) ServeHTTP(resp http.ResponseWriter, _ *http.Request) {
+	resp.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	err := m.write(resp)
+	if err != nil && m.server != nil {
+		m.server.Log.Error("failed to write metrics", "error", err)
+	}
+}
+
+// observe records the duration of a request answered with the status code.
+func (m *Metrics) observe(code int, duration time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	durations, ok := m.durations[code]
+	if !ok {
+		durations = NewHistogram(DefaultDurationBuckets)
+		m.durations[code] = durations
+	}
+
+	durations.Observe(duration.Seconds())
+}
+
+// wrap serves the metrics at MetricsPath and records the duration of all other requests.
+func (m *Metrics) wrap(handler http.Handler) http.Handler {
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == MetricsPath && (req.Method == http.MethodGet || req.Method == http.MethodHead) {
+			m.ServeHTTP(resp, req)
+
+			return
+		}
+
+		start := time.Now()
+		writer := NewResponseWriter(resp)
+
+		defer func() {
+			code := writer.Status()
+			if code == 0 {
+				code = http.StatusOK
+			}
+
+			m.observe(code, time.Since(start))
+		}()
+
+		handler.ServeHTTP(writer, req)
+	})
+}
+
+// write writes the metrics of the server followed by those of the registered collectors.
+func (m *Metrics) write(w io.Writer) error {
+	var builder strings.Builder
+
+	if m.server != nil {
+		WriteMetricHeader(&builder, "httpserver_requests_in_flight", "gauge", "Requests being handled.")
+		fmt.Fprintf(&builder, "httpserver_requests_in_flight %d\n", m.server.ActiveRequests())
+
+		states := m.server.connectionStates()
+
+		WriteMetricHeader(&builder, "httpserver_connections", "gauge", "Open connections by state.")
+
+		for _, state := range []http.ConnState{http.StateNew, http.StateActive, http.StateIdle} {
+			fmt.Fprintf(&builder, "httpserver_connections{state=%q} %d\n", state.String(), states[state])
+		}
+
+		if m.server.currentStapler() != nil {
+			writeOCSPMetrics(&builder, m.server.OCSPStatus())
+		}
+	}
+
+	WriteMetricHeader(&builder, "httpserver_tls_handshake_errors_total", "counter", "Failed TLS handshakes.")
+	fmt.Fprintf(&builder, "httpserver_tls_handshake_errors_total %d\n", m.tlsHandshakeErrors.Load())
+
+	m.mutex.Lock()
+	m.writeDurations(&builder)
+	collectors := slices.Clone(m.collectors)
+	m.mutex.Unlock()
+
+	_, err := io.WriteString(w, builder.String())
+	if err != nil {
+		return fmt.Errorf("httpserver: failed to write metrics: %w", err)
+	}
+
+	for _, collector := range collectors {
+		err = collector.CollectMetrics(w)
+		if err != nil {
+			return fmt.Errorf("httpserver: failed to collect metrics: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeDurations writes the request duration histogram ordered by status code.
+func (m *Metrics) writeDurations(builder *strings.Builder) {
+	WriteMetricHeader(builder, "httpserver_request_duration_seconds", "histogram", "Duration of requests by status code.")
+
+	for _, code := range slices.Sorted(maps.Keys(m.durations)) {
+		m.durations[code].Write(builder, "httpserver_request_duration_seconds", fmt.Sprintf("code=\"%d\"", code))
+	}
+}
+
+// WriteMetricHeader writes the HELP and TYPE lines of a metric, e.g. of the kind "counter".
+func WriteMetricHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// writeOCSPMetrics writes whether a valid OCSP response is stapled and when it expires.
+func writeOCSPMetrics(builder *strings.Builder, status OCSPStatus) {
+	stapled := 0
+	if status.Stapled {
+		stapled = 1
+	}
+
+	WriteMetricHeader(builder, "httpserver_ocsp_stapled", "gauge", "Whether a valid OCSP response is stapled.")
+	fmt.Fprintf(builder, "httpserver_ocsp_stapled %d\n", stapled)
+
+	WriteMetricHeader(builder, "httpserver_ocsp_next_update_seconds", "gauge", "Expiry of the stapled OCSP response.")
+	fmt.Fprintf(builder, "httpserver_ocsp_next_update_seconds %d\n", max(status.NextUpdate.Unix(), 0))
+}
@@ -0,0 +1,121 @@
+package httpserver_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMetrics(t *testing.T) {
+	t.Parallel()
+
+	metrics := httpserver.NewMetrics()
+	metrics.Register(httpserver.MetricsCollectorFunc(func(w io.Writer) error {
+		_, err := io.WriteString(w, "# TYPE app_jobs gauge\napp_jobs 3\n")
+
+		return err //nolint:wrapcheck // Required for testing.
+	}))
+
+	server := httpserver.New(
+		&httpserver.Config{BasePath: "/api"},
+		httpserver.WithMetrics(metrics),
+		httpserver.WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/found" {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})),
+	)
+
+	for _, target := range []string{"/api/found", "/api/found", "/api/missing"} {
+		server.Server.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, http.NoBody))
+	}
+
+	rec := httptest.NewRecorder()
+	server.Server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	for _, want := range []string{
+		"httpserver_requests_in_flight 1\n",
+		"httpserver_connections{state=\"active\"} 0\n",
+		"httpserver_tls_handshake_errors_total 0\n",
+		"httpserver_request_duration_seconds_bucket{code=\"200\",le=\"+Inf\"} 2\n",
+		"httpserver_request_duration_seconds_count{code=\"200\"} 2\n",
+		"httpserver_request_duration_seconds_count{code=\"404\"} 1\n",
+		"app_jobs 3\n",
+	} {
+		assert.Contains(t, rec.Body.String(), want)
+	}
+}
+
+func TestWithMetrics_TLSHandshakeErrors(t *testing.T) {
+	t.Parallel()
+
+	certFile, keyFile := generateTestCert(t)
+	metrics := httpserver.NewMetrics()
+
+	server := httpserver.New(
+		&httpserver.Config{Host: "127.0.0.1", Port: 8096, CertFile: certFile, KeyFile: keyFile},
+		httpserver.WithLogger(&mockLogger{}),
+		httpserver.WithMetrics(metrics),
+	)
+
+	require.NoError(t, server.Start(context.Background()))
+
+	// A client speaking plain HTTP fails the TLS handshake.
+	conn, err := (&net.Dialer{}).DialContext(context.Background(), "tcp", "127.0.0.1:8096")
+	require.NoError(t, err)
+
+	_, err = fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	require.NoError(t, err)
+
+	_, _ = io.ReadAll(conn)
+	require.NoError(t, conn.Close())
+
+	assert.Eventually(t, func() bool {
+		rec := httptest.NewRecorder()
+		metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+
+		return strings.Contains(rec.Body.String(), "httpserver_tls_handshake_errors_total 1\n")
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, server.Stop(context.Background()))
+}
+
+func TestHistogram(t *testing.T) {
+	t.Parallel()
+
+	histogram := httpserver.NewHistogram([]float64{1, 10})
+	histogram.Observe(0.5)
+	histogram.Observe(5)
+	histogram.Observe(50)
+
+	var labeled, unlabeled strings.Builder
+
+	histogram.Write(&labeled, "size", `code="200"`)
+	histogram.Write(&unlabeled, "size", "")
+
+	assert.Equal(t, uint64(3), histogram.Count())
+	assert.Equal(t, `size_bucket{code="200",le="1"} 1
+size_bucket{code="200",le="10"} 2
+size_bucket{code="200",le="+Inf"} 3
+size_sum{code="200"} 55.5
+size_count{code="200"} 3
+`, labeled.String())
+	assert.Equal(t, `size_bucket{le="1"} 1
+size_bucket{le="10"} 2
+size_bucket{le="+Inf"} 3
+size_sum 55.5
+size_count 3
+`, unlabeled.String())
+}
@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/spacecafe/go-parts/pkg/log"
+)
+
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+var (
+	_ config.Defaultable = (*AccessLogConfig)(nil)
+	_ config.Validatable = (*AccessLogConfig)(nil)
+
+	ErrInvalidAccessLogField = errors.New("access log: fields must be known request or response attributes")
+	ErrInvalidAccessLogLevel = errors.New("access log: levels must be debug, info, warn or error")
+)
+
+// AccessLogConfig holds the configuration for the AccessLog middleware.
+type AccessLogConfig struct {
+	// Level is the level of records of responses with a status below 400.
+	// Default: "info"
+	Level string `json:"level" yaml:"level"`
+
+	// ClientErrorLevel is the level of records of responses with a 4xx status.
+	// Default: "warn"
+	ClientErrorLevel string `json:"clientErrorLevel" yaml:"clientErrorLevel"`
+
+	// ServerErrorLevel is the level of records of responses with a 5xx status.
+	// Default: "error"
+	ServerErrorLevel string `json:"serverErrorLevel" yaml:"serverErrorLevel"`
+
+	// Fields is the list of attributes of each record, out of "remote_addr", "method", "scheme", "host", "path",
+	// "query", "proto", "content_length", "user_agent", "referer", "status", "size" and "duration".
+	// Default: all of them
+	Fields []string `json:"fields" yaml:"fields"`
+
+	// ExcludedPaths is a list of request paths that are not logged, e.g. "/healthz".
+	// Default: []
+	ExcludedPaths []string `json:"excludedPaths" yaml:"excludedPaths"`
+}
+
+func (c *AccessLogConfig) SetDefaults() {
+	c.Level = LevelInfo
+	c.ClientErrorLevel = LevelWarn
+	c.ServerErrorLevel = LevelError
+	c.Fields = accessLogFields()
+	c.ExcludedPaths = []string{}
+}
+
+func (c *AccessLogConfig) Validate() error {
+	for _, level := range []string{c.Level, c.ClientErrorLevel, c.ServerErrorLevel} {
+		if !slices.Contains([]string{LevelDebug, LevelInfo, LevelWarn, LevelError}, level) {
+			return fmt.Errorf("%w: %q", ErrInvalidAccessLogLevel, level)
+		}
+	}
+
+	for _, field := range c.Fields {
+		if !slices.Contains(accessLogFields(), field) {
+			return fmt.Errorf("%w: %q", ErrInvalidAccessLogField, field)
+		}
+	}
+
+	return nil
+}
+
+// AccessLog returns a middleware that logs every request once it has been answered, together with the status,
+// the number of body bytes written and the time it took. It logs with the logger of the request context, so
+// behind the Logger middleware the records carry its request ID, route pattern and principal as well.
+func AccessLog(cfg *AccessLogConfig) httpserver.Middleware {
+	if cfg == nil {
+		cfg = &AccessLogConfig{}
+		cfg.SetDefaults()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			if slices.Contains(cfg.ExcludedPaths, req.URL.Path) {
+				next.ServeHTTP(resp, req)
+
+				return
+			}
+
+			start := time.Now()
+			writer := httpserver.NewResponseWriter(resp)
+
+			next.ServeHTTP(writer, req)
+
+			status := writer.Status()
+			if status == 0 {
+				// Handlers writing neither a status nor a body answer with 200.
+				status = http.StatusOK
+			}
+
+			args := make([]any, 0, 2*len(cfg.Fields))
+			for _, field := range cfg.Fields {
+				args = append(args, field, accessLogValue(field, req, writer, status, time.Since(start)))
+			}
+
+			logAt(log.From(req.Context()), statusLevel(cfg, status), "handled request", args...)
+		})
+	}
+}
+
+// accessLogFields returns the names of all attributes AccessLog can record.
+func accessLogFields() []string {
+	return []string{
+		"remote_addr", "method", "scheme", "host", "path", "query", "proto", "content_length", "user_agent",
+		"referer", "status", "size", "duration",
+	}
+}
+
+// accessLogValue returns the value of the attribute of an access log record.
+func accessLogValue(
+	field string,
+	req *http.Request,
+	writer *httpserver.ResponseWriter,
+	status int,
+	duration time.Duration,
+) any {
+	switch field {
+	case "remote_addr":
+		return req.RemoteAddr
+	case "method":
+		return req.Method
+	case "scheme":
+		return req.URL.Scheme
+	case "host":
+		return req.Host
+	case "path":
+		return req.URL.Path
+	case "query":
+		return req.URL.RawQuery
+	case "proto":
+		return req.Proto
+	case "content_length":
+		return req.ContentLength
+	case "user_agent":
+		return req.UserAgent()
+	case "referer":
+		return req.Referer()
+	case "status":
+		return status
+	case "size":
+		return writer.Size()
+	case "duration":
+		return duration
+	default:
+		return nil
+	}
+}
+
+// statusLevel returns the level of records of responses with the status.
+func statusLevel(cfg *AccessLogConfig, status int) string {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return cfg.ServerErrorLevel
+	case status >= http.StatusBadRequest:
+		return cfg.ClientErrorLevel
+	default:
+		return cfg.Level
+	}
+}
+
+// logAt logs the record with the method of the logger for the level.
+func logAt(logger log.Logger, level, msg string, args ...any) {
+	switch level {
+	case LevelDebug:
+		logger.Debug(msg, args...)
+	case LevelWarn:
+		logger.Warn(msg, args...)
+	case LevelError:
+		logger.Error(msg, args...)
+	default:
+		logger.Info(msg, args...)
+	}
+}
@@ -0,0 +1,149 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/spacecafe/go-parts/pkg/httpserver/middleware"
+	"github.com/spacecafe/go-parts/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		configure func(cfg *middleware.AccessLogConfig)
+		wantLevel string
+		name      string
+		path      string
+		status    int
+		wantLines int
+	}{
+		{name: "success", path: "/items/1", status: http.StatusOK, wantLines: 2, wantLevel: "INFO"},
+		{name: "client error", path: "/items/1", status: http.StatusNotFound, wantLines: 2, wantLevel: "WARN"},
+		{name: "server error", path: "/items/1", status: http.StatusBadGateway, wantLines: 2, wantLevel: "ERROR"},
+		{
+			name:      "custom level",
+			path:      "/items/1",
+			status:    http.StatusOK,
+			configure: func(cfg *middleware.AccessLogConfig) { cfg.Level = middleware.LevelWarn },
+			wantLines: 2,
+			wantLevel: "WARN",
+		},
+		{
+			name:      "excluded path",
+			path:      "/healthz",
+			status:    http.StatusOK,
+			configure: func(cfg *middleware.AccessLogConfig) { cfg.ExcludedPaths = []string{"/healthz"} },
+			wantLines: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			cfg := &middleware.AccessLogConfig{}
+			cfg.SetDefaults()
+
+			if tt.configure != nil {
+				tt.configure(cfg)
+			}
+
+			require.NoError(t, cfg.Validate())
+
+			router := httpserver.NewRouter()
+			router.Use(middleware.Logger(slog.New(slog.NewJSONHandler(&buf, nil))), middleware.AccessLog(cfg))
+			router.HandleFunc("GET /", func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte("hello"))
+			})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path+"?page=2", http.NoBody))
+			assert.Equal(t, tt.status, rec.Code)
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			require.Len(t, lines, tt.wantLines)
+
+			if tt.wantLines == 1 {
+				return
+			}
+
+			var record map[string]any
+
+			require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+			assert.Equal(t, "handled request", record["msg"])
+			assert.Equal(t, tt.wantLevel, record["level"])
+			assert.Equal(t, rec.Header().Get(middleware.RequestIDHeader), record["request_id"])
+			assert.Equal(t, "GET /", record["route"])
+			assert.Equal(t, http.MethodGet, record["method"])
+			assert.Equal(t, tt.path, record["path"])
+			assert.Equal(t, "page=2", record["query"])
+			assert.InDelta(t, tt.status, record["status"], 0)
+			assert.InDelta(t, 5, record["size"], 0)
+			assert.Contains(t, record, "duration")
+		})
+	}
+}
+
+func TestAccessLog_Fields(t *testing.T) {
+	t.Parallel()
+
+	logger := &recordingLogger{}
+
+	cfg := &middleware.AccessLogConfig{}
+	cfg.SetDefaults()
+	cfg.Fields = []string{"method", "status"}
+
+	handler := middleware.AccessLog(cfg)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(log.Into(req.Context(), logger)))
+
+	require.Len(t, logger.args, 1)
+	assert.Equal(t, []any{"method", http.MethodGet, "status", http.StatusOK}, logger.args[0])
+}
+
+func TestAccessLogConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		configure func(cfg *middleware.AccessLogConfig)
+		wantErr   error
+		name      string
+	}{
+		{name: "defaults", configure: func(*middleware.AccessLogConfig) {}},
+		{
+			name:      "unknown field",
+			configure: func(cfg *middleware.AccessLogConfig) { cfg.Fields = []string{"status", "cookie"} },
+			wantErr:   middleware.ErrInvalidAccessLogField,
+		},
+		{
+			name:      "unknown level",
+			configure: func(cfg *middleware.AccessLogConfig) { cfg.ServerErrorLevel = "fatal" },
+			wantErr:   middleware.ErrInvalidAccessLogLevel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &middleware.AccessLogConfig{}
+			cfg.SetDefaults()
+			tt.configure(cfg)
+
+			require.ErrorIs(t, cfg.Validate(), tt.wantErr)
+		})
+	}
+}
@@ -1,22 +1,35 @@
 package middleware
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/spacecafe/go-parts/pkg/config"
 	"github.com/spacecafe/go-parts/pkg/httpserver"
 	"golang.org/x/crypto/bcrypt"
 )
 
-const authTokenPrefix = "Token "
+const (
+	authTokenPrefix = "Token "
+
+	// tokenPrincipalPrefix prefixes the principal of requests authenticated by a token.
+	tokenPrincipalPrefix = "token:"
+)
 
 var (
 	_ config.Defaultable = (*BasicAuthConfig)(nil)
+	_ config.Validatable = (*BasicAuthConfig)(nil)
 
-	ErrMismatchPassword = errors.New("basic-auth: password mismatch")
+	ErrMismatchPassword        = errors.New("basic-auth: password mismatch")
+	ErrInvalidHtpasswdInterval = errors.New("basic-auth: htpasswd interval must not be negative")
 
 	//nolint:gochecknoglobals // Maintain a set of predefined bcrypt prefixes that are used throughout the application.
 	BcryptHashPrefixes = []string{"$2a$", "$2b$", "$2x$", "$2y$"}
@@ -27,34 +40,78 @@ type Authenticator func(username, password string) bool
 type BasicAuthConfig struct {
 	Principals    map[string]string `json:"principals" yaml:"principals"`
 	Authenticator Authenticator
-	Tokens        []string `json:"tokens"     yaml:"tokens"`
-	UseTokens     bool
+
+	// HtpasswdFile is the path of an htpasswd file whose bcrypt, APR1 and SHA entries are accepted in addition to
+	// the Authenticator. The file is not watched in the background: requests check it for changes at most every
+	// HtpasswdInterval and reload it before they are authenticated, so a revoked user is rejected from the first
+	// request after the change. If the file became invalid, the entries loaded before remain in effect and the
+	// failure is logged with that request, not when the file is written.
+	// Default: ""
+	HtpasswdFile string   `json:"htpasswdFile" yaml:"htpasswdFile"`
+	Tokens       []string `json:"tokens"       yaml:"tokens"`
+
+	// HtpasswdInterval is the interval the HtpasswdFile is checked for changes at.
+	// Default: DefaultHtpasswdInterval
+	HtpasswdInterval time.Duration `json:"htpasswdInterval" yaml:"htpasswdInterval"`
+	UseTokens        bool
 }
 
 func (c *BasicAuthConfig) SetDefaults() {
 	c.Principals = map[string]string{}
 	c.Tokens = []string{}
 	c.Authenticator = configAuthenticator(c)
+	c.HtpasswdFile = ""
+	c.HtpasswdInterval = DefaultHtpasswdInterval
 	c.UseTokens = false
 }
 
+func (c *BasicAuthConfig) Validate() error {
+	if c.HtpasswdInterval < 0 {
+		return ErrInvalidHtpasswdInterval
+	}
+
+	if c.HtpasswdFile != "" {
+		_, err := readHtpasswd(c.HtpasswdFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func BasicAuth(cfg *BasicAuthConfig) httpserver.Middleware {
+	// tokenKey keys the token principals, so they do not fingerprint the tokens beyond this middleware.
+	tokenKey := make([]byte, sha256.Size)
+	_, _ = rand.Read(tokenKey)
+
+	var htpasswd *htpasswdFile
+	if cfg.HtpasswdFile != "" {
+		htpasswd = newHtpasswdFile(cfg.HtpasswdFile, cfg.HtpasswdInterval)
+		htpasswd.refresh(context.Background())
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
 			if cfg.UseTokens {
 				authHeader := req.Header.Get("Authorization")
 
-				if strings.HasPrefix(authHeader, authTokenPrefix) &&
-					cfg.Authenticator("", authHeader[len(authTokenPrefix):]) {
-					next.ServeHTTP(resp, req)
+				token := strings.TrimPrefix(authHeader, authTokenPrefix)
+				if token != authHeader && cfg.Authenticator("", token) {
+					next.ServeHTTP(resp, withPrincipal(req, tokenPrincipal(tokenKey, token)))
 
 					return
 				}
 			}
 
+			if htpasswd != nil {
+				htpasswd.refresh(req.Context())
+			}
+
 			username, password, ok := req.BasicAuth()
-			if ok && cfg.Authenticator(username, password) {
-				next.ServeHTTP(resp, req)
+			if ok && (cfg.Authenticator(username, password) ||
+				htpasswd != nil && htpasswd.authenticate(username, password)) {
+				next.ServeHTTP(resp, withPrincipal(req, username))
 
 				return
 			}
@@ -102,6 +159,16 @@ func ValidatePasswords(expected, actual string) bool {
 	return validator(expectedBytes, actualBytes) == nil
 }
 
+// tokenPrincipal returns the principal of requests authenticated by a token. Tokens carry no username, so the
+// principal is derived from an HMAC-SHA256 of the token with the random key of the middleware. It attributes the
+// requests until the process restarts, but cannot be checked against guessed tokens without the key.
+func tokenPrincipal(key []byte, token string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(token))
+
+	return tokenPrincipalPrefix + hex.EncodeToString(mac.Sum(nil)[:6])
+}
+
 func abortBasicAuth(resp http.ResponseWriter, useTokens bool) {
 	if useTokens {
 		resp.Header().Set("WWW-Authenticate", `Token`)
@@ -3,10 +3,15 @@ package middleware_test
 import (
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/spacecafe/go-parts/pkg/httpserver"
 	"github.com/spacecafe/go-parts/pkg/httpserver/middleware"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBasicAuth(t *testing.T) {
@@ -19,6 +24,7 @@ func TestBasicAuth(t *testing.T) {
 		username       string
 		password       string
 		wantAuthHeader string
+		wantPrincipal  string
 		wantStatus     int
 		basicAuth      bool
 	}{
@@ -31,7 +37,8 @@ func TestBasicAuth(t *testing.T) {
 			headers: map[string]string{
 				"Authorization": "Token valid-token",
 			},
-			wantStatus: http.StatusOK,
+			wantPrincipal: "token:[0-9a-f]{12}",
+			wantStatus:    http.StatusOK,
 		},
 		{
 			name: "invalid token",
@@ -58,10 +65,11 @@ func TestBasicAuth(t *testing.T) {
 			cfg: func(cfg *middleware.BasicAuthConfig) {
 				cfg.Principals = map[string]string{"user": "pass"}
 			},
-			basicAuth:  true,
-			username:   "user",
-			password:   "pass",
-			wantStatus: http.StatusOK,
+			basicAuth:     true,
+			username:      "user",
+			password:      "pass",
+			wantPrincipal: "user",
+			wantStatus:    http.StatusOK,
 		},
 		{
 			name: "invalid basic auth",
@@ -80,10 +88,11 @@ func TestBasicAuth(t *testing.T) {
 				cfg.Tokens = []string{"pass"}
 				cfg.UseTokens = true
 			},
-			basicAuth:  true,
-			username:   "user",
-			password:   "pass",
-			wantStatus: http.StatusOK,
+			basicAuth:     true,
+			username:      "user",
+			password:      "pass",
+			wantPrincipal: "user",
+			wantStatus:    http.StatusOK,
 		},
 	}
 
@@ -95,10 +104,13 @@ func TestBasicAuth(t *testing.T) {
 			cfg.SetDefaults()
 			tt.cfg(cfg)
 
+			var principal string
+
 			handler := middleware.BasicAuth(
 				cfg,
 			)(
-				http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					principal, _ = middleware.PrincipalFromContext(r.Context())
 					w.WriteHeader(http.StatusOK)
 				}),
 			)
@@ -116,6 +128,7 @@ func TestBasicAuth(t *testing.T) {
 			handler.ServeHTTP(rec, req)
 
 			assert.Equal(t, tt.wantStatus, rec.Code, "unexpected status code")
+			assert.Regexp(t, "^"+tt.wantPrincipal+"$", principal, "unexpected principal")
 
 			if tt.wantAuthHeader != "" {
 				assert.Contains(
@@ -128,3 +141,88 @@ func TestBasicAuth(t *testing.T) {
 		})
 	}
 }
+
+func TestBasicAuth_TokenPrincipal(t *testing.T) {
+	t.Parallel()
+
+	cfg := &middleware.BasicAuthConfig{}
+	cfg.SetDefaults()
+	cfg.Tokens = []string{"valid-token"}
+	cfg.UseTokens = true
+
+	principalOf := func(auth httpserver.Middleware) string {
+		var principal string
+
+		handler := auth(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			principal, _ = middleware.PrincipalFromContext(r.Context())
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Authorization", "Token valid-token")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		return principal
+	}
+
+	auth := middleware.BasicAuth(cfg)
+	principal := principalOf(auth)
+
+	assert.Equal(t, principal, principalOf(auth), "principals must be stable per middleware")
+	assert.NotEqual(t, "token:397a2a9c5bf5", principal, "principals must not be the plain SHA-256 of the token")
+	assert.NotEqual(t, principal, principalOf(middleware.BasicAuth(cfg)), "principals must be keyed per middleware")
+}
+
+func TestBasicAuth_Htpasswd(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ".htpasswd")
+	require.NoError(t, os.WriteFile(path, []byte(`# generated by htpasswd
+apr1:$apr1$saltsalt$LrttParrLPdxvgutaSXWJ0
+sha:{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ=
+bcrypt:$2a$04$63iYIgU53lZ7JKEbpdVZbebiuKlws809Ion7vHmDGZfzpkcY9H.8S
+
+crypt:rl0uE7ULmmbzk
+`), 0o600))
+
+	cfg := &middleware.BasicAuthConfig{}
+	cfg.SetDefaults()
+	cfg.HtpasswdFile = path
+	cfg.HtpasswdInterval = time.Nanosecond
+	require.NoError(t, cfg.Validate())
+
+	handler := middleware.BasicAuth(cfg)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(username, password string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.SetBasicAuth(username, password)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("apr1", "secret"))
+	assert.Equal(t, http.StatusOK, serve("sha", "secret"))
+	assert.Equal(t, http.StatusOK, serve("bcrypt", "secret"))
+	assert.Equal(t, http.StatusUnauthorized, serve("apr1", "wrong"))
+	assert.Equal(t, http.StatusUnauthorized, serve("sha", "wrong"))
+	assert.Equal(t, http.StatusUnauthorized, serve("crypt", "secret"))
+	assert.Equal(t, http.StatusUnauthorized, serve("unknown", "secret"))
+
+	require.NoError(t, os.WriteFile(path, []byte("sha:{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ=\n"), 0o600))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	assert.Equal(t, http.StatusUnauthorized, serve("apr1", "secret"), "removed entries must be rejected")
+	assert.Equal(t, http.StatusOK, serve("sha", "secret"))
+
+	require.NoError(t, os.WriteFile(path, []byte("malformed\n"), 0o600))
+	later = later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	assert.Equal(t, http.StatusOK, serve("sha", "secret"), "invalid files must keep the entries loaded before")
+	require.ErrorIs(t, cfg.Validate(), middleware.ErrInvalidHtpasswdFile)
+}
@@ -0,0 +1,475 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+)
+
+const (
+	// zstdMaxLevel is the highest level of the zstd command line tool.
+	zstdMaxLevel = 22
+
+	// zstdMaxWindow is the largest window size browsers decode, see RFC 8878, section 3.1.1.1.2.
+	zstdMaxWindow = 8 << 20
+)
+
+var (
+	_ config.Defaultable = (*CompressConfig)(nil)
+	_ config.Validatable = (*CompressConfig)(nil)
+
+	_ http.Flusher  = (*compressWriter)(nil)
+	_ http.Hijacker = (*compressWriter)(nil)
+
+	_ Codec = BrotliCodec
+	_ Codec = ZstdCodec
+	_ Codec = GzipCodec
+	_ Codec = DeflateCodec
+
+	ErrMissingEncodings     = errors.New("compress: encodings must name at least one codec")
+	ErrInvalidCompressLevel = errors.New("compress: level is not supported by the codec")
+	ErrInvalidMinSize       = errors.New("compress: min size must be non-negative")
+)
+
+// Encoder compresses the data written to it into the writer given to Reset.
+// *gzip.Writer, *flate.Writer, *brotli.Writer and *zstd.Encoder implement it.
+type Encoder interface {
+	io.WriteCloser
+
+	// Flush writes any pending data to the underlying writer.
+	Flush() error
+
+	// Reset discards the state of the encoder and makes it write to w.
+	Reset(w io.Writer)
+}
+
+// Codec creates an Encoder of a content coding at the level writing to w.
+// The level is the one of CompressConfig.Levels, or zero if there is none.
+type Codec func(w io.Writer, level int) (Encoder, error)
+
+// CompressConfig holds the configuration for the Compress middleware.
+type CompressConfig struct {
+	// Codecs maps content codings of Accept-Encoding to their codec.
+	// Default: {"zstd": ZstdCodec, "br": BrotliCodec, "gzip": GzipCodec, "deflate": DeflateCodec}
+	Codecs map[string]Codec `json:"-" yaml:"-"`
+
+	// Levels maps content codings to the compression level passed to their codec.
+	// Default: {} (the default level of every codec)
+	Levels map[string]int `json:"levels" yaml:"levels"`
+
+	// Encodings lists the content codings in the order the server prefers them if a client accepts several
+	// with the same quality. Codings without a codec are skipped.
+	// Default: ["zstd", "br", "gzip", "deflate"]
+	Encodings []string `json:"encodings" yaml:"encodings"`
+
+	// ContentTypes lists the media types of the responses that are compressed. Entries ending with "/" match
+	// all subtypes, e.g. "text/". Responses without Content-Type are sniffed.
+	// Default: ["text/", "application/json", "application/javascript", "application/xml",
+	// "application/problem+json", "application/wasm", "image/svg+xml"]
+	ContentTypes []string `json:"contentTypes" yaml:"contentTypes"`
+
+	// MinSize is the number of body bytes below which responses are sent uncompressed, since the encoding
+	// overhead outweighs the savings.
+	// Default: 1024
+	MinSize int `json:"minSize" yaml:"minSize"`
+}
+
+func (c *CompressConfig) SetDefaults() {
+	c.Codecs = map[string]Codec{"zstd": ZstdCodec, "br": BrotliCodec, "gzip": GzipCodec, "deflate": DeflateCodec}
+	c.Levels = map[string]int{}
+	c.Encodings = []string{"zstd", "br", "gzip", "deflate"}
+	c.ContentTypes = []string{
+		"text/",
+		"application/json",
+		"application/javascript",
+		"application/xml",
+		"application/problem+json",
+		"application/wasm",
+		"image/svg+xml",
+	}
+	c.MinSize = 1024
+}
+
+func (c *CompressConfig) Validate() error {
+	if c.MinSize < 0 {
+		return ErrInvalidMinSize
+	}
+
+	available := false
+
+	for _, encoding := range c.Encodings {
+		codec, found := c.Codecs[encoding]
+		if !found {
+			continue
+		}
+
+		available = true
+
+		encoder, err := codec(io.Discard, c.Levels[encoding])
+		if err != nil {
+			return fmt.Errorf("%w: %s level %d: %w", ErrInvalidCompressLevel, encoding, c.Levels[encoding], err)
+		}
+
+		_ = encoder.Close()
+	}
+
+	if !available {
+		return ErrMissingEncodings
+	}
+
+	return nil
+}
+
+// ZstdCodec creates zstd encoders with the window size of at most 8 MiB browsers support. The levels 1 to 22 of
+// the zstd command line tool are mapped to the closest level of the encoder; zero selects zstd.SpeedDefault.
+//
+//nolint:ireturn // Codecs return encoders by interface.
+func ZstdCodec(w io.Writer, level int) (Encoder, error) {
+	if level < 0 || level > zstdMaxLevel {
+		return nil, fmt.Errorf("%w: zstd level %d out of range [0,%d]", ErrInvalidCompressLevel, level, zstdMaxLevel)
+	}
+
+	speed := zstd.SpeedDefault
+	if level != 0 {
+		speed = zstd.EncoderLevelFromZstd(level)
+	}
+
+	encoder, err := zstd.NewWriter(
+		w,
+		zstd.WithEncoderLevel(speed),
+		zstd.WithEncoderConcurrency(1),
+		zstd.WithWindowSize(zstdMaxWindow),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCompressLevel, err)
+	}
+
+	return encoder, nil
+}
+
+// BrotliCodec creates Brotli encoders. The levels 1 to 11 are passed as they are; zero selects
+// brotli.DefaultCompression.
+//
+//nolint:ireturn // Codecs return encoders by interface.
+func BrotliCodec(w io.Writer, level int) (Encoder, error) {
+	if level < 0 || level > brotli.BestCompression {
+		return nil, fmt.Errorf(
+			"%w: brotli level %d out of range [0,%d]", ErrInvalidCompressLevel, level, brotli.BestCompression,
+		)
+	}
+
+	if level == 0 {
+		level = brotli.DefaultCompression
+	}
+
+	return brotli.NewWriterLevel(w, level), nil
+}
+
+// GzipCodec creates gzip encoders. Level zero selects gzip.DefaultCompression.
+//
+//nolint:ireturn // Codecs return encoders by interface.
+func GzipCodec(w io.Writer, level int) (Encoder, error) {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	encoder, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCompressLevel, err)
+	}
+
+	return encoder, nil
+}
+
+// DeflateCodec creates deflate encoders. Level zero selects flate.DefaultCompression.
+//
+//nolint:ireturn // Codecs return encoders by interface.
+func DeflateCodec(w io.Writer, level int) (Encoder, error) {
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+
+	encoder, err := flate.NewWriter(w, level)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCompressLevel, err)
+	}
+
+	return encoder, nil
+}
+
+// Compress returns a middleware that compresses responses with the content coding the client prefers among the
+// ones of Accept-Encoding with a codec, or the one the server prefers among equally preferred ones.
+// Encoders are pooled per coding. Responses that are small, already encoded, partial or of other media types
+// than ContentTypes are sent as they are.
+func Compress(cfg *CompressConfig) httpserver.Middleware {
+	if cfg == nil {
+		cfg = &CompressConfig{}
+		cfg.SetDefaults()
+	}
+
+	encodings := make([]string, 0, len(cfg.Encodings))
+	pools := map[string]*sync.Pool{}
+
+	for _, encoding := range cfg.Encodings {
+		codec, found := cfg.Codecs[encoding]
+		if !found {
+			continue
+		}
+
+		level := cfg.Levels[encoding]
+		encodings = append(encodings, encoding)
+		pools[encoding] = &sync.Pool{New: func() any {
+			encoder, err := codec(io.Discard, level)
+			if err != nil {
+				return nil
+			}
+
+			return encoder
+		}}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			resp.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateEncoding(req.Header.Values("Accept-Encoding"), encodings)
+			if encoding == "" || req.Method == http.MethodHead {
+				next.ServeHTTP(resp, req)
+
+				return
+			}
+
+			writer := &compressWriter{ResponseWriter: resp, cfg: cfg, pool: pools[encoding], encoding: encoding}
+			defer writer.close()
+
+			next.ServeHTTP(writer, req)
+		})
+	}
+}
+
+// negotiateEncoding returns the content coding of the available ones the Accept-Encoding header values rate
+// highest, preferring earlier ones on ties, or an empty string if none is acceptable.
+func negotiateEncoding(accept []string, available []string) string {
+	qualities := map[string]float64{}
+
+	for _, value := range accept {
+		for coding := range strings.SplitSeq(value, ",") {
+			name, params, _ := strings.Cut(coding, ";")
+			name = strings.ToLower(strings.TrimSpace(name))
+
+			if name == "" {
+				continue
+			}
+
+			quality := 1.0
+
+			for param := range strings.SplitSeq(params, ";") {
+				key, raw, _ := strings.Cut(param, "=")
+				if strings.TrimSpace(key) == "q" {
+					parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+					if err == nil {
+						quality = parsed
+					}
+				}
+			}
+
+			qualities[name] = quality
+		}
+	}
+
+	best, bestQuality := "", 0.0
+
+	for _, encoding := range available {
+		quality, found := qualities[encoding]
+		if !found {
+			quality = qualities["*"]
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+
+	return best
+}
+
+// compressWriter holds back the beginning of the body until MinSize bytes were written, the handler flushes
+// or returns, and then decides whether the response is compressed.
+type compressWriter struct {
+	http.ResponseWriter
+
+	encoder Encoder
+	pool    *sync.Pool
+	cfg     *CompressConfig
+
+	encoding string
+	buffer   []byte
+	status   int
+
+	// decided reports whether the header was sent, so the body is written to the encoder if set or as it is.
+	decided bool
+
+	// hijacked reports whether the handler took over the connection, so nothing is written when it returns.
+	hijacked bool
+}
+
+// Flush sends the body held back so far and flushes the encoder and the wrapped writer.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(true)
+	}
+
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack lets the caller take over the connection of the wrapped writer.
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buffer, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+
+	//nolint:wrapcheck // The caller expects the errors of the underlying connection.
+	return conn, buffer, err
+}
+
+// Unwrap returns the wrapped writer, so http.ResponseController can reach its optional interfaces.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buffer = append(w.buffer, data...)
+		if len(w.buffer) < w.cfg.MinSize {
+			return len(data), nil
+		}
+
+		w.decide(true)
+
+		return len(data), nil
+	}
+
+	if w.encoder != nil {
+		//nolint:wrapcheck // The caller expects the errors of the underlying connection.
+		return w.encoder.Write(data)
+	}
+
+	//nolint:wrapcheck // The caller expects the errors of the underlying connection.
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteHeader records the status code until the body decides about the compression.
+// Informational responses are passed through.
+func (w *compressWriter) WriteHeader(code int) {
+	if code >= 100 && code <= 199 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+
+		return
+	}
+
+	if w.status == 0 {
+		w.status = code
+	}
+
+	if code == http.StatusSwitchingProtocols {
+		w.decide(false)
+	}
+}
+
+// close sends the body held back if the handler did not, and returns the encoder to the pool.
+// It does nothing if the connection was hijacked.
+func (w *compressWriter) close() {
+	if w.hijacked {
+		return
+	}
+
+	if !w.decided {
+		w.decide(len(w.buffer) > 0 && len(w.buffer) >= w.cfg.MinSize)
+	}
+
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+		w.encoder.Reset(io.Discard)
+		w.pool.Put(w.encoder)
+		w.encoder = nil
+	}
+}
+
+// decide sends the header, choosing an encoder if compress is allowed and the response qualifies, and writes
+// the body held back.
+func (w *compressWriter) decide(compress bool) {
+	w.decided = true
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	header := w.Header()
+	if header.Get("Content-Type") == "" && len(w.buffer) > 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buffer))
+	}
+
+	if compress && w.compressible(header) {
+		if encoder, ok := w.pool.Get().(Encoder); ok {
+			encoder.Reset(w.ResponseWriter)
+			w.encoder = encoder
+
+			header.Set("Content-Encoding", w.encoding)
+			header.Del("Content-Length")
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buffer := w.buffer
+	w.buffer = nil
+
+	if len(buffer) > 0 {
+		_, _ = w.Write(buffer)
+	}
+}
+
+// compressible reports whether the response with the header may be compressed.
+func (w *compressWriter) compressible(header http.Header) bool {
+	switch w.status {
+	case http.StatusNoContent, http.StatusPartialContent, http.StatusNotModified:
+		return false
+	}
+
+	if w.status < http.StatusOK || header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	return slices.ContainsFunc(w.cfg.ContentTypes, func(contentType string) bool {
+		if strings.HasSuffix(contentType, "/") {
+			return strings.HasPrefix(mediaType, contentType)
+		}
+
+		return mediaType == contentType
+	})
+}
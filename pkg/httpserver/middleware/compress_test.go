@@ -0,0 +1,323 @@
+package middleware_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/spacecafe/go-parts/pkg/httpserver/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompress(t *testing.T) {
+	t.Parallel()
+
+	body := strings.Repeat(`{"name":"value"}`, 256)
+
+	tests := []struct {
+		handler      http.HandlerFunc
+		name         string
+		accept       string
+		wantEncoding string
+	}{
+		{name: "zstd", accept: "zstd", wantEncoding: "zstd"},
+		{name: "brotli", accept: "br", wantEncoding: "br"},
+		{name: "gzip", accept: "gzip", wantEncoding: "gzip"},
+		{name: "deflate", accept: "deflate", wantEncoding: "deflate"},
+		{name: "server preference on ties", accept: "gzip, deflate, br, zstd", wantEncoding: "zstd"},
+		{name: "server preference of brotli", accept: "gzip, deflate, br", wantEncoding: "br"},
+		{name: "client preference", accept: "zstd;q=0.5, br;q=0.8, gzip", wantEncoding: "gzip"},
+		{name: "wildcard", accept: "*", wantEncoding: "zstd"},
+		{name: "excluded coding", accept: "zstd;q=0, *;q=0.1", wantEncoding: "br"},
+		{name: "unsupported coding", accept: "compress"},
+		{name: "no accept encoding"},
+		{
+			name:   "small body",
+			accept: "gzip",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{}`))
+			},
+		},
+		{
+			name:   "incompressible media type",
+			accept: "gzip",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "image/png")
+				_, _ = w.Write([]byte(body))
+			},
+		},
+		{
+			name:   "already encoded",
+			accept: "gzip",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Content-Encoding", "br")
+				_, _ = w.Write([]byte(body))
+			},
+		},
+		{
+			name:   "no content",
+			accept: "gzip",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+		},
+		{
+			name:         "sniffed media type",
+			accept:       "gzip",
+			wantEncoding: "gzip",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(strings.Repeat("plain text ", 256)))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := tt.handler
+			if handler == nil {
+				handler = func(w http.ResponseWriter, _ *http.Request) {
+					w.Header().Set("Content-Type", "application/json; charset=utf-8")
+					w.Header().Set("Content-Length", "4096")
+					_, _ = w.Write([]byte(body[:2048]))
+					_, _ = w.Write([]byte(body[2048:]))
+				}
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			if tt.accept != "" {
+				req.Header.Set("Accept-Encoding", tt.accept)
+			}
+
+			rec := httptest.NewRecorder()
+			middleware.Compress(nil)(handler).ServeHTTP(rec, req)
+
+			assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+
+			if tt.wantEncoding == "" {
+				assert.NotEqual(t, "gzip", rec.Header().Get("Content-Encoding"))
+
+				return
+			}
+
+			assert.Equal(t, tt.wantEncoding, rec.Header().Get("Content-Encoding"))
+			assert.Empty(t, rec.Header().Get("Content-Length"))
+			assert.Less(t, rec.Body.Len(), len(body))
+
+			decoded, err := io.ReadAll(decompress(t, tt.wantEncoding, rec.Body))
+			require.NoError(t, err)
+			assert.NotEmpty(t, decoded)
+		})
+	}
+}
+
+func TestCompress_Codecs(t *testing.T) {
+	t.Parallel()
+
+	cfg := &middleware.CompressConfig{}
+	cfg.SetDefaults()
+	cfg.MinSize = 0
+	cfg.Levels["gzip"] = gzip.BestSpeed
+	cfg.Codecs["br"] = func(w io.Writer, _ int) (middleware.Encoder, error) {
+		return &upperEncoder{writer: w}, nil
+	}
+	require.NoError(t, cfg.Validate())
+
+	handler := middleware.Compress(cfg)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("hello"))
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte(" world"))
+	}))
+
+	for range 2 {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Accept-Encoding", "gzip, br")
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "HELLO WORLD", rec.Body.String())
+		assert.True(t, rec.Flushed)
+	}
+}
+
+func TestCompress_Levels(t *testing.T) {
+	t.Parallel()
+
+	body := strings.Repeat(`{"name":"value","count":42}`, 1024)
+
+	for _, encoding := range []string{"zstd", "br", "gzip", "deflate"} {
+		t.Run(encoding, func(t *testing.T) {
+			t.Parallel()
+
+			sizes := map[int]int{}
+
+			for _, level := range []int{1, 9} {
+				cfg := &middleware.CompressConfig{}
+				cfg.SetDefaults()
+				cfg.Encodings = []string{encoding}
+				cfg.Levels[encoding] = level
+				require.NoError(t, cfg.Validate())
+
+				handler := middleware.Compress(cfg)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(body))
+				}))
+
+				req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+				req.Header.Set("Accept-Encoding", encoding)
+
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+
+				assert.Equal(t, encoding, rec.Header().Get("Content-Encoding"))
+				sizes[level] = rec.Body.Len()
+
+				decoded, err := io.ReadAll(decompress(t, encoding, rec.Body))
+				require.NoError(t, err)
+				assert.Equal(t, body, string(decoded))
+			}
+
+			assert.LessOrEqual(t, sizes[9], sizes[1])
+		})
+	}
+}
+
+func TestCompress_Hijack(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.Compress(nil)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("held back"))
+		_, _, err := w.(http.Hijacker).Hijack()
+		assert.NoError(t, err)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	handler.ServeHTTP(rec, req)
+
+	assert.True(t, rec.hijacked)
+	assert.False(t, rec.Flushed)
+	assert.Empty(t, rec.Body.String())
+	assert.Empty(t, rec.Header().Get("Content-Type"))
+}
+
+func TestCompressConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		configure func(cfg *middleware.CompressConfig)
+		wantErr   error
+		name      string
+	}{
+		{name: "defaults", configure: func(*middleware.CompressConfig) {}},
+		{
+			name:      "no codec",
+			configure: func(cfg *middleware.CompressConfig) { cfg.Encodings = []string{"compress"} },
+			wantErr:   middleware.ErrMissingEncodings,
+		},
+		{
+			name:      "highest levels",
+			configure: func(cfg *middleware.CompressConfig) { cfg.Levels = map[string]int{"zstd": 22, "br": 11} },
+		},
+		{
+			name:      "invalid level",
+			configure: func(cfg *middleware.CompressConfig) { cfg.Levels["gzip"] = 42 },
+			wantErr:   middleware.ErrInvalidCompressLevel,
+		},
+		{
+			name:      "invalid zstd level",
+			configure: func(cfg *middleware.CompressConfig) { cfg.Levels["zstd"] = 23 },
+			wantErr:   middleware.ErrInvalidCompressLevel,
+		},
+		{
+			name:      "invalid brotli level",
+			configure: func(cfg *middleware.CompressConfig) { cfg.Levels["br"] = -1 },
+			wantErr:   middleware.ErrInvalidCompressLevel,
+		},
+		{
+			name:      "negative min size",
+			configure: func(cfg *middleware.CompressConfig) { cfg.MinSize = -1 },
+			wantErr:   middleware.ErrInvalidMinSize,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &middleware.CompressConfig{}
+			cfg.SetDefaults()
+			tt.configure(cfg)
+
+			require.ErrorIs(t, cfg.Validate(), tt.wantErr)
+		})
+	}
+}
+
+// decompress returns a reader decoding the body of the content coding.
+func decompress(t *testing.T, encoding string, body io.Reader) io.Reader {
+	t.Helper()
+
+	switch encoding {
+	case "zstd":
+		decoder, err := zstd.NewReader(body)
+		require.NoError(t, err)
+		t.Cleanup(decoder.Close)
+
+		return decoder
+	case "br":
+		return brotli.NewReader(body)
+	case "gzip":
+		decoder, err := gzip.NewReader(body)
+		require.NoError(t, err)
+
+		return decoder
+	default:
+		return flate.NewReader(body)
+	}
+}
+
+// hijackRecorder is a recorder for tests whose connection can be hijacked.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+
+	hijacked bool
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.hijacked = true
+
+	return nil, nil, nil
+}
+
+// upperEncoder is a codec for tests that upper-cases the data instead of compressing it.
+type upperEncoder struct {
+	writer io.Writer
+}
+
+func (e *upperEncoder) Close() error { return nil }
+func (e *upperEncoder) Flush() error { return nil }
+
+func (e *upperEncoder) Reset(w io.Writer) { e.writer = w }
+
+func (e *upperEncoder) Write(data []byte) (int, error) {
+	return e.writer.Write(bytes.ToUpper(data))
+}
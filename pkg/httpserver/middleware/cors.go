@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"slices"
 	"strconv"
@@ -18,12 +19,23 @@ var (
 	ErrMissingAllowedOrigins = errors.New("CORS: allowed origins cannot be empty")
 	ErrMissingAllowedMethods = errors.New("CORS: allowed methods cannot be empty")
 	ErrInvalidMaxAge         = errors.New("CORS: max age must be non-negative")
+	ErrWildcardCredentials   = errors.New("CORS: credentials cannot be allowed for the wildcard origin")
+	ErrInvalidOriginPattern  = errors.New(
+		`CORS: origin patterns must have a single wildcard subdomain, e.g. "https://*.example.com"`,
+	)
 )
 
 // CORSConfig holds the configuration for CORS middleware.
 type CORSConfig struct {
+	// AllowOriginFunc is called with the origins matching none of AllowedOrigins and reports whether a
+	// cross-domain request can be executed from them, e.g. to look them up in a database.
+	// Default: nil
+	AllowOriginFunc func(origin string) bool `json:"-" yaml:"-"`
+
 	// AllowedOrigins is a list of origins a cross-domain request can be executed from.
-	// If the special "*" value is present, all origins will be allowed.
+	// If the special "*" value is present, all origins will be allowed. Origins may start with a wildcard
+	// subdomain, e.g. "https://*.example.com" allows all subdomains of example.com at any depth over HTTPS,
+	// but not example.com itself. Origins allowed by a pattern or AllowOriginFunc are echoed.
 	// Default: ["*"]
 	AllowedOrigins []string `json:"allowedOrigins" yaml:"allowedOrigins"`
 
@@ -44,8 +56,14 @@ type CORSConfig struct {
 	MaxAge int `json:"maxAge" yaml:"maxAge"`
 
 	// AllowCredentials indicates whether the request can include user credentials.
+	// It cannot be combined with the "*" origin, since browsers reject credentials for it.
 	// Default: false
 	AllowCredentials bool `json:"allowCredentials" yaml:"allowCredentials"`
+
+	// AllowPrivateNetwork indicates whether public websites of allowed origins can send requests to the server
+	// in a private network, answering the preflights of Private Network Access.
+	// Default: false
+	AllowPrivateNetwork bool `json:"allowPrivateNetwork" yaml:"allowPrivateNetwork"`
 }
 
 func (c *CORSConfig) SetDefaults() {
@@ -64,13 +82,21 @@ func (c *CORSConfig) SetDefaults() {
 	c.ExposedHeaders = []string{}
 	c.MaxAge = 0
 	c.AllowCredentials = false
+	c.AllowPrivateNetwork = false
+	c.AllowOriginFunc = nil
 }
 
 func (c *CORSConfig) Validate() error {
-	if len(c.AllowedOrigins) == 0 {
+	if len(c.AllowedOrigins) == 0 && c.AllowOriginFunc == nil {
 		return ErrMissingAllowedOrigins
 	}
 
+	for _, origin := range c.AllowedOrigins {
+		if _, err := parseOriginPattern(origin); err != nil {
+			return err
+		}
+	}
+
 	if len(c.AllowedMethods) == 0 {
 		return ErrMissingAllowedMethods
 	}
@@ -79,17 +105,25 @@ func (c *CORSConfig) Validate() error {
 		return ErrInvalidMaxAge
 	}
 
+	if c.AllowCredentials && slices.Contains(c.AllowedOrigins, "*") {
+		return ErrWildcardCredentials
+	}
+
 	return nil
 }
 
 // CORS returns a middleware that enables Cross-Origin Resource Sharing (CORS).
+// It answers preflight requests, i.e. OPTIONS requests with an Origin header; other OPTIONS requests
+// are passed on, so the Router answers them with the allowed methods of the path. Responses vary by Origin,
+// and preflight responses by the requested method and headers as well, so caches never serve the response of
+// one origin to another.
 func CORS(cfg *CORSConfig) httpserver.Middleware {
 	if cfg == nil {
 		cfg = &CORSConfig{}
 		cfg.SetDefaults()
 	}
 
-	allowAllOrigins := containsWildcard(cfg.AllowedOrigins)
+	origins := newOriginMatcher(cfg)
 
 	// Pre-build header values.
 	allowMethods := strings.Join(cfg.AllowedMethods, ", ")
@@ -104,12 +138,13 @@ func CORS(cfg *CORSConfig) httpserver.Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
 			origin := req.Header.Get("Origin")
-			allowOrigin := getAllowedOrigin(origin, cfg.AllowedOrigins, allowAllOrigins)
+			allowOrigin := origins.allowed(origin)
 
+			resp.Header().Add("Vary", "Origin")
 			setCORSHeaders(resp, allowOrigin, cfg.AllowCredentials, exposeHeaders)
 
-			if req.Method == http.MethodOptions {
-				handlePreflightRequest(resp, allowOrigin, allowMethods, allowHeaders, maxAge)
+			if req.Method == http.MethodOptions && origin != "" {
+				handlePreflightRequest(resp, req, allowOrigin, allowMethods, allowHeaders, maxAge, cfg.AllowPrivateNetwork)
 
 				return
 			}
@@ -119,12 +154,46 @@ func CORS(cfg *CORSConfig) httpserver.Middleware {
 	}
 }
 
-func containsWildcard(origins []string) bool {
-	return slices.Contains(origins, "*")
+// originMatcher decides which origins are allowed by a CORSConfig.
+type originMatcher struct {
+	allowFunc func(origin string) bool
+	exact     []string
+	patterns  []originPattern
+	allowAll  bool
+}
+
+// originPattern matches the origins of the subdomains of a host, e.g. "https://*.example.com".
+type originPattern struct {
+	// prefix is the scheme including "://".
+	prefix string
+
+	// suffix is the host starting with "." and the optional port.
+	suffix string
+}
+
+func newOriginMatcher(cfg *CORSConfig) *originMatcher {
+	obj := &originMatcher{allowFunc: cfg.AllowOriginFunc, allowAll: slices.Contains(cfg.AllowedOrigins, "*")}
+
+	for _, origin := range cfg.AllowedOrigins {
+		pattern, err := parseOriginPattern(origin)
+		if err != nil || origin == "*" {
+			continue
+		}
+
+		if pattern != nil {
+			obj.patterns = append(obj.patterns, *pattern)
+		} else {
+			obj.exact = append(obj.exact, origin)
+		}
+	}
+
+	return obj
 }
 
-func getAllowedOrigin(origin string, allowedOrigins []string, allowAll bool) string {
-	if allowAll {
+// allowed returns the value of the Access-Control-Allow-Origin header for the origin, or an empty string if
+// the origin is not allowed.
+func (m *originMatcher) allowed(origin string) string {
+	if m.allowAll {
 		return "*"
 	}
 
@@ -132,13 +201,52 @@ func getAllowedOrigin(origin string, allowedOrigins []string, allowAll bool) str
 		return ""
 	}
 
-	if slices.Contains(allowedOrigins, origin) {
+	if slices.Contains(m.exact, origin) || slices.ContainsFunc(m.patterns, func(pattern originPattern) bool {
+		return pattern.match(origin)
+	}) {
+		return origin
+	}
+
+	if m.allowFunc != nil && m.allowFunc(origin) {
 		return origin
 	}
 
 	return ""
 }
 
+// match reports whether the origin is the one of a subdomain of the pattern.
+func (p originPattern) match(origin string) bool {
+	rest, found := strings.CutPrefix(origin, p.prefix)
+	if !found {
+		return false
+	}
+
+	subdomain, found := strings.CutSuffix(rest, p.suffix)
+	if !found || subdomain == "" || strings.HasPrefix(subdomain, ".") || strings.HasSuffix(subdomain, ".") {
+		return false
+	}
+
+	return !strings.ContainsFunc(subdomain, func(char rune) bool {
+		return (char < 'a' || char > 'z') && (char < '0' || char > '9') && char != '-' && char != '.'
+	})
+}
+
+// parseOriginPattern returns the pattern of an origin with wildcard subdomain, or nil for other origins.
+func parseOriginPattern(origin string) (*originPattern, error) {
+	if origin == "*" || !strings.Contains(origin, "*") {
+		return nil, nil //nolint:nilnil // Origins without wildcard are no patterns, which is no error.
+	}
+
+	scheme, host, found := strings.Cut(origin, "://")
+	suffix, isSubdomain := strings.CutPrefix(host, "*.")
+
+	if !found || scheme == "" || !isSubdomain || suffix == "" || strings.ContainsAny(suffix, "*/") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidOriginPattern, origin)
+	}
+
+	return &originPattern{prefix: scheme + "://", suffix: "." + suffix}, nil
+}
+
 func setCORSHeaders(
 	resp http.ResponseWriter,
 	allowOrigin string,
@@ -162,8 +270,17 @@ func setCORSHeaders(
 
 func handlePreflightRequest(
 	resp http.ResponseWriter,
+	req *http.Request,
 	allowOrigin, allowMethods, allowHeaders, maxAge string,
+	allowPrivateNetwork bool,
 ) {
+	resp.Header().Add("Vary", "Access-Control-Request-Method")
+	resp.Header().Add("Vary", "Access-Control-Request-Headers")
+
+	if allowPrivateNetwork {
+		resp.Header().Add("Vary", "Access-Control-Request-Private-Network")
+	}
+
 	if allowOrigin != "" {
 		resp.Header().Set("Access-Control-Allow-Methods", allowMethods)
 
@@ -174,6 +291,10 @@ func handlePreflightRequest(
 		if maxAge != "" {
 			resp.Header().Set("Access-Control-Max-Age", maxAge)
 		}
+
+		if allowPrivateNetwork && req.Header.Get("Access-Control-Request-Private-Network") == "true" {
+			resp.Header().Set("Access-Control-Allow-Private-Network", "true")
+		}
 	}
 
 	resp.WriteHeader(http.StatusNoContent)
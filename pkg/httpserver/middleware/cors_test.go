@@ -8,6 +8,7 @@ import (
 
 	"github.com/spacecafe/go-parts/pkg/httpserver/middleware"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCORS(t *testing.T) {
@@ -87,6 +88,17 @@ func TestCORS(t *testing.T) {
 			expectedStatusCode: http.StatusNoContent,
 			expectedHeaders:    map[string]string{}, // No CORS headers expected
 		},
+		{
+			name: "OPTIONS request without origin is passed on",
+			cfg: &middleware.CORSConfig{
+				AllowedOrigins: []string{"https://example.com"},
+				AllowedMethods: []string{http.MethodGet, http.MethodPost},
+			},
+			requestOrigin:      "",
+			requestMethod:      http.MethodOptions,
+			expectedStatusCode: http.StatusOK,
+			expectedHeaders:    map[string]string{},
+		},
 		{
 			name: "credentials support enabled",
 			cfg: &middleware.CORSConfig{
@@ -102,6 +114,80 @@ func TestCORS(t *testing.T) {
 				"Access-Control-Allow-Credentials": "true",
 			},
 		},
+		{
+			name: "wildcard subdomain origin",
+			cfg: &middleware.CORSConfig{
+				AllowedOrigins: []string{"https://example.com", "https://*.preview.example.com"},
+				AllowedMethods: []string{http.MethodGet},
+			},
+			requestOrigin:      "https://pr-42.eu.preview.example.com",
+			requestMethod:      http.MethodGet,
+			expectedStatusCode: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "https://pr-42.eu.preview.example.com",
+			},
+		},
+		{
+			name: "wildcard subdomain origin does not match apex",
+			cfg: &middleware.CORSConfig{
+				AllowedOrigins: []string{"https://*.example.com"},
+				AllowedMethods: []string{http.MethodGet},
+			},
+			requestOrigin:      "https://example.com",
+			requestMethod:      http.MethodGet,
+			expectedStatusCode: http.StatusOK,
+			expectedHeaders:    map[string]string{},
+		},
+		{
+			name: "wildcard subdomain origin does not match other scheme",
+			cfg: &middleware.CORSConfig{
+				AllowedOrigins: []string{"https://*.example.com"},
+				AllowedMethods: []string{http.MethodGet},
+			},
+			requestOrigin:      "http://app.example.com",
+			requestMethod:      http.MethodGet,
+			expectedStatusCode: http.StatusOK,
+			expectedHeaders:    map[string]string{},
+		},
+		{
+			name: "wildcard subdomain origin does not match lookalike host",
+			cfg: &middleware.CORSConfig{
+				AllowedOrigins: []string{"https://*.example.com"},
+				AllowedMethods: []string{http.MethodGet},
+			},
+			requestOrigin:      "https://evil.com#.example.com",
+			requestMethod:      http.MethodGet,
+			expectedStatusCode: http.StatusOK,
+			expectedHeaders:    map[string]string{},
+		},
+		{
+			name: "origin allowed by callback",
+			cfg: &middleware.CORSConfig{
+				AllowedOrigins: []string{"https://example.com"},
+				AllowedMethods: []string{http.MethodGet, http.MethodPost},
+				AllowOriginFunc: func(origin string) bool {
+					return strings.HasSuffix(origin, ".partner.test")
+				},
+			},
+			requestOrigin:      "https://shop.partner.test",
+			requestMethod:      http.MethodOptions,
+			expectedStatusCode: http.StatusNoContent,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://shop.partner.test",
+				"Access-Control-Allow-Methods": "GET, POST",
+			},
+		},
+		{
+			name: "origin rejected by callback",
+			cfg: &middleware.CORSConfig{
+				AllowedMethods:  []string{http.MethodGet},
+				AllowOriginFunc: func(string) bool { return false },
+			},
+			requestOrigin:      "https://shop.partner.test",
+			requestMethod:      http.MethodGet,
+			expectedStatusCode: http.StatusOK,
+			expectedHeaders:    map[string]string{},
+		},
 		{
 			name: "no origin header in request",
 			cfg: &middleware.CORSConfig{
@@ -161,3 +247,161 @@ func TestCORS(t *testing.T) {
 		})
 	}
 }
+
+func TestCORS_Vary(t *testing.T) {
+	t.Parallel()
+
+	cfg := &middleware.CORSConfig{}
+	cfg.SetDefaults()
+	cfg.AllowedOrigins = []string{"https://example.com"}
+	cfg.AllowPrivateNetwork = true
+
+	handler := middleware.CORS(cfg)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+	}))
+
+	tests := []struct {
+		header                  http.Header
+		name                    string
+		method                  string
+		wantAllowPrivateNetwork string
+		wantVary                []string
+	}{
+		{
+			name:     "request without origin",
+			method:   http.MethodGet,
+			header:   http.Header{},
+			wantVary: []string{"Origin", "Accept-Encoding"},
+		},
+		{
+			name:     "request of other origin",
+			method:   http.MethodGet,
+			header:   http.Header{"Origin": {"https://other.example"}},
+			wantVary: []string{"Origin", "Accept-Encoding"},
+		},
+		{
+			name:   "preflight",
+			method: http.MethodOptions,
+			header: http.Header{"Origin": {"https://example.com"}, "Access-Control-Request-Method": {"POST"}},
+			wantVary: []string{
+				"Origin",
+				"Access-Control-Request-Method",
+				"Access-Control-Request-Headers",
+				"Access-Control-Request-Private-Network",
+			},
+		},
+		{
+			name:   "private network preflight",
+			method: http.MethodOptions,
+			header: http.Header{
+				"Origin":                                 {"https://example.com"},
+				"Access-Control-Request-Private-Network": {"true"},
+			},
+			wantAllowPrivateNetwork: "true",
+			wantVary: []string{
+				"Origin",
+				"Access-Control-Request-Method",
+				"Access-Control-Request-Headers",
+				"Access-Control-Request-Private-Network",
+			},
+		},
+		{
+			name:   "private network preflight of other origin",
+			method: http.MethodOptions,
+			header: http.Header{
+				"Origin":                                 {"https://other.example"},
+				"Access-Control-Request-Private-Network": {"true"},
+			},
+			wantVary: []string{
+				"Origin",
+				"Access-Control-Request-Method",
+				"Access-Control-Request-Headers",
+				"Access-Control-Request-Private-Network",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tt.method, "/", http.NoBody)
+			req.Header = tt.header
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantVary, rec.Header().Values("Vary"))
+			assert.Equal(t, tt.wantAllowPrivateNetwork, rec.Header().Get("Access-Control-Allow-Private-Network"))
+		})
+	}
+}
+
+func TestCORSConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		configure func(cfg *middleware.CORSConfig)
+		wantErr   error
+		name      string
+	}{
+		{name: "defaults", configure: func(*middleware.CORSConfig) {}},
+		{
+			name: "origin patterns",
+			configure: func(cfg *middleware.CORSConfig) {
+				cfg.AllowedOrigins = []string{"https://*.example.com", "http://*.localhost:3000"}
+			},
+		},
+		{
+			name: "callback only",
+			configure: func(cfg *middleware.CORSConfig) {
+				cfg.AllowedOrigins = nil
+				cfg.AllowOriginFunc = func(string) bool { return true }
+			},
+		},
+		{
+			name:      "wildcard origin with credentials",
+			configure: func(cfg *middleware.CORSConfig) { cfg.AllowCredentials = true },
+			wantErr:   middleware.ErrWildcardCredentials,
+		},
+		{
+			name: "origin pattern with credentials",
+			configure: func(cfg *middleware.CORSConfig) {
+				cfg.AllowedOrigins = []string{"https://*.example.com"}
+				cfg.AllowCredentials = true
+			},
+		},
+		{
+			name:      "missing origins",
+			configure: func(cfg *middleware.CORSConfig) { cfg.AllowedOrigins = nil },
+			wantErr:   middleware.ErrMissingAllowedOrigins,
+		},
+		{
+			name:      "wildcard inside host",
+			configure: func(cfg *middleware.CORSConfig) { cfg.AllowedOrigins = []string{"https://app-*.example.com"} },
+			wantErr:   middleware.ErrInvalidOriginPattern,
+		},
+		{
+			name:      "wildcard without scheme",
+			configure: func(cfg *middleware.CORSConfig) { cfg.AllowedOrigins = []string{"*.example.com"} },
+			wantErr:   middleware.ErrInvalidOriginPattern,
+		},
+		{
+			name:      "several wildcards",
+			configure: func(cfg *middleware.CORSConfig) { cfg.AllowedOrigins = []string{"https://*.*.example.com"} },
+			wantErr:   middleware.ErrInvalidOriginPattern,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &middleware.CORSConfig{}
+			cfg.SetDefaults()
+			tt.configure(cfg)
+
+			require.ErrorIs(t, cfg.Validate(), tt.wantErr)
+		})
+	}
+}
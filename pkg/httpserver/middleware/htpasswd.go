@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"  //nolint:gosec // APR1 hashes of htpasswd files are based on MD5.
+	"crypto/sha1" //nolint:gosec // SHA hashes of htpasswd files are based on SHA-1.
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/log"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// DefaultHtpasswdInterval is the interval the htpasswd file of a BasicAuthConfig is checked for changes at.
+	DefaultHtpasswdInterval = 5 * time.Second
+
+	apr1Prefix = "$apr1$"
+	shaPrefix  = "{SHA}"
+
+	// apr1Alphabet encodes APR1 hashes, like crypt(3).
+	apr1Alphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+var ErrInvalidHtpasswdFile = errors.New("basic-auth: htpasswd file must be readable and contain user:hash lines")
+
+// htpasswdFile holds the credentials of an htpasswd file, which are reloaded when the file changes.
+type htpasswdFile struct {
+	// entries maps the users to their password hash. It is replaced as a whole on reload.
+	entries atomic.Pointer[map[string]string]
+
+	// modTime and size identify the version of the file loaded, guarded by mutex.
+	modTime time.Time
+
+	path string
+
+	size     int64
+	interval time.Duration
+
+	// checked is the time of the last check for changes in Unix nanoseconds.
+	checked atomic.Int64
+
+	// mutex serializes reloads.
+	mutex sync.Mutex
+}
+
+func newHtpasswdFile(path string, interval time.Duration) *htpasswdFile {
+	if interval <= 0 {
+		interval = DefaultHtpasswdInterval
+	}
+
+	obj := &htpasswdFile{path: path, interval: interval}
+	obj.entries.Store(&map[string]string{})
+
+	return obj
+}
+
+// authenticate reports whether the password matches the hash of the user.
+func (f *htpasswdFile) authenticate(username, password string) bool {
+	hash, found := (*f.entries.Load())[username]
+
+	return found && verifyHtpasswd(hash, password)
+}
+
+// refresh reloads the file if it changed and the interval passed since the last check. Failures keep the
+// credentials loaded before and are logged with the logger of the context.
+func (f *htpasswdFile) refresh(ctx context.Context) {
+	now := time.Now()
+	if now.UnixNano()-f.checked.Load() < int64(f.interval) || !f.mutex.TryLock() {
+		return
+	}
+	defer f.mutex.Unlock()
+
+	f.checked.Store(now.UnixNano())
+
+	info, err := os.Stat(f.path)
+	if err == nil && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return
+	}
+
+	var entries map[string]string
+	if err == nil {
+		entries, err = readHtpasswd(f.path)
+	}
+
+	if err != nil {
+		log.From(ctx).Warn("failed to reload htpasswd file", "path", f.path, "error", err)
+
+		return
+	}
+
+	f.entries.Store(&entries)
+	f.modTime, f.size = info.ModTime(), info.Size()
+}
+
+// readHtpasswd reads the user:hash lines of an htpasswd file, skipping empty lines and comments.
+func readHtpasswd(path string) (map[string]string, error) {
+	file, err := os.Open(path) //nolint:gosec // The path is part of the configuration.
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHtpasswdFile, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	entries := map[string]string{}
+	scanner := bufio.NewScanner(file)
+
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		username, hash, found := strings.Cut(line, ":")
+		if !found || username == "" || hash == "" {
+			return nil, fmt.Errorf("%w: malformed line %d", ErrInvalidHtpasswdFile, number)
+		}
+
+		entries[username] = hash
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHtpasswdFile, err)
+	}
+
+	return entries, nil
+}
+
+// verifyHtpasswd reports whether the password matches a bcrypt, APR1 or SHA hash of an htpasswd file.
+// Other hashes, e.g. of crypt(3), never match.
+func verifyHtpasswd(hash, password string) bool {
+	switch {
+	case strings.HasPrefix(hash, apr1Prefix):
+		salt, _, _ := strings.Cut(strings.TrimPrefix(hash, apr1Prefix), "$")
+
+		return subtle.ConstantTimeCompare([]byte(apr1(password, salt)), []byte(hash)) == 1
+	case strings.HasPrefix(hash, shaPrefix):
+		sum := sha1.Sum([]byte(password)) //nolint:gosec // SHA hashes of htpasswd files are based on SHA-1.
+		encoded := shaPrefix + base64.StdEncoding.EncodeToString(sum[:])
+
+		return subtle.ConstantTimeCompare([]byte(encoded), []byte(hash)) == 1
+	case slices.ContainsFunc(BcryptHashPrefixes, func(prefix string) bool { return strings.HasPrefix(hash, prefix) }):
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	default:
+		return false
+	}
+}
+
+// apr1 returns the APR1 hash of the password with the salt as created by htpasswd -m.
+func apr1(password, salt string) string {
+	pw, saltBytes := []byte(password), []byte(salt[:min(len(salt), 8)])
+
+	alternate := md5.Sum(append(append(append([]byte{}, pw...), saltBytes...), pw...)) //nolint:gosec // See apr1.
+
+	digest := md5.New() //nolint:gosec // See apr1.
+	digest.Write(pw)
+	digest.Write([]byte(apr1Prefix))
+	digest.Write(saltBytes)
+
+	for i := len(pw); i > 0; i -= 16 {
+		digest.Write(alternate[:min(i, 16)])
+	}
+
+	for i := len(pw); i > 0; i >>= 1 {
+		if i&1 == 1 {
+			digest.Write([]byte{0})
+		} else {
+			digest.Write(pw[:1])
+		}
+	}
+
+	final := digest.Sum(nil)
+
+	for i := range 1000 {
+		round := md5.New() //nolint:gosec // See apr1.
+
+		if i&1 == 1 {
+			round.Write(pw)
+		} else {
+			round.Write(final)
+		}
+
+		if i%3 != 0 {
+			round.Write(saltBytes)
+		}
+
+		if i%7 != 0 {
+			round.Write(pw)
+		}
+
+		if i&1 == 1 {
+			round.Write(final)
+		} else {
+			round.Write(pw)
+		}
+
+		final = round.Sum(nil)
+	}
+
+	var encoded strings.Builder
+
+	for _, group := range [][3]int{{0, 6, 12}, {1, 7, 13}, {2, 8, 14}, {3, 9, 15}, {4, 10, 5}} {
+		value := uint(final[group[0]])<<16 | uint(final[group[1]])<<8 | uint(final[group[2]])
+		for range 4 {
+			encoded.WriteByte(apr1Alphabet[value&0x3f])
+			value >>= 6
+		}
+	}
+
+	value := uint(final[11])
+	for range 2 {
+		encoded.WriteByte(apr1Alphabet[value&0x3f])
+		value >>= 6
+	}
+
+	return apr1Prefix + string(saltBytes) + "$" + encoded.String()
+}
@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"log/slog"
 	"net/http"
 
@@ -8,7 +11,41 @@ import (
 	"github.com/spacecafe/go-parts/pkg/log"
 )
 
-// Logger provides an HTTP middleware that logs incoming requests using the specified logger.
+const (
+	// RequestIDHeader is the header used to receive and return the request ID.
+	RequestIDHeader = "X-Request-ID"
+
+	// maxRequestIDLength limits the length of request IDs accepted from clients.
+	maxRequestIDLength = 128
+)
+
+var _ log.Logger = (*requestLogger)(nil)
+
+// requestStateKey is the context key of the per-request logging state.
+type requestStateKey struct{}
+
+// principalKey is the context key of the principal of requests that did not pass through Logger.
+type principalKey struct{}
+
+// requestState carries attributes that become known while the request travels down the chain.
+type requestState struct {
+	request   *http.Request
+	principal string
+	requestID string
+}
+
+// requestLogger adds the attributes of the request state to every record. They are resolved on every call
+// rather than when the logger is created, because they become known only further down the chain.
+type requestLogger struct {
+	parent log.Logger
+	state  *requestState
+}
+
+// Logger provides an HTTP middleware that derives a child logger for every request, stores it in the
+// request context via log.Into, and logs the request with it once the handler returned.
+// The child logger carries the request ID, the matched route pattern and the authenticated principal,
+// so handler logs and the access log record share the same attributes.
+// AccessLog placed behind it logs the status, size and duration of the responses with these attributes as well.
 func Logger(logger log.Logger) httpserver.Middleware {
 	if logger == nil {
 		logger = slog.Default()
@@ -16,7 +53,17 @@ func Logger(logger log.Logger) httpserver.Middleware {
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
-			logger.Info(
+			state := &requestState{request: req, requestID: requestID(req)}
+			resp.Header().Set(RequestIDHeader, state.requestID)
+
+			child := &requestLogger{parent: log.With(logger, "request_id", state.requestID), state: state}
+
+			ctx := context.WithValue(log.Into(req.Context(), child), requestStateKey{}, state)
+			state.request = req.WithContext(ctx)
+
+			next.ServeHTTP(resp, state.request)
+
+			child.Info(
 				"received request",
 				"remote_addr", req.RemoteAddr,
 				"method", req.Method,
@@ -29,7 +76,95 @@ func Logger(logger log.Logger) httpserver.Middleware {
 				"user_agent", req.UserAgent(),
 				"referer", req.Referer(),
 			)
-			next.ServeHTTP(resp, req)
 		})
 	}
 }
+
+// RequestID returns the ID assigned to the request by the Logger middleware, or an empty string.
+func RequestID(ctx context.Context) string {
+	if state, ok := ctx.Value(requestStateKey{}).(*requestState); ok {
+		return state.requestID
+	}
+
+	return ""
+}
+
+// PrincipalFromContext returns the principal recorded by an authentication middleware, e.g. the username of
+// BasicAuth, and reports whether the request was authenticated.
+func PrincipalFromContext(ctx context.Context) (string, bool) {
+	if principal, ok := ctx.Value(principalKey{}).(string); ok {
+		return principal, true
+	}
+
+	if state, ok := ctx.Value(requestStateKey{}).(*requestState); ok && state.principal != "" {
+		return state.principal, true
+	}
+
+	return "", false
+}
+
+// SetPrincipal records the authenticated principal of the request for the child logger created by Logger.
+// It is a no-op if the request did not pass through the Logger middleware.
+func SetPrincipal(ctx context.Context, principal string) {
+	if state, ok := ctx.Value(requestStateKey{}).(*requestState); ok {
+		state.principal = principal
+	}
+}
+
+func (l *requestLogger) Debug(msg string, args ...any) {
+	l.parent.Debug(msg, l.args(args)...)
+}
+
+func (l *requestLogger) Error(msg string, args ...any) {
+	l.parent.Error(msg, l.args(args)...)
+}
+
+func (l *requestLogger) Info(msg string, args ...any) {
+	l.parent.Info(msg, l.args(args)...)
+}
+
+func (l *requestLogger) Warn(msg string, args ...any) {
+	l.parent.Warn(msg, l.args(args)...)
+}
+
+// args prepends the current request attributes. The route pattern is set by http.ServeMux
+// further down the chain, unless another middleware replaces the request in between.
+func (l *requestLogger) args(args []any) []any {
+	return append([]any{"route", l.state.request.Pattern, "principal", l.state.principal}, args...)
+}
+
+// withPrincipal records the principal for PrincipalFromContext and the child logger created by Logger.
+// The request is only replaced if it did not pass through Logger, which would lose the route pattern otherwise.
+func withPrincipal(req *http.Request, principal string) *http.Request {
+	if state, ok := req.Context().Value(requestStateKey{}).(*requestState); ok {
+		state.principal = principal
+
+		return req
+	}
+
+	return req.WithContext(context.WithValue(req.Context(), principalKey{}, principal))
+}
+
+// requestID returns the well-formed request ID supplied by the client or generates a new one.
+func requestID(req *http.Request) string {
+	id := req.Header.Get(RequestIDHeader)
+	if id != "" && len(id) <= maxRequestIDLength && isPrintableASCII(id) {
+		return id
+	}
+
+	var buf [16]byte
+
+	_, _ = rand.Read(buf[:])
+
+	return hex.EncodeToString(buf[:])
+}
+
+func isPrintableASCII(value string) bool {
+	for i := range len(value) {
+		if value[i] < ' ' || value[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,104 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/spacecafe/go-parts/pkg/httpserver/middleware"
+	"github.com/spacecafe/go-parts/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		requestID     string
+		wantRequestID string
+	}{
+		{name: "client request ID", requestID: "abc-123", wantRequestID: "abc-123"},
+		{name: "generated request ID", requestID: ""},
+		{name: "malformed request ID", requestID: "bad\x01id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			cfg := &middleware.BasicAuthConfig{}
+			cfg.SetDefaults()
+			cfg.Principals = map[string]string{"user": "pass"}
+
+			router := httpserver.NewRouter()
+			router.Use(middleware.Logger(slog.New(slog.NewJSONHandler(&buf, nil))), middleware.BasicAuth(cfg))
+			router.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, req *http.Request) {
+				log.From(req.Context()).Info("handler")
+				_, _ = w.Write([]byte(middleware.RequestID(req.Context())))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/items/1", http.NoBody)
+			req.SetBasicAuth("user", "pass")
+			req.Header.Set(middleware.RequestIDHeader, tt.requestID)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			requestID := rec.Header().Get(middleware.RequestIDHeader)
+			if tt.wantRequestID != "" {
+				assert.Equal(t, tt.wantRequestID, requestID)
+			} else {
+				assert.Len(t, requestID, 32)
+			}
+
+			assert.Equal(t, requestID, rec.Body.String())
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			require.Len(t, lines, 2)
+
+			for _, line := range lines {
+				var record map[string]any
+
+				require.NoError(t, json.Unmarshal([]byte(line), &record))
+				assert.Equal(t, requestID, record["request_id"])
+				assert.Equal(t, "GET /items/{id}", record["route"])
+				assert.Equal(t, "user", record["principal"])
+			}
+		})
+	}
+}
+
+func TestLogger_ChildLogger(t *testing.T) {
+	t.Parallel()
+
+	logger := &recordingLogger{}
+
+	handler := middleware.Logger(logger)(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		log.From(req.Context()).Warn("handler", "key", "value")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	require.Len(t, logger.args, 2)
+	assert.Equal(t, "request_id", logger.args[0][0])
+	assert.Equal(t, []any{"route", "", "principal", ""}, logger.args[0][2:6])
+	assert.Equal(t, []any{"key", "value"}, logger.args[0][6:])
+	assert.Equal(t, logger.args[0][:6], logger.args[1][:6])
+}
+
+type recordingLogger struct {
+	args [][]any
+}
+
+func (l *recordingLogger) Debug(_ string, args ...any) { l.args = append(l.args, args) }
+func (l *recordingLogger) Error(_ string, args ...any) { l.args = append(l.args, args) }
+func (l *recordingLogger) Info(_ string, args ...any)  { l.args = append(l.args, args) }
+func (l *recordingLogger) Warn(_ string, args ...any)  { l.args = append(l.args, args) }
@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+)
+
+var (
+	_ httpserver.MetricsCollector = (*routeMetrics)(nil)
+
+	// DefaultSizeBuckets are the upper bounds in bytes of the response size histogram of Metrics.
+	//
+	//nolint:gochecknoglobals // Read-only defaults of the histogram.
+	DefaultSizeBuckets = []float64{100, 1e3, 1e4, 1e5, 1e6, 1e7}
+)
+
+// routeKey identifies the series of requests of a route.
+type routeKey struct {
+	route  string
+	method string
+
+	// class is the status class of the responses, e.g. "2xx".
+	class string
+}
+
+// routeSeries holds the histograms of the requests of a route.
+type routeSeries struct {
+	durations *httpserver.Histogram
+	sizes     *httpserver.Histogram
+}
+
+// routeMetrics collects the metrics of the requests passing the Metrics middleware.
+type routeMetrics struct {
+	series   map[routeKey]*routeSeries
+	inFlight atomic.Int64
+	mutex    sync.Mutex
+}
+
+// Metrics returns a middleware that records the requests, their duration and response size by route pattern,
+// method and status class, and registers them with the registry. The requests in flight are recorded as well.
+// Labeling by the pattern of the Router instead of the path keeps the number of series bounded; requests
+// matching no route are recorded with an empty route, and methods other than those of net/http as "OTHER".
+// The pattern is only known if the middleware is placed before the Router dispatches the request and no
+// middleware in between replaces the request, as with Logger. It panics if registry is nil.
+func Metrics(registry *httpserver.Metrics) httpserver.Middleware {
+	if registry == nil {
+		panic("middleware: Metrics requires a registry")
+	}
+
+	metrics := &routeMetrics{series: map[routeKey]*routeSeries{}}
+	registry.Register(metrics)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			metrics.inFlight.Add(1)
+			defer metrics.inFlight.Add(-1)
+
+			start := time.Now()
+			writer := httpserver.NewResponseWriter(resp)
+
+			defer func() {
+				status := writer.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+
+				key := routeKey{route: req.Pattern, method: metricsMethod(req.Method), class: statusClass(status)}
+				metrics.observe(key, time.Since(start), writer.Size())
+			}()
+
+			next.ServeHTTP(writer, req)
+		})
+	}
+}
+
+// CollectMetrics writes the metrics of the routes ordered by route, method and status class.
+func (m *routeMetrics) CollectMetrics(w io.Writer) error {
+	var builder strings.Builder
+
+	httpserver.WriteMetricHeader(&builder, "httpserver_route_requests_in_flight", "gauge",
+		"Requests being handled by routes.")
+	fmt.Fprintf(&builder, "httpserver_route_requests_in_flight %d\n", m.inFlight.Load())
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	keys := slices.SortedFunc(maps.Keys(m.series), func(a, b routeKey) int {
+		return cmp.Or(cmp.Compare(a.route, b.route), cmp.Compare(a.method, b.method), cmp.Compare(a.class, b.class))
+	})
+
+	httpserver.WriteMetricHeader(&builder, "httpserver_route_requests_total", "counter",
+		"Requests by route, method and status class.")
+
+	for _, key := range keys {
+		fmt.Fprintf(&builder, "httpserver_route_requests_total{%s} %d\n", key.labels(), m.series[key].durations.Count())
+	}
+
+	httpserver.WriteMetricHeader(&builder, "httpserver_route_request_duration_seconds", "histogram",
+		"Duration of requests by route, method and status class.")
+
+	for _, key := range keys {
+		m.series[key].durations.Write(&builder, "httpserver_route_request_duration_seconds", key.labels())
+	}
+
+	httpserver.WriteMetricHeader(&builder, "httpserver_route_response_size_bytes", "histogram",
+		"Size of response bodies by route, method and status class.")
+
+	for _, key := range keys {
+		m.series[key].sizes.Write(&builder, "httpserver_route_response_size_bytes", key.labels())
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	if err != nil {
+		return fmt.Errorf("middleware: failed to write route metrics: %w", err)
+	}
+
+	return nil
+}
+
+// observe records a request of the series.
+func (m *routeMetrics) observe(key routeKey, duration time.Duration, size int64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	series, ok := m.series[key]
+	if !ok {
+		series = &routeSeries{
+			durations: httpserver.NewHistogram(httpserver.DefaultDurationBuckets),
+			sizes:     httpserver.NewHistogram(DefaultSizeBuckets),
+		}
+		m.series[key] = series
+	}
+
+	series.durations.Observe(duration.Seconds())
+	series.sizes.Observe(float64(size))
+}
+
+// labels returns the labels of the series in the Prometheus text exposition format.
+func (k routeKey) labels() string {
+	return fmt.Sprintf("route=%q,method=%q,class=%q", k.route, k.method, k.class)
+}
+
+// metricsMethod returns the method, or "OTHER" for methods not defined by net/http.
+func metricsMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+		http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
+// statusClass returns the class of the status code, e.g. "4xx".
+func statusClass(status int) string {
+	return strconv.Itoa(status/100) + "xx"
+}
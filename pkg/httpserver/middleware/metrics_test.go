@@ -0,0 +1,61 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/spacecafe/go-parts/pkg/httpserver/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	registry := httpserver.NewMetrics()
+
+	router := httpserver.NewRouter()
+	router.Use(middleware.Metrics(registry))
+	router.Get("/users/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("user"))
+	})
+	router.Post("/users", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/users/1", http.NoBody),
+		httptest.NewRequest(http.MethodGet, "/users/2", http.NoBody),
+		httptest.NewRequest(http.MethodPost, "/users", http.NoBody),
+		httptest.NewRequest("PURGE", "/users", http.NoBody),
+		httptest.NewRequest(http.MethodGet, "/missing", http.NoBody),
+	} {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	rec := httptest.NewRecorder()
+	registry.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, httpserver.MetricsPath, http.NoBody))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		"# TYPE httpserver_route_requests_in_flight gauge\nhttpserver_route_requests_in_flight 0\n",
+		`httpserver_route_requests_total{route="GET /users/{id}",method="GET",class="2xx"} 2`,
+		`httpserver_route_requests_total{route="POST /users",method="POST",class="4xx"} 1`,
+		`httpserver_route_requests_total{route="",method="OTHER",class="4xx"} 1`,
+		`httpserver_route_requests_total{route="",method="GET",class="4xx"} 1`,
+		`httpserver_route_request_duration_seconds_count{route="GET /users/{id}",method="GET",class="2xx"} 2`,
+		`httpserver_route_response_size_bytes_bucket{route="GET /users/{id}",method="GET",class="2xx",le="100"} 2`,
+		`httpserver_route_response_size_bytes_sum{route="GET /users/{id}",method="GET",class="2xx"} 8`,
+	} {
+		assert.Contains(t, body, want)
+	}
+
+	assert.NotContains(t, body, "/users/1")
+}
+
+func TestMetrics_NilRegistry(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() { middleware.Metrics(nil) })
+}
@@ -0,0 +1,694 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/spacecafe/go-parts/pkg/log"
+)
+
+const (
+	// oidcFlowCookie carries the state, nonce and PKCE verifier of a login from Login to Callback.
+	oidcFlowCookie = "oidc_flow"
+
+	// oidcFlowTTL limits the time a user has to sign in at the provider.
+	oidcFlowTTL = 10 * time.Minute
+
+	// oidcFlowPurpose and oidcSessionPurpose are signed with the cookies, so one cannot be replayed as the other.
+	oidcFlowPurpose    = "flow"
+	oidcSessionPurpose = "session"
+
+	oidcDiscoveryPath = "/.well-known/openid-configuration"
+
+	// minSessionSecretLength is the key size of HMAC-SHA256.
+	minSessionSecretLength = 32
+
+	// maxCookieSize is the size of a cookie, including its name and attributes, browsers must at least store.
+	maxCookieSize = 4096
+
+	// maxIntrospectionCache limits the number of introspection results cached by Introspect.
+	maxIntrospectionCache = 1024
+)
+
+var (
+	_ config.Defaultable = (*OIDCConfig)(nil)
+	_ config.Validatable = (*OIDCConfig)(nil)
+
+	ErrInvalidIssuerURL     = errors.New("OIDC: issuer URL must be an absolute URL")
+	ErrMissingClientID      = errors.New("OIDC: client ID cannot be empty")
+	ErrInvalidRedirectURL   = errors.New("OIDC: redirect URL must be an absolute URL")
+	ErrInvalidSessionSecret = errors.New("OIDC: session secret must be at least 32 bytes")
+	ErrInvalidSessionTTL    = errors.New("OIDC: session TTL must be positive")
+	ErrOIDCDiscovery        = errors.New("OIDC: provider discovery failed")
+	ErrOIDCExchange         = errors.New("OIDC: code exchange failed")
+	ErrOIDCProvider         = errors.New("OIDC: provider request failed")
+	ErrInvalidIDToken       = errors.New("OIDC: invalid ID token")
+	ErrMissingSubject       = errors.New("OIDC: ID token names no subject")
+	ErrInvalidOIDCCookie    = errors.New("OIDC: invalid cookie")
+	ErrOIDCSessionTooLarge  = errors.New("OIDC: session cookie exceeds 4096 bytes")
+	ErrMissingIntrospection = errors.New("OIDC: provider announces no introspection endpoint")
+)
+
+// oidcCookie is the content of a signed cookie, which is rejected after its expiry.
+type oidcCookie interface {
+	expiry() time.Time
+}
+
+// oidcIdentityKey is the context key of the identity of an authenticated request.
+type oidcIdentityKey struct{}
+
+// OIDCConfig holds the configuration for the OIDC middleware.
+type OIDCConfig struct {
+	// HTTPClient sends the requests to the provider.
+	// Default: http.DefaultClient
+	HTTPClient *http.Client `json:"-" yaml:"-"`
+
+	// IssuerURL is the issuer of the provider, whose configuration is discovered below
+	// /.well-known/openid-configuration.
+	IssuerURL string `json:"issuerUrl" yaml:"issuerUrl"`
+
+	// ClientID and ClientSecret are the credentials of the client, sent with client_secret_basic.
+	ClientID     string `json:"clientId"     yaml:"clientId"`
+	ClientSecret string `json:"clientSecret" yaml:"clientSecret"`
+
+	// RedirectURL is the absolute URL of the Callback handler registered at the provider.
+	// It is only required for the authorization code flow of browser routes.
+	RedirectURL string `json:"redirectUrl" yaml:"redirectUrl"`
+
+	// PostLogoutRedirectURL is where the provider, or Logout if the provider announces no end session endpoint,
+	// sends users after they signed out.
+	// Default: "/"
+	PostLogoutRedirectURL string `json:"postLogoutRedirectUrl" yaml:"postLogoutRedirectUrl"`
+
+	// LoginPath is the path of the Login handler, which Authenticate redirects unauthenticated users to.
+	// Default: "/auth/login"
+	LoginPath string `json:"loginPath" yaml:"loginPath"`
+
+	// CookieName is the name of the session cookie.
+	// Default: "oidc_session"
+	CookieName string `json:"cookieName" yaml:"cookieName"`
+
+	// SessionSecret signs the session and login cookies. It must be at least 32 bytes long if set, and is required
+	// if RedirectURL is set or Authenticate is used.
+	SessionSecret string `json:"sessionSecret" yaml:"sessionSecret"`
+
+	// Scopes are requested by the authorization code flow.
+	// Default: ["openid", "profile", "email"]
+	Scopes []string `json:"scopes" yaml:"scopes"`
+
+	// SessionClaims are the claims of the ID token kept in the session cookie besides the subject. Browsers drop
+	// cookies larger than 4096 bytes, so Callback fails logins whose session would exceed them, e.g. because of
+	// long group lists.
+	// Default: ["name", "email", "preferred_username"]
+	SessionClaims []string `json:"sessionClaims" yaml:"sessionClaims"`
+
+	// SessionTTL limits how long a session lasts after the login.
+	// Default: 8h
+	SessionTTL time.Duration `json:"sessionTtl" yaml:"sessionTtl"`
+
+	// InsecureCookies omits the Secure attribute of the cookies, e.g. for local development without TLS.
+	// Default: false
+	InsecureCookies bool `json:"insecureCookies" yaml:"insecureCookies"`
+}
+
+func (c *OIDCConfig) SetDefaults() {
+	c.HTTPClient = http.DefaultClient
+	c.PostLogoutRedirectURL = "/"
+	c.LoginPath = "/auth/login"
+	c.CookieName = "oidc_session"
+	c.Scopes = []string{"openid", "profile", "email"}
+	c.SessionClaims = []string{"name", "email", "preferred_username"}
+	c.SessionTTL = 8 * time.Hour
+	c.InsecureCookies = false
+}
+
+func (c *OIDCConfig) Validate() error {
+	if !isAbsoluteURL(c.IssuerURL) {
+		return ErrInvalidIssuerURL
+	}
+
+	if c.ClientID == "" {
+		return ErrMissingClientID
+	}
+
+	if c.SessionTTL <= 0 {
+		return ErrInvalidSessionTTL
+	}
+
+	if c.RedirectURL != "" && !isAbsoluteURL(c.RedirectURL) {
+		return ErrInvalidRedirectURL
+	}
+
+	if (c.RedirectURL != "" || c.SessionSecret != "") && len(c.SessionSecret) < minSessionSecretLength {
+		return ErrInvalidSessionSecret
+	}
+
+	return nil
+}
+
+// OIDCIdentity is the user an OIDC middleware authenticated a request for.
+type OIDCIdentity struct {
+	// Claims are the SessionClaims of the ID token for browser routes, or the introspection response for API
+	// routes.
+	Claims map[string]any
+
+	// Subject is the identifier of the user at the provider.
+	Subject string
+}
+
+// OIDC authenticates requests with an OpenID Connect provider: browser routes by the authorization code flow
+// with PKCE, see Login, Callback, Logout and Authenticate, and API routes by introspecting their bearer tokens,
+// see Introspect. The user is recorded as principal for the Logger middleware.
+type OIDC struct {
+	cfg *OIDCConfig
+
+	// introspections caches the active introspection results by the SHA-256 hash of their token until they expire.
+	introspections map[[sha256.Size]byte]oidcIntrospection
+	provider       oidcProvider
+	mutex          sync.Mutex
+}
+
+// oidcIntrospection is an active introspection result cached by Introspect.
+type oidcIntrospection struct {
+	expires time.Time
+	claims  map[string]any
+}
+
+// oidcProvider is the part of the provider configuration of OpenID Connect Discovery the middleware uses.
+//
+//nolint:tagliatelle // The field names are defined by OpenID Connect Discovery.
+type oidcProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	IntrospectionEndpoint string `json:"introspection_endpoint"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// oidcFlow is the login in progress stored in the flow cookie.
+//
+//nolint:tagliatelle // Compact names keep the cookie small.
+type oidcFlow struct {
+	Expires  time.Time `json:"exp"`
+	State    string    `json:"state"`
+	Nonce    string    `json:"nonce"`
+	Verifier string    `json:"verifier"`
+	ReturnTo string    `json:"return"`
+}
+
+// oidcSession is the signed-in user stored in the session cookie.
+//
+//nolint:tagliatelle // Compact names keep the cookie small.
+type oidcSession struct {
+	Expires time.Time      `json:"exp"`
+	Claims  map[string]any `json:"claims"`
+	Subject string         `json:"sub"`
+}
+
+func (f *oidcFlow) expiry() time.Time {
+	return f.Expires
+}
+
+func (s *oidcSession) expiry() time.Time {
+	return s.Expires
+}
+
+// NewOIDC discovers the provider configuration of the issuer. Without RedirectURL, only API routes can be
+// authenticated, so the provider must announce an introspection endpoint.
+func NewOIDC(ctx context.Context, cfg *OIDCConfig) (*OIDC, error) {
+	obj := &OIDC{cfg: cfg, introspections: map[[sha256.Size]byte]oidcIntrospection{}}
+
+	err := obj.fetch(ctx, http.MethodGet, strings.TrimSuffix(cfg.IssuerURL, "/")+oidcDiscoveryPath, nil, &obj.provider)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOIDCDiscovery, err)
+	}
+
+	if strings.TrimSuffix(obj.provider.Issuer, "/") != strings.TrimSuffix(cfg.IssuerURL, "/") {
+		return nil, fmt.Errorf("%w: provider announces issuer %q", ErrOIDCDiscovery, obj.provider.Issuer)
+	}
+
+	if cfg.RedirectURL == "" && obj.provider.IntrospectionEndpoint == "" {
+		return nil, fmt.Errorf("%w: %w", ErrOIDCDiscovery, ErrMissingIntrospection)
+	}
+
+	return obj, nil
+}
+
+// OIDCIdentityFromContext returns the identity of a request authenticated by an OIDC middleware.
+func OIDCIdentityFromContext(ctx context.Context) (*OIDCIdentity, bool) {
+	identity, ok := ctx.Value(oidcIdentityKey{}).(*OIDCIdentity)
+
+	return identity, ok
+}
+
+// Authenticate returns a middleware for browser routes that passes on requests with a valid session cookie.
+// Other GET and HEAD requests are redirected to LoginPath, returning to the requested URL after the login;
+// requests of other methods are answered with 401.
+// It panics if SessionSecret is shorter than 32 bytes, since sessions could be forged otherwise.
+func (o *OIDC) Authenticate() httpserver.Middleware {
+	if len(o.cfg.SessionSecret) < minSessionSecretLength {
+		panic(ErrInvalidSessionSecret)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			var session oidcSession
+
+			cookie, err := req.Cookie(o.cfg.CookieName)
+			if err == nil {
+				err = o.verifyCookie(oidcSessionPurpose, cookie.Value, &session)
+			}
+
+			if err == nil && session.Subject != "" {
+				next.ServeHTTP(resp, o.authenticated(req, session.Subject, session.Claims))
+
+				return
+			}
+
+			if req.Method != http.MethodGet && req.Method != http.MethodHead {
+				http.Error(resp, "Unauthorized", http.StatusUnauthorized)
+
+				return
+			}
+
+			target := o.cfg.LoginPath + "?" + url.Values{"return_to": {req.URL.RequestURI()}}.Encode()
+			http.Redirect(resp, req, target, http.StatusFound)
+		})
+	}
+}
+
+// Callback completes the login of Login when the provider redirects the user back to RedirectURL. It checks
+// the state, exchanges the code for the ID token and starts the session.
+func (o *OIDC) Callback(resp http.ResponseWriter, req *http.Request) {
+	var flow oidcFlow
+
+	cookie, err := req.Cookie(oidcFlowCookie)
+	if err == nil {
+		err = o.verifyCookie(oidcFlowPurpose, cookie.Value, &flow)
+	}
+
+	http.SetCookie(resp, o.cookie(oidcFlowCookie, "", -1))
+
+	query := req.URL.Query()
+
+	switch {
+	case err != nil:
+		http.Error(resp, "login expired", http.StatusBadRequest)
+
+		return
+	case query.Get("error") != "":
+		http.Error(resp, "login failed: "+query.Get("error"), http.StatusUnauthorized)
+
+		return
+	case subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(flow.State)) != 1:
+		http.Error(resp, "login state mismatch", http.StatusBadRequest)
+
+		return
+	}
+
+	claims, err := o.exchange(req.Context(), query.Get("code"), flow)
+	if err != nil {
+		http.Error(resp, "login failed", http.StatusUnauthorized)
+
+		return
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		log.From(req.Context()).Error("failed to start OIDC session", "error", ErrMissingSubject)
+		http.Error(resp, "login failed", http.StatusUnauthorized)
+
+		return
+	}
+
+	session := oidcSession{Subject: subject, Claims: map[string]any{}, Expires: time.Now().Add(o.cfg.SessionTTL)}
+
+	for _, name := range o.cfg.SessionClaims {
+		if value, found := claims[name]; found {
+			session.Claims[name] = value
+		}
+	}
+
+	cookie = o.cookie(o.cfg.CookieName, o.signCookie(oidcSessionPurpose, session), int(o.cfg.SessionTTL.Seconds()))
+	if len(cookie.String()) > maxCookieSize {
+		log.From(req.Context()).Error("failed to start OIDC session", "subject", subject, "error", ErrOIDCSessionTooLarge)
+		http.Error(resp, "login failed", http.StatusInternalServerError)
+
+		return
+	}
+
+	http.SetCookie(resp, cookie)
+	http.Redirect(resp, req, flow.ReturnTo, http.StatusFound)
+}
+
+// Introspect returns a middleware for API routes that passes on requests whose bearer token the introspection
+// endpoint of the provider reports as active. Other requests are answered with 401, and with 502 if the
+// provider cannot be asked. Active results are cached until the expiry they report, so the provider is not
+// asked on every request; tokens revoked in the meantime remain accepted until then.
+// It panics if the provider announces no introspection endpoint.
+func (o *OIDC) Introspect() httpserver.Middleware {
+	if o.provider.IntrospectionEndpoint == "" {
+		panic(ErrMissingIntrospection)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			token, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+			if !found || token == "" {
+				resp.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(resp, "Unauthorized", http.StatusUnauthorized)
+
+				return
+			}
+
+			claims, err := o.introspect(req.Context(), token)
+			if err != nil {
+				http.Error(resp, "token introspection failed", http.StatusBadGateway)
+
+				return
+			}
+
+			if active, _ := claims["active"].(bool); !active {
+				resp.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				http.Error(resp, "Unauthorized", http.StatusUnauthorized)
+
+				return
+			}
+
+			subject, _ := claims["sub"].(string)
+			next.ServeHTTP(resp, o.authenticated(req, subject, claims))
+		})
+	}
+}
+
+// Login starts the authorization code flow by redirecting the user to the provider. The query parameter
+// return_to names the local path the user returns to after the login.
+func (o *OIDC) Login(resp http.ResponseWriter, req *http.Request) {
+	flow := oidcFlow{
+		State:    randomToken(),
+		Nonce:    randomToken(),
+		Verifier: randomToken(),
+		ReturnTo: req.URL.Query().Get("return_to"),
+		Expires:  time.Now().Add(oidcFlowTTL),
+	}
+
+	// Only local paths are accepted, so the login cannot be abused as an open redirect.
+	if !strings.HasPrefix(flow.ReturnTo, "/") || strings.HasPrefix(flow.ReturnTo, "//") ||
+		strings.HasPrefix(flow.ReturnTo, "/\\") {
+		flow.ReturnTo = "/"
+	}
+
+	challenge := sha256.Sum256([]byte(flow.Verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {o.cfg.ClientID},
+		"redirect_uri":          {o.cfg.RedirectURL},
+		"scope":                 {strings.Join(o.cfg.Scopes, " ")},
+		"state":                 {flow.State},
+		"nonce":                 {flow.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	http.SetCookie(resp, o.cookie(oidcFlowCookie, o.signCookie(oidcFlowPurpose, flow), int(oidcFlowTTL.Seconds())))
+	http.Redirect(resp, req, appendQuery(o.provider.AuthorizationEndpoint, query), http.StatusFound)
+}
+
+// Logout ends the session and signs the user out at the provider if it announces an end session endpoint.
+func (o *OIDC) Logout(resp http.ResponseWriter, req *http.Request) {
+	http.SetCookie(resp, o.cookie(o.cfg.CookieName, "", -1))
+
+	if o.provider.EndSessionEndpoint == "" {
+		http.Redirect(resp, req, o.cfg.PostLogoutRedirectURL, http.StatusFound)
+
+		return
+	}
+
+	query := url.Values{"client_id": {o.cfg.ClientID}}
+	if isAbsoluteURL(o.cfg.PostLogoutRedirectURL) {
+		query.Set("post_logout_redirect_uri", o.cfg.PostLogoutRedirectURL)
+	}
+
+	http.Redirect(resp, req, appendQuery(o.provider.EndSessionEndpoint, query), http.StatusFound)
+}
+
+// authenticated returns the request carrying the identity and records its subject as principal.
+func (o *OIDC) authenticated(req *http.Request, subject string, claims map[string]any) *http.Request {
+	req = withPrincipal(req, subject)
+	identity := &OIDCIdentity{Subject: subject, Claims: claims}
+
+	return req.WithContext(context.WithValue(req.Context(), oidcIdentityKey{}, identity))
+}
+
+// cacheIntrospection caches the result, evicting expired ones if the cache is full. Results are not cached if
+// the cache remains full.
+func (o *OIDC) cacheIntrospection(key [sha256.Size]byte, result oidcIntrospection, now time.Time) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if len(o.introspections) >= maxIntrospectionCache {
+		maps.DeleteFunc(o.introspections, func(_ [sha256.Size]byte, cached oidcIntrospection) bool {
+			return !now.Before(cached.expires)
+		})
+	}
+
+	if len(o.introspections) < maxIntrospectionCache {
+		o.introspections[key] = result
+	}
+}
+
+// cookie returns a cookie of the middleware with the value, which is deleted by a negative max age.
+func (o *OIDC) cookie(name, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   !o.cfg.InsecureCookies,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// cookieMAC returns the HMAC-SHA256 of the purpose and payload of a cookie with the session secret.
+func (o *OIDC) cookieMAC(purpose, payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(o.cfg.SessionSecret))
+	mac.Write([]byte(purpose + "." + payload))
+
+	return mac.Sum(nil)
+}
+
+// exchange redeems the code at the token endpoint and returns the claims of the ID token.
+// The claims are checked, but not the signature of the token, which is received from the provider directly
+// over TLS as permitted by OpenID Connect Core 1.0, section 3.1.3.7.
+func (o *OIDC) exchange(ctx context.Context, code string, flow oidcFlow) (map[string]any, error) {
+	var tokens struct {
+		IDToken string `json:"id_token"` //nolint:tagliatelle // The field name is defined by OAuth 2.0.
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {o.cfg.RedirectURL},
+		"code_verifier": {flow.Verifier},
+	}
+
+	err := o.fetch(ctx, http.MethodPost, o.provider.TokenEndpoint, form, &tokens)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOIDCExchange, err)
+	}
+
+	parts := strings.Split(tokens.IDToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidIDToken)
+	}
+
+	var claims map[string]any
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err == nil {
+		err = json.Unmarshal(payload, &claims)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+
+	return claims, o.verifyClaims(claims, flow.Nonce)
+}
+
+// fetch sends the request to the provider, with the form and client credentials if form is set, and decodes
+// the JSON response into target.
+func (o *OIDC) fetch(ctx context.Context, method, endpoint string, form url.Values, target any) error {
+	var body io.Reader = http.NoBody
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(url.QueryEscape(o.cfg.ClientID), url.QueryEscape(o.cfg.ClientSecret))
+	}
+
+	client := o.cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s answered %s", ErrOIDCProvider, endpoint, resp.Status)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(target)
+	if err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
+
+// introspect returns the introspection result of the token, from the cache if it is still active. Every call
+// returns its own copy, so handlers changing the claims of one request do not affect others.
+func (o *OIDC) introspect(ctx context.Context, token string) (map[string]any, error) {
+	key := sha256.Sum256([]byte(token))
+	now := time.Now()
+
+	o.mutex.Lock()
+	cached, found := o.introspections[key]
+	o.mutex.Unlock()
+
+	if found && now.Before(cached.expires) {
+		return maps.Clone(cached.claims), nil
+	}
+
+	var claims map[string]any
+
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+
+	err := o.fetch(ctx, http.MethodPost, o.provider.IntrospectionEndpoint, form, &claims)
+	if err != nil {
+		return nil, err
+	}
+
+	active, _ := claims["active"].(bool)
+	expires, _ := claims["exp"].(float64)
+
+	if active && expires > 0 {
+		cached = oidcIntrospection{expires: time.Unix(int64(expires), 0), claims: maps.Clone(claims)}
+		o.cacheIntrospection(key, cached, now)
+	}
+
+	return claims, nil
+}
+
+// signCookie encodes the value as JSON signed with the session secret for the purpose.
+func (o *OIDC) signCookie(purpose string, value any) string {
+	data, _ := json.Marshal(value)
+	payload := base64.RawURLEncoding.EncodeToString(data)
+
+	return payload + "." + base64.RawURLEncoding.EncodeToString(o.cookieMAC(purpose, payload))
+}
+
+// verifyClaims checks the issuer, audience, expiry and nonce of the ID token.
+func (o *OIDC) verifyClaims(claims map[string]any, nonce string) error {
+	audience := []any{claims["aud"]}
+	if list, ok := claims["aud"].([]any); ok {
+		audience = list
+	}
+
+	expires, _ := claims["exp"].(float64)
+	tokenNonce, _ := claims["nonce"].(string)
+
+	switch {
+	case claims["iss"] != o.provider.Issuer:
+		return fmt.Errorf("%w: issuer mismatch", ErrInvalidIDToken)
+	case !slices.Contains(audience, any(o.cfg.ClientID)):
+		return fmt.Errorf("%w: audience mismatch", ErrInvalidIDToken)
+	case time.Unix(int64(expires), 0).Before(time.Now()):
+		return fmt.Errorf("%w: token expired", ErrInvalidIDToken)
+	case subtle.ConstantTimeCompare([]byte(tokenNonce), []byte(nonce)) != 1:
+		return fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	return nil
+}
+
+// verifyCookie checks the signature of a cookie of signCookie for the purpose, decodes it into target and checks
+// its expiry.
+func (o *OIDC) verifyCookie(purpose, raw string, target oidcCookie) error {
+	payload, signature, _ := strings.Cut(raw, ".")
+
+	decoded, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decoded, o.cookieMAC(purpose, payload)) {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidOIDCCookie)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err == nil {
+		err = json.Unmarshal(data, target)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOIDCCookie, err)
+	}
+
+	if target.expiry().Before(time.Now()) {
+		return fmt.Errorf("%w: expired", ErrInvalidOIDCCookie)
+	}
+
+	return nil
+}
+
+// appendQuery adds the query to the endpoint, keeping the parameters it has already.
+func appendQuery(endpoint string, query url.Values) string {
+	if strings.Contains(endpoint, "?") {
+		return endpoint + "&" + query.Encode()
+	}
+
+	return endpoint + "?" + query.Encode()
+}
+
+func isAbsoluteURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+
+	return err == nil && parsed.Scheme != "" && parsed.Host != ""
+}
+
+// randomToken returns 32 random bytes encoded for URLs, as required for PKCE verifiers.
+func randomToken() string {
+	var buf [32]byte
+
+	_, _ = rand.Read(buf[:])
+
+	return base64.RawURLEncoding.EncodeToString(buf[:])
+}
@@ -0,0 +1,616 @@
+package middleware_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDC_AuthorizationCodeFlow(t *testing.T) {
+	t.Parallel()
+
+	provider := newOIDCProvider(t)
+	provider.groups = []string{"admins", "developers"}
+	oidc := newOIDC(t, provider)
+
+	protected := oidc.Authenticate()(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		identity, ok := middleware.OIDCIdentityFromContext(req.Context())
+		require.True(t, ok)
+
+		assert.NotContains(t, identity.Claims, "groups", "only the session claims are kept")
+		_, _ = fmt.Fprint(w, identity.Subject, " ", identity.Claims["email"])
+	}))
+
+	// Unauthenticated users are sent to the login.
+	rec := httptest.NewRecorder()
+	protected.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/profile?tab=1", http.NoBody))
+	require.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "/auth/login?return_to=%2Fprofile%3Ftab%3D1", rec.Header().Get("Location"))
+
+	// The login redirects to the provider with state, nonce and PKCE challenge.
+	rec = httptest.NewRecorder()
+	oidc.Login(rec, httptest.NewRequest(http.MethodGet, "/auth/login?return_to=%2Fprofile%3Ftab%3D1", http.NoBody))
+	require.Equal(t, http.StatusFound, rec.Code)
+
+	authorize, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, provider.server.URL+"/authorize", authorize.Scheme+"://"+authorize.Host+authorize.Path)
+	assert.Equal(t, "code", authorize.Query().Get("response_type"))
+	assert.Equal(t, "client", authorize.Query().Get("client_id"))
+	assert.Equal(t, "openid profile email", authorize.Query().Get("scope"))
+	assert.Equal(t, "S256", authorize.Query().Get("code_challenge_method"))
+
+	provider.authorize(authorize.Query())
+	flowCookie := findCookie(t, rec, "oidc_flow")
+
+	// The provider redirects back with the code, which is exchanged for the session.
+	req := httptest.NewRequest(http.MethodGet, "/auth/callback?code=secret-code&state="+authorize.Query().Get("state"),
+		http.NoBody)
+	req.AddCookie(flowCookie)
+
+	rec = httptest.NewRecorder()
+	oidc.Callback(rec, req)
+	require.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "/profile?tab=1", rec.Header().Get("Location"))
+
+	sessionCookie := findCookie(t, rec, "oidc_session")
+	assert.True(t, sessionCookie.HttpOnly)
+	assert.True(t, sessionCookie.Secure)
+
+	req = httptest.NewRequest(http.MethodGet, "/profile", http.NoBody)
+	req.AddCookie(sessionCookie)
+
+	rec = httptest.NewRecorder()
+	protected.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "user-1 user@example.com", rec.Body.String())
+
+	// A tampered session is rejected.
+	req = httptest.NewRequest(http.MethodPost, "/profile", http.NoBody)
+	req.AddCookie(&http.Cookie{Name: "oidc_session", Value: "e30." + strings.Split(sessionCookie.Value, ".")[1]})
+
+	rec = httptest.NewRecorder()
+	protected.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// The logout ends the session at the provider.
+	rec = httptest.NewRecorder()
+	oidc.Logout(rec, httptest.NewRequest(http.MethodGet, "/auth/logout", http.NoBody))
+	require.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, provider.server.URL+"/logout?client_id=client", rec.Header().Get("Location"))
+	assert.Negative(t, findCookie(t, rec, "oidc_session").MaxAge)
+}
+
+func TestOIDC_Callback(t *testing.T) {
+	t.Parallel()
+
+	provider := newOIDCProvider(t)
+	oidc := newOIDC(t, provider)
+
+	tests := []struct {
+		name       string
+		query      string
+		returnTo   string
+		nonce      string
+		wantStatus int
+	}{
+		{name: "state mismatch", query: "code=secret-code&state=forged", wantStatus: http.StatusBadRequest},
+		{name: "provider error", query: "error=access_denied", wantStatus: http.StatusUnauthorized},
+		{name: "invalid code", query: "code=other-code", wantStatus: http.StatusUnauthorized},
+		{name: "nonce mismatch", query: "code=secret-code", nonce: "forged", wantStatus: http.StatusUnauthorized},
+		{name: "open redirect", query: "code=secret-code", returnTo: "//evil.example", wantStatus: http.StatusFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			oidc.Login(rec, httptest.NewRequest(http.MethodGet, "/auth/login?return_to="+tt.returnTo, http.NoBody))
+
+			authorize, err := url.Parse(rec.Header().Get("Location"))
+			require.NoError(t, err)
+
+			params := authorize.Query()
+			if tt.nonce != "" {
+				params.Set("nonce", tt.nonce)
+			}
+
+			provider.authorize(params)
+
+			query := tt.query
+			if !strings.Contains(query, "state=") {
+				query += "&state=" + params.Get("state")
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/auth/callback?"+query, http.NoBody)
+			req.AddCookie(findCookie(t, rec, "oidc_flow"))
+
+			rec = httptest.NewRecorder()
+			oidc.Callback(rec, req)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.wantStatus == http.StatusFound {
+				assert.Equal(t, "/", rec.Header().Get("Location"))
+			}
+		})
+	}
+
+	t.Run("missing login", func(t *testing.T) {
+		t.Parallel()
+
+		rec := httptest.NewRecorder()
+		oidc.Callback(rec, httptest.NewRequest(http.MethodGet, "/auth/callback?code=secret-code", http.NoBody))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestOIDC_Callback_MissingSubject(t *testing.T) {
+	t.Parallel()
+
+	provider := newOIDCProvider(t)
+	provider.withoutSubject = true
+	oidc := newOIDC(t, provider)
+
+	rec := httptest.NewRecorder()
+	oidc.Login(rec, httptest.NewRequest(http.MethodGet, "/auth/login", http.NoBody))
+
+	authorize, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	provider.authorize(authorize.Query())
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/callback?code=secret-code&state="+authorize.Query().Get("state"),
+		http.NoBody)
+	req.AddCookie(findCookie(t, rec, "oidc_flow"))
+
+	rec = httptest.NewRecorder()
+	oidc.Callback(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	for _, line := range rec.Header().Values("Set-Cookie") {
+		assert.False(t, strings.HasPrefix(line, "oidc_session="), "session without subject must not be set")
+	}
+}
+
+func TestOIDC_Authenticate_FlowCookie(t *testing.T) {
+	t.Parallel()
+
+	oidc := newOIDC(t, newOIDCProvider(t))
+	protected := oidc.Authenticate()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	oidc.Login(rec, httptest.NewRequest(http.MethodGet, "/auth/login", http.NoBody))
+
+	// The signed flow cookie of a login is replayed as session cookie.
+	req := httptest.NewRequest(http.MethodPost, "/profile", http.NoBody)
+	req.AddCookie(&http.Cookie{Name: "oidc_session", Value: findCookie(t, rec, "oidc_flow").Value})
+
+	rec = httptest.NewRecorder()
+	protected.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestOIDC_Authenticate_MissingSessionSecret(t *testing.T) {
+	t.Parallel()
+
+	cfg := newOIDCConfig(t, newOIDCProvider(t))
+	cfg.RedirectURL = ""
+	cfg.SessionSecret = ""
+	require.NoError(t, cfg.Validate())
+
+	oidc, err := middleware.NewOIDC(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.PanicsWithValue(t, middleware.ErrInvalidSessionSecret, func() { oidc.Authenticate() })
+}
+
+func TestOIDC_Callback_SessionTooLarge(t *testing.T) {
+	t.Parallel()
+
+	provider := newOIDCProvider(t)
+	for i := range 500 {
+		provider.groups = append(provider.groups, fmt.Sprintf("group-%d", i))
+	}
+
+	cfg := newOIDCConfig(t, provider)
+	cfg.SessionClaims = append(cfg.SessionClaims, "groups")
+
+	oidc, err := middleware.NewOIDC(context.Background(), cfg)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	oidc.Login(rec, httptest.NewRequest(http.MethodGet, "/auth/login", http.NoBody))
+
+	authorize, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	provider.authorize(authorize.Query())
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/callback?code=secret-code&state="+authorize.Query().Get("state"),
+		http.NoBody)
+	req.AddCookie(findCookie(t, rec, "oidc_flow"))
+
+	rec = httptest.NewRecorder()
+	oidc.Callback(rec, req)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	for _, line := range rec.Header().Values("Set-Cookie") {
+		assert.False(t, strings.HasPrefix(line, "oidc_session="), "oversized session must not be set")
+	}
+}
+
+func TestOIDC_Introspect(t *testing.T) {
+	t.Parallel()
+
+	oidc := newOIDC(t, newOIDCProvider(t))
+	handler := oidc.Introspect()(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		identity, ok := middleware.OIDCIdentityFromContext(req.Context())
+		require.True(t, ok)
+
+		_, _ = w.Write([]byte(identity.Subject))
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          string
+		wantChallenge string
+		wantStatus    int
+	}{
+		{name: "active token", authorization: "Bearer active-token", wantStatus: http.StatusOK, want: "service-1"},
+		{
+			name:          "inactive token",
+			authorization: "Bearer revoked-token",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer error="invalid_token"`,
+		},
+		{name: "missing token", wantStatus: http.StatusUnauthorized, wantChallenge: "Bearer"},
+		{name: "basic credentials", authorization: "Basic dXNlcjpwYXNz", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/api", http.NoBody)
+			req.Header.Set("Authorization", tt.authorization)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.want != "" {
+				assert.Equal(t, tt.want, rec.Body.String())
+			}
+
+			if tt.wantChallenge != "" {
+				assert.Equal(t, tt.wantChallenge, rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestOIDC_Introspect_Cache(t *testing.T) {
+	t.Parallel()
+
+	provider := newOIDCProvider(t)
+	handler := newOIDC(t, provider).Introspect()(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		identity, ok := middleware.OIDCIdentityFromContext(req.Context())
+		require.True(t, ok)
+
+		if identity.Claims["scope"] != "read" {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		// Changing the claims of one request must not affect the cached result.
+		identity.Claims["scope"] = "admin"
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api", http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("active-token"))
+	assert.Equal(t, http.StatusOK, serve("active-token"))
+	assert.Equal(t, http.StatusOK, serve("active-token"))
+	assert.Equal(t, int64(1), provider.introspections.Load(), "active results must be cached")
+
+	assert.Equal(t, http.StatusUnauthorized, serve("revoked-token"))
+	assert.Equal(t, http.StatusUnauthorized, serve("revoked-token"))
+	assert.Equal(t, int64(3), provider.introspections.Load(), "inactive results must not be cached")
+}
+
+func TestNewOIDC(t *testing.T) {
+	t.Parallel()
+
+	t.Run("issuer mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		provider := newOIDCProvider(t)
+
+		cfg := &middleware.OIDCConfig{}
+		cfg.SetDefaults()
+		cfg.IssuerURL = provider.server.URL + "/other"
+		cfg.ClientID = "client"
+
+		_, err := middleware.NewOIDC(context.Background(), cfg)
+		require.ErrorIs(t, err, middleware.ErrOIDCDiscovery)
+	})
+
+	t.Run("API routes without introspection endpoint", func(t *testing.T) {
+		t.Parallel()
+
+		provider := newOIDCProvider(t)
+		provider.withoutIntrospection = true
+
+		cfg := newOIDCConfig(t, provider)
+		cfg.RedirectURL = ""
+
+		_, err := middleware.NewOIDC(context.Background(), cfg)
+		require.ErrorIs(t, err, middleware.ErrMissingIntrospection)
+	})
+
+	t.Run("browser routes without introspection endpoint", func(t *testing.T) {
+		t.Parallel()
+
+		provider := newOIDCProvider(t)
+		provider.withoutIntrospection = true
+
+		oidc := newOIDC(t, provider)
+		assert.PanicsWithValue(t, middleware.ErrMissingIntrospection, func() { oidc.Introspect() })
+	})
+}
+
+func TestOIDCConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		configure func(cfg *middleware.OIDCConfig)
+		wantErr   error
+		name      string
+	}{
+		{name: "API routes only", configure: func(*middleware.OIDCConfig) {}},
+		{
+			name: "browser routes",
+			configure: func(cfg *middleware.OIDCConfig) {
+				cfg.RedirectURL = "https://app.example/auth/callback"
+				cfg.SessionSecret = strings.Repeat("s", 32)
+			},
+		},
+		{
+			name:      "relative issuer",
+			configure: func(cfg *middleware.OIDCConfig) { cfg.IssuerURL = "/issuer" },
+			wantErr:   middleware.ErrInvalidIssuerURL,
+		},
+		{
+			name:      "missing client ID",
+			configure: func(cfg *middleware.OIDCConfig) { cfg.ClientID = "" },
+			wantErr:   middleware.ErrMissingClientID,
+		},
+		{
+			name:      "relative redirect URL",
+			configure: func(cfg *middleware.OIDCConfig) { cfg.RedirectURL = "/auth/callback" },
+			wantErr:   middleware.ErrInvalidRedirectURL,
+		},
+		{
+			name:      "missing session secret",
+			configure: func(cfg *middleware.OIDCConfig) { cfg.RedirectURL = "https://app.example/auth/callback" },
+			wantErr:   middleware.ErrInvalidSessionSecret,
+		},
+		{
+			name:      "short session secret of API routes",
+			configure: func(cfg *middleware.OIDCConfig) { cfg.SessionSecret = "secret" },
+			wantErr:   middleware.ErrInvalidSessionSecret,
+		},
+		{
+			name:      "non-positive session TTL",
+			configure: func(cfg *middleware.OIDCConfig) { cfg.SessionTTL = 0 },
+			wantErr:   middleware.ErrInvalidSessionTTL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &middleware.OIDCConfig{}
+			cfg.SetDefaults()
+			cfg.IssuerURL = "https://issuer.example"
+			cfg.ClientID = "client"
+			tt.configure(cfg)
+
+			require.ErrorIs(t, cfg.Validate(), tt.wantErr)
+		})
+	}
+}
+
+// oidcProvider is an OpenID Connect provider for tests that issues a token for the code "secret-code" to the
+// client "client" with the secret "secret", and reports the access token "active-token" as active.
+type oidcProvider struct {
+	server *httptest.Server
+
+	// logins maps the PKCE challenges of the authorization requests to their nonce.
+	logins map[string]string
+
+	// groups are the groups claim of the ID token.
+	groups []string
+
+	// introspections counts the introspection requests.
+	introspections atomic.Int64
+	mutex          sync.Mutex
+
+	// withoutIntrospection omits the introspection endpoint from the provider configuration.
+	withoutIntrospection bool
+
+	// withoutSubject omits the sub claim from the ID token.
+	withoutSubject bool
+}
+
+func newOIDCProvider(t *testing.T) *oidcProvider {
+	t.Helper()
+
+	provider := &oidcProvider{logins: map[string]string{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		discovery := map[string]string{
+			"issuer":                 provider.server.URL,
+			"authorization_endpoint": provider.server.URL + "/authorize",
+			"token_endpoint":         provider.server.URL + "/token",
+			"introspection_endpoint": provider.server.URL + "/introspect",
+			"end_session_endpoint":   provider.server.URL + "/logout",
+		}
+		if provider.withoutIntrospection {
+			delete(discovery, "introspection_endpoint")
+		}
+
+		writeJSON(w, discovery)
+	})
+	mux.HandleFunc("POST /token", provider.token)
+	mux.HandleFunc("POST /introspect", func(w http.ResponseWriter, req *http.Request) {
+		provider.introspections.Add(1)
+
+		if !validClient(req) {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		if req.PostFormValue("token") != "active-token" {
+			writeJSON(w, map[string]any{"active": false})
+
+			return
+		}
+
+		writeJSON(w, map[string]any{
+			"active": true,
+			"sub":    "service-1",
+			"scope":  "read",
+			"exp":    time.Now().Add(time.Hour).Unix(),
+		})
+	})
+
+	provider.server = httptest.NewServer(mux)
+	t.Cleanup(provider.server.Close)
+
+	return provider
+}
+
+// authorize records the authorization request as if the user signed in.
+func (p *oidcProvider) authorize(params url.Values) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.logins[params.Get("code_challenge")] = params.Get("nonce")
+}
+
+func (p *oidcProvider) token(w http.ResponseWriter, req *http.Request) {
+	challenge := sha256.Sum256([]byte(req.PostFormValue("code_verifier")))
+
+	p.mutex.Lock()
+	nonce, found := p.logins[base64.RawURLEncoding.EncodeToString(challenge[:])]
+	p.mutex.Unlock()
+
+	if !found || !validClient(req) || req.PostFormValue("code") != "secret-code" ||
+		req.PostFormValue("grant_type") != "authorization_code" {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	claims := map[string]any{
+		"iss":    p.server.URL,
+		"aud":    []string{"client"},
+		"sub":    "user-1",
+		"email":  "user@example.com",
+		"groups": p.groups,
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"nonce":  nonce,
+	}
+	if p.withoutSubject {
+		delete(claims, "sub")
+	}
+
+	payload, _ := json.Marshal(claims)
+
+	writeJSON(w, map[string]string{
+		"access_token": "active-token",
+		"token_type":   "Bearer",
+		"id_token":     "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".c2ln",
+	})
+}
+
+func newOIDC(t *testing.T, provider *oidcProvider) *middleware.OIDC {
+	t.Helper()
+
+	oidc, err := middleware.NewOIDC(context.Background(), newOIDCConfig(t, provider))
+	require.NoError(t, err)
+
+	return oidc
+}
+
+func newOIDCConfig(t *testing.T, provider *oidcProvider) *middleware.OIDCConfig {
+	t.Helper()
+
+	cfg := &middleware.OIDCConfig{}
+	cfg.SetDefaults()
+	cfg.IssuerURL = provider.server.URL
+	cfg.ClientID = "client"
+	cfg.ClientSecret = "secret"
+	cfg.RedirectURL = "https://app.example/auth/callback"
+	cfg.SessionSecret = strings.Repeat("s", 32)
+	cfg.HTTPClient = provider.server.Client()
+	require.NoError(t, cfg.Validate())
+
+	return cfg
+}
+
+func validClient(req *http.Request) bool {
+	username, password, ok := req.BasicAuth()
+
+	return ok && username == "client" && password == "secret"
+}
+
+func findCookie(t *testing.T, rec *httptest.ResponseRecorder, name string) *http.Cookie {
+	t.Helper()
+
+	for _, line := range rec.Header().Values("Set-Cookie") {
+		cookie, err := http.ParseSetCookie(line)
+		if err == nil && cookie.Name == name {
+			return cookie
+		}
+	}
+
+	require.Failf(t, "missing cookie", "cookie %q not set", name)
+
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
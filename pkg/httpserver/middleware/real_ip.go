@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+)
+
+var (
+	_ config.Defaultable = (*RealIPConfig)(nil)
+	_ config.Validatable = (*RealIPConfig)(nil)
+
+	ErrInvalidTrustedProxy = errors.New("real IP: trusted proxies must be CIDRs")
+	ErrInvalidRealIPHeader = errors.New("real IP: headers must be Forwarded, X-Forwarded-For or X-Real-IP")
+)
+
+// RealIPConfig holds the configuration for the RealIP middleware.
+type RealIPConfig struct {
+	// TrustedProxies lists the CIDRs of the load balancers and proxies in front of the server, e.g. "10.0.0.0/8".
+	// Headers of other peers are ignored, so clients cannot spoof their address.
+	// Default: []
+	TrustedProxies []string `json:"trustedProxies" yaml:"trustedProxies"`
+
+	// Headers lists the headers carrying the client address in the order they are consulted; the first one
+	// naming a client is used.
+	// Default: ["Forwarded", "X-Forwarded-For", "X-Real-IP"]
+	Headers []string `json:"headers" yaml:"headers"`
+}
+
+func (c *RealIPConfig) SetDefaults() {
+	c.TrustedProxies = []string{}
+	c.Headers = []string{"Forwarded", "X-Forwarded-For", "X-Real-IP"}
+}
+
+func (c *RealIPConfig) Validate() error {
+	for _, cidr := range c.TrustedProxies {
+		_, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidTrustedProxy, err)
+		}
+	}
+
+	for _, header := range c.Headers {
+		known := slices.ContainsFunc([]string{"Forwarded", "X-Forwarded-For", "X-Real-IP"}, func(name string) bool {
+			return strings.EqualFold(name, header)
+		})
+		if !known {
+			return fmt.Errorf("%w: %q", ErrInvalidRealIPHeader, header)
+		}
+	}
+
+	return nil
+}
+
+// RealIP returns a middleware that replaces the RemoteAddr of requests from trusted proxies with the address of
+// the client the proxies forwarded the request for, so later middlewares and handlers, e.g. Logger, see the
+// client instead of the load balancer. The addresses of a header are read from right to left, skipping trusted
+// proxies, so addresses prepended by the client itself are never used. The port is the one of the Forwarded
+// header, or zero if the header does not name one.
+func RealIP(cfg *RealIPConfig) httpserver.Middleware {
+	if cfg == nil {
+		cfg = &RealIPConfig{}
+		cfg.SetDefaults()
+	}
+
+	trusted := make([]netip.Prefix, 0, len(cfg.TrustedProxies))
+
+	for _, cidr := range cfg.TrustedProxies {
+		if prefix, err := netip.ParsePrefix(cidr); err == nil {
+			trusted = append(trusted, prefix.Masked())
+		}
+	}
+
+	trusts := func(addr netip.Addr) bool {
+		return slices.ContainsFunc(trusted, func(prefix netip.Prefix) bool { return prefix.Contains(addr.Unmap()) })
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			peer, err := netip.ParseAddrPort(req.RemoteAddr)
+			if err != nil || !trusts(peer.Addr()) {
+				next.ServeHTTP(resp, req)
+
+				return
+			}
+
+			for _, header := range cfg.Headers {
+				client, found := forwardedClient(forwardedAddrs(req.Header, header), trusts)
+				if found {
+					req = req.Clone(req.Context())
+					req.RemoteAddr = client.String()
+
+					break
+				}
+			}
+
+			next.ServeHTTP(resp, req)
+		})
+	}
+}
+
+// forwardedClient returns the last address that is not trusted, or the first one if all of them are.
+// It reports false if there are no addresses or one that is not trusted cannot be parsed.
+func forwardedClient(addrs []string, trusts func(addr netip.Addr) bool) (netip.AddrPort, bool) {
+	var client netip.AddrPort
+
+	for _, raw := range slices.Backward(addrs) {
+		addr, err := parseForwardedAddr(raw)
+		if err != nil {
+			return netip.AddrPort{}, false
+		}
+
+		client = addr
+		if !trusts(addr.Addr()) {
+			break
+		}
+	}
+
+	return client, client.IsValid()
+}
+
+// forwardedAddrs returns the addresses of the header of all proxies in the order they were added.
+func forwardedAddrs(header http.Header, name string) []string {
+	var addrs []string
+
+	for _, value := range header.Values(name) {
+		for element := range strings.SplitSeq(value, ",") {
+			element = strings.TrimSpace(element)
+
+			if !strings.EqualFold(name, "Forwarded") {
+				addrs = append(addrs, element)
+
+				continue
+			}
+
+			for pair := range strings.SplitSeq(element, ";") {
+				key, addr, _ := strings.Cut(strings.TrimSpace(pair), "=")
+				if strings.EqualFold(key, "for") {
+					addrs = append(addrs, strings.Trim(addr, `"`))
+				}
+			}
+		}
+	}
+
+	return addrs
+}
+
+// parseForwardedAddr parses an address with optional port, IPv6 addresses with port in brackets.
+func parseForwardedAddr(raw string) (netip.AddrPort, error) {
+	if addrPort, err := netip.ParseAddrPort(raw); err == nil {
+		return netip.AddrPortFrom(addrPort.Addr().Unmap(), addrPort.Port()), nil
+	}
+
+	addr, err := netip.ParseAddr(strings.Trim(raw, "[]"))
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("invalid forwarded address: %w", err)
+	}
+
+	return netip.AddrPortFrom(addr.Unmap(), 0), nil
+}
@@ -0,0 +1,135 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealIP(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		header     http.Header
+		name       string
+		remoteAddr string
+		want       string
+	}{
+		{
+			name:       "untrusted peer",
+			remoteAddr: "203.0.113.9:4000",
+			header:     http.Header{"X-Forwarded-For": {"198.51.100.1"}},
+			want:       "203.0.113.9:4000",
+		},
+		{
+			name:       "no header",
+			remoteAddr: "10.0.0.1:4000",
+			want:       "10.0.0.1:4000",
+		},
+		{
+			name:       "X-Forwarded-For",
+			remoteAddr: "10.0.0.1:4000",
+			header:     http.Header{"X-Forwarded-For": {"198.51.100.1"}},
+			want:       "198.51.100.1:0",
+		},
+		{
+			name:       "spoofed X-Forwarded-For",
+			remoteAddr: "10.0.0.1:4000",
+			header:     http.Header{"X-Forwarded-For": {"192.0.2.66, 198.51.100.1", "10.0.0.2"}},
+			want:       "198.51.100.1:0",
+		},
+		{
+			name:       "only trusted proxies",
+			remoteAddr: "10.0.0.1:4000",
+			header:     http.Header{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}},
+			want:       "10.0.0.3:0",
+		},
+		{
+			name:       "Forwarded",
+			remoteAddr: "10.0.0.1:4000",
+			header: http.Header{
+				"Forwarded":       {`for=192.0.2.60;proto=http, for="[2001:db8:cafe::17]:4711";by=10.0.0.1`},
+				"X-Forwarded-For": {"198.51.100.1"},
+			},
+			want: "[2001:db8:cafe::17]:4711",
+		},
+		{
+			name:       "invalid Forwarded",
+			remoteAddr: "10.0.0.1:4000",
+			header:     http.Header{"Forwarded": {"for=_hidden"}, "X-Real-Ip": {"198.51.100.2"}},
+			want:       "198.51.100.2:0",
+		},
+		{
+			name:       "IPv4-mapped peer",
+			remoteAddr: "[::ffff:10.0.0.1]:4000",
+			header:     http.Header{"X-Real-Ip": {"198.51.100.2"}},
+			want:       "198.51.100.2:0",
+		},
+	}
+
+	cfg := &middleware.RealIPConfig{}
+	cfg.SetDefaults()
+	cfg.TrustedProxies = []string{"10.0.0.0/8"}
+	require.NoError(t, cfg.Validate())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got string
+
+			handler := middleware.RealIP(cfg)(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+				got = req.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header = tt.header
+
+			if req.Header == nil {
+				req.Header = http.Header{}
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRealIPConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		configure func(cfg *middleware.RealIPConfig)
+		wantErr   error
+		name      string
+	}{
+		{name: "defaults", configure: func(*middleware.RealIPConfig) {}},
+		{
+			name:      "invalid CIDR",
+			configure: func(cfg *middleware.RealIPConfig) { cfg.TrustedProxies = []string{"10.0.0.1"} },
+			wantErr:   middleware.ErrInvalidTrustedProxy,
+		},
+		{
+			name:      "unknown header",
+			configure: func(cfg *middleware.RealIPConfig) { cfg.Headers = []string{"x-real-ip", "X-Client-IP"} },
+			wantErr:   middleware.ErrInvalidRealIPHeader,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &middleware.RealIPConfig{}
+			cfg.SetDefaults()
+			tt.configure(cfg)
+
+			require.ErrorIs(t, cfg.Validate(), tt.wantErr)
+		})
+	}
+}
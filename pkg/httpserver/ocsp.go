@@ -0,0 +1,216 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	// OCSPRetryInterval is the interval in which failed OCSP fetches are retried.
+	OCSPRetryInterval = 5 * time.Minute
+
+	// OCSPFetchTimeout is the timeout of a single OCSP fetch.
+	OCSPFetchTimeout = 30 * time.Second
+
+	// maxOCSPResponseSize is the maximum size of an OCSP response.
+	maxOCSPResponseSize = 1 << 20
+)
+
+var (
+	ErrOCSPUnavailable = errors.New("httpserver: OCSP response unavailable")
+	ErrOCSPRevoked     = errors.New("httpserver: certificate is not valid according to OCSP")
+)
+
+// OCSPStatus describes the OCSP response stapled to the certificate of CertFile.
+type OCSPStatus struct {
+	// LastFetch is the time of the last fetch, successful or not.
+	LastFetch time.Time
+
+	// ThisUpdate and NextUpdate bound the validity of the stapled response.
+	ThisUpdate time.Time
+	NextUpdate time.Time
+
+	// Err is the error of the last fetch, or nil if it succeeded.
+	Err error
+
+	// Stapled indicates whether a valid response is stapled to handshakes.
+	Stapled bool
+}
+
+// ocspStapler staples OCSP responses to a certificate and refreshes them before they expire.
+type ocspStapler struct {
+	server *HTTPServer
+	client *http.Client
+
+	certificate tls.Certificate
+	issuer      *x509.Certificate
+
+	// cancel stops refreshing.
+	cancel context.CancelFunc
+
+	// status describes the last fetch, staple is the raw response stapled to handshakes.
+	status OCSPStatus
+	staple []byte
+
+	// mutex guards staple and status.
+	mutex sync.RWMutex
+}
+
+// newOCSPStapler loads the certificate of the configuration. It returns nil if the certificate
+// names no OCSP responder, as nothing can be stapled then.
+func newOCSPStapler(server *HTTPServer) (*ocspStapler, error) {
+	certificate, err := tls.LoadX509KeyPair(server.cfg.CertFile, server.cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("httpserver: failed to load certificate for OCSP stapling: %w", err)
+	}
+
+	if len(certificate.Leaf.OCSPServer) == 0 {
+		server.Log.Warn("certificate names no OCSP responder, serving without OCSP stapling")
+
+		return nil, nil //nolint:nilnil // Stapling is skipped without error.
+	}
+
+	if len(certificate.Certificate) < 2 {
+		return nil, fmt.Errorf("%w: cert file must contain the issuer certificate", ErrOCSPUnavailable)
+	}
+
+	issuer, err := x509.ParseCertificate(certificate.Certificate[1])
+	if err != nil {
+		return nil, fmt.Errorf("httpserver: failed to parse issuer certificate: %w", err)
+	}
+
+	return &ocspStapler{
+		server:      server,
+		client:      &http.Client{Timeout: OCSPFetchTimeout},
+		certificate: certificate,
+		issuer:      issuer,
+	}, nil
+}
+
+// fetch requests a response for the certificate from its OCSP responder.
+func (o *ocspStapler) fetch(ctx context.Context) ([]byte, *ocsp.Response, error) {
+	request, err := ocsp.CreateRequest(o.certificate.Leaf, o.issuer, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrOCSPUnavailable, err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, o.certificate.Leaf.OCSPServer[0], bytes.NewReader(request),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrOCSPUnavailable, err)
+	}
+
+	req.Header.Set("Content-Type", "application/ocsp-request")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrOCSPUnavailable, err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("%w: responder answered %s", ErrOCSPUnavailable, resp.Status)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxOCSPResponseSize))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrOCSPUnavailable, err)
+	}
+
+	response, err := ocsp.ParseResponseForCert(raw, o.certificate.Leaf, o.issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrOCSPUnavailable, err)
+	}
+
+	if response.Status != ocsp.Good {
+		return nil, nil, fmt.Errorf("%w: status %d", ErrOCSPRevoked, response.Status)
+	}
+
+	return raw, response, nil
+}
+
+// getCertificate returns the certificate with the current staple as tls.Config.GetCertificate.
+func (o *ocspStapler) getCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	certificate := o.certificate
+	certificate.OCSPStaple = o.staple
+
+	return &certificate, nil
+}
+
+// refresh fetches a response and returns the time of the next refresh: halfway to the expiry of the response,
+// or OCSPRetryInterval after a failure. A jitter of up to a tenth spreads the load of many servers.
+func (o *ocspStapler) refresh(ctx context.Context) time.Duration {
+	raw, response, err := o.fetch(ctx)
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.status.LastFetch = time.Now()
+	o.status.Err = err
+
+	next := OCSPRetryInterval
+
+	if err != nil {
+		o.server.Log.Warn("failed to fetch OCSP response", "error", err)
+
+		// A response already stapled remains valid until it expires.
+		if o.status.Stapled && !o.status.NextUpdate.IsZero() && time.Now().After(o.status.NextUpdate) {
+			o.staple, o.status.Stapled = nil, false
+		}
+	} else {
+		o.staple = raw
+		o.status.Stapled = true
+		o.status.ThisUpdate, o.status.NextUpdate = response.ThisUpdate, response.NextUpdate
+
+		if !response.NextUpdate.IsZero() {
+			next = max(time.Until(response.NextUpdate)/2, time.Minute)
+		}
+	}
+
+	return next - rand.N(next/10+1) //nolint:gosec // Jitter needs no cryptographic randomness.
+}
+
+// start staples a response and refreshes it in the background until stop is called.
+func (o *ocspStapler) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	o.cancel = cancel
+
+	go func() {
+		for {
+			timer := time.NewTimer(o.refresh(ctx))
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+}
+
+// stop stops refreshing if started.
+func (o *ocspStapler) stop() {
+	if o.cancel != nil {
+		o.cancel()
+	}
+}
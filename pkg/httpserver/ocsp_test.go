@@ -0,0 +1,167 @@
+package httpserver_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestHTTPServer_OCSPStapling(t *testing.T) {
+	t.Parallel()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	responder := newOCSPResponder(t, ca, caKey)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+		OCSPServer:   []string{responder.URL},
+	}
+
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &leafKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	certFile, keyFile := writeTestKeyPair(t, leafKey, leafDER, caDER)
+
+	metrics := httpserver.NewMetrics()
+	server := httpserver.New(
+		&httpserver.Config{Host: "127.0.0.1", CertFile: certFile, KeyFile: keyFile, OCSPStapling: true},
+		httpserver.WithLogger(&mockLogger{}),
+		httpserver.WithMetrics(metrics),
+	)
+
+	assert.Equal(t, httpserver.OCSPStatus{}, server.OCSPStatus())
+
+	require.NoError(t, server.Start(context.Background()))
+
+	assert.Eventually(t, func() bool {
+		return server.OCSPStatus().Stapled
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status := server.OCSPStatus()
+	require.NoError(t, status.Err)
+	assert.False(t, status.LastFetch.IsZero())
+	assert.WithinDuration(t, time.Now().Add(time.Hour), status.NextUpdate, time.Minute)
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	assert.Contains(t, rec.Body.String(), "httpserver_ocsp_stapled 1\n")
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	conn, err := (&tls.Dialer{Config: &tls.Config{
+		RootCAs:    roots,
+		ServerName: "localhost",
+		MinVersion: tls.VersionTLS12,
+	}}).DialContext(context.Background(), "tcp", server.Addr().String())
+	require.NoError(t, err)
+
+	tlsConn, ok := conn.(*tls.Conn)
+	require.True(t, ok)
+
+	state := tlsConn.ConnectionState()
+	response, err := ocsp.ParseResponseForCert(state.OCSPResponse, state.PeerCertificates[0], ca)
+	require.NoError(t, err)
+	assert.Equal(t, ocsp.Good, response.Status)
+	require.NoError(t, conn.Close())
+
+	require.NoError(t, server.Stop(context.Background()))
+}
+
+// newOCSPResponder starts a responder signed by the CA declaring every certificate good for an hour.
+func newOCSPResponder(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey) *httptest.Server {
+	t.Helper()
+
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		request, err := ocsp.ParseRequest(body)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		response, err := ocsp.CreateResponse(ca, ca, ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: request.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+		}, caKey)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		_, _ = w.Write(response)
+	}))
+	t.Cleanup(responder.Close)
+
+	return responder
+}
+
+// writeTestKeyPair stores the key and the certificate chain as PEM files.
+func writeTestKeyPair(t *testing.T, key crypto.Signer, chain ...[]byte) (certFile, keyFile string) {
+	t.Helper()
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	keyFile = filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	var certPEM []byte
+	for _, der := range chain {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+
+	certFile = filepath.Join(t.TempDir(), "cert.pem")
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+
+	return certFile, keyFile
+}
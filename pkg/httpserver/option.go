@@ -1,7 +1,13 @@
 package httpserver
 
 import (
+	"context"
+	"crypto/tls"
+	"net"
 	"net/http"
+	"slices"
+	"strings"
+	"syscall"
 
 	"github.com/spacecafe/go-parts/pkg/log"
 )
@@ -9,14 +15,135 @@ import (
 // Option is a functional option for configuring HTTPServer.
 type Option func(*HTTPServer)
 
+// WithBaseContext derives the contexts of all requests from the given context, so handlers inherit its values,
+// e.g. the logger or configuration of the application, without wiring them into every route.
+// Requests are canceled once the context is, e.g. when a shutdown context is passed.
+func WithBaseContext(ctx context.Context) Option {
+	return func(s *HTTPServer) {
+		s.Server.BaseContext = func(net.Listener) context.Context {
+			return ctx
+		}
+	}
+}
+
+// WithConnContext decorates the context of every connection, which the contexts of its requests derive from,
+// e.g. to attach values describing the connection. The function must return a non-nil context.
+// It runs in the accept loop of http.Server, so it must not block. With the PROXY protocol, conn.RemoteAddr
+// waits up to ProxyHeaderTimeout for the header of trusted proxies, which would delay accepting all other
+// connections; read the remote address from the requests instead, e.g. in a middleware.
+func WithConnContext(decorate func(ctx context.Context, conn net.Conn) context.Context) Option {
+	return func(s *HTTPServer) {
+		s.Server.ConnContext = decorate
+	}
+}
+
 func WithHandler(handler http.Handler) Option {
 	return func(s *HTTPServer) {
 		s.Server.Handler = handler
 	}
 }
 
+// WithHealth serves the liveness and readiness probes of the Health at LivenessPath and ReadinessPath,
+// outside of the base path. Stop fails the readiness probe and waits StopDelay before closing the listeners.
+func WithHealth(health *Health) Option {
+	return func(s *HTTPServer) {
+		s.health = health
+	}
+}
+
+// WithListener serves Server on the given listener instead of listening on Host and Port,
+// e.g. an in-memory listener in tests, a listener unwrapping the proxy protocol or one inherited from the parent.
+// The listener is closed when the server stops.
+func WithListener(listener net.Listener) Option {
+	return func(s *HTTPServer) {
+		s.listener = listener
+	}
+}
+
+// WithListenControl calls the given function on every socket before it is bound, e.g. to set socket options
+// Config.Listener does not cover. It runs after the options of Config.Listener.
+func WithListenControl(control func(network, address string, conn syscall.RawConn) error) Option {
+	return func(s *HTTPServer) {
+		s.listenControl = control
+	}
+}
+
 func WithLogger(logger log.Logger) Option {
 	return func(s *HTTPServer) {
 		s.Log = logger
 	}
 }
+
+// WithMetrics serves the Metrics at MetricsPath, outside of the base path, and records the metrics of the server.
+func WithMetrics(metrics *Metrics) Option {
+	return func(s *HTTPServer) {
+		metrics.server = s
+		s.metrics = metrics
+	}
+}
+
+// WithOnError calls the hook with the error of every server that fails after Start returned, e.g. to shut down
+// the application. Failures during Start are returned by Start instead. It may be given several times.
+func WithOnError(hook func(err error)) Option {
+	return func(s *HTTPServer) {
+		s.hooks.errors = append(s.hooks.errors, hook)
+	}
+}
+
+// WithOnStart calls the hook with the address of Server once Start bound all listeners and they survived
+// StartupCheckTimeout, e.g. to register the service with a discovery system. It may be given several times.
+func WithOnStart(hook func(addr net.Addr)) Option {
+	return func(s *HTTPServer) {
+		s.hooks.start = append(s.hooks.start, hook)
+	}
+}
+
+// WithOnStop calls the hook when Stop begins, before the readiness probe fails and the listeners close,
+// e.g. to deregister the service from a discovery system. It may be given several times.
+func WithOnStop(hook func()) Option {
+	return func(s *HTTPServer) {
+		s.hooks.stop = append(s.hooks.stop, hook)
+	}
+}
+
+// WithOnStopped calls the hook with the result of Stop once all servers stopped. It may be given several times.
+func WithOnStopped(hook func(err error)) Option {
+	return func(s *HTTPServer) {
+		s.hooks.stopped = append(s.hooks.stopped, hook)
+	}
+}
+
+// WithPprof serves the runtime profiles below the path prefix, e.g. DefaultPprofPrefix, guarded by the
+// middlewares, e.g. authentication. The profiles are served outside the base path and nothing is registered
+// on http.DefaultServeMux. Prefer the AdminServer to keep them off the public port.
+func WithPprof(pathPrefix string, middlewares ...Middleware) Option {
+	pathPrefix = strings.TrimSuffix(pathPrefix, "/")
+
+	return func(s *HTTPServer) {
+		var handler http.Handler = newProfiler(s, pathPrefix)
+		for _, middleware := range slices.Backward(middlewares) {
+			handler = middleware(handler)
+		}
+
+		s.pprof = &mount{prefix: pathPrefix + "/", handler: handler}
+	}
+}
+
+// WithProtocols serves the given protocols instead of those selected by EnableH2C and DisableHTTP2,
+// e.g. only unencrypted HTTP/2 behind a proxy. The protocols are copied.
+func WithProtocols(protocols *http.Protocols) Option {
+	return func(s *HTTPServer) {
+		copied := *protocols
+		s.Server.Protocols = &copied
+	}
+}
+
+// WithTLSConfig serves TLS with a copy of the given settings, e.g. cipher suites, session tickets, ALPN or
+// GetConfigForClient. The certificate of CertFile and KeyFile replaces those of the settings, certificates
+// obtained by ACME are only used if the settings provide none. The minimum version, cipher suites and curves
+// left unset are taken from Config.TLSProfile; MinVersion defaults to TLS 1.2.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(s *HTTPServer) {
+		s.Server.TLSConfig = mergeTLSConfig(config, s.Server.TLSConfig, s.cfg.TLSProfile)
+	}
+}
@@ -0,0 +1,68 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/spacecafe/go-parts/pkg/typeconv"
+)
+
+var (
+	ErrMissingParam = errors.New("httpserver: missing path parameter")
+	ErrInvalidParam = errors.New("httpserver: invalid path parameter")
+)
+
+// Param returns the path parameter of the wildcard of the pattern the request matched, e.g. {id},
+// converted to T by typeconv.ConvertTo. Empty values fail with ErrMissingParam, values that cannot be
+// converted with ErrInvalidParam.
+//
+//nolint:ireturn // Generic function must return type parameter T.
+func Param[T any](req *http.Request, name string) (T, error) {
+	value := req.PathValue(name)
+	if value == "" {
+		var zero T
+
+		return zero, fmt.Errorf("%w: %s", ErrMissingParam, name)
+	}
+
+	result, err := typeconv.ConvertTo[T](value)
+	if err != nil {
+		return result, fmt.Errorf("%w: %s: %w", ErrInvalidParam, name, err)
+	}
+
+	return result, nil
+}
+
+// MustParam is like Param but panics if the parameter is missing or invalid. It suits wildcards whose constraint
+// guarantees a valid value, e.g. {id:[0-9]+}.
+//
+//nolint:ireturn // Generic function must return type parameter T.
+func MustParam[T any](req *http.Request, name string) T {
+	result, err := Param[T](req, name)
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+// ParamOrBadRequest is like Param but answers the request with 400 if the parameter is missing or invalid.
+// The handler returns without writing a response if ok is false, e.g.
+//
+//	id, ok := httpserver.ParamOrBadRequest[int64](resp, req, "id")
+//	if !ok {
+//		return
+//	}
+//
+//nolint:ireturn // Generic function must return type parameter T.
+func ParamOrBadRequest[T any](resp http.ResponseWriter, req *http.Request, name string) (T, bool) {
+	result, err := Param[T](req, name)
+	if err != nil {
+		http.Error(resp, "invalid path parameter "+name, http.StatusBadRequest)
+
+		return result, false
+	}
+
+	return result, true
+}
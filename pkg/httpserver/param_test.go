@@ -0,0 +1,88 @@
+package httpserver_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParam(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		target  string
+		want    int64
+	}{
+		{name: "valid parameter", target: "/users/42", want: 42},
+		{name: "invalid parameter", target: "/users/abc", wantErr: httpserver.ErrInvalidParam},
+		{name: "missing parameter", target: "/users/", wantErr: httpserver.ErrMissingParam},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				got int64
+				err error
+			)
+
+			router := httpserver.NewRouter()
+			router.HandleFunc("GET /users/{id...}", func(_ http.ResponseWriter, r *http.Request) {
+				got, err = httpserver.Param[int64](r, "id")
+			})
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.target, http.NoBody))
+
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMustParam(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/users/7", http.NoBody)
+	req.SetPathValue("id", "7")
+	assert.Equal(t, 7, httpserver.MustParam[int](req, "id"))
+
+	req.SetPathValue("id", "seven")
+	_, err := httpserver.Param[int](req, "id")
+	require.ErrorIs(t, err, httpserver.ErrInvalidParam)
+	assert.PanicsWithError(t, err.Error(), func() { httpserver.MustParam[int](req, "id") })
+}
+
+func TestParamOrBadRequest(t *testing.T) {
+	t.Parallel()
+
+	router := httpserver.NewRouter()
+	router.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := httpserver.ParamOrBadRequest[uint](w, r, "id")
+		if !ok {
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, "user %d", id)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/7", http.NoBody))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "user 7", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/-1", http.NoBody))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid path parameter id")
+}
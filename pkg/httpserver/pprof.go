@@ -0,0 +1,182 @@
+package httpserver
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPprofPrefix is the path prefix of the runtime profiles served by the AdminServer.
+	DefaultPprofPrefix = "/debug/pprof"
+
+	// DefaultCPUProfileDuration is the duration of CPU profiles requested without seconds.
+	DefaultCPUProfileDuration = 30 * time.Second
+
+	// DefaultTraceDuration is the duration of execution traces requested without seconds.
+	DefaultTraceDuration = time.Second
+)
+
+// mount routes requests below a path prefix to its own handler in front of the handler of the server.
+type mount struct {
+	handler http.Handler
+	prefix  string
+}
+
+// wrap serves requests below the prefix with the handler of the mount and all others with the given handler.
+func (m *mount) wrap(handler http.Handler) http.Handler {
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.URL.Path, m.prefix) {
+			m.handler.ServeHTTP(resp, req)
+
+			return
+		}
+
+		handler.ServeHTTP(resp, req)
+	})
+}
+
+// profiler serves the runtime profiles like net/http/pprof, without registering them on http.DefaultServeMux.
+type profiler struct {
+	server *HTTPServer
+}
+
+// newProfiler returns a handler serving the index of profiles at the prefix followed by a slash,
+// CPU profiles at profile, execution traces at trace and all other profiles by their name, e.g. heap.
+func newProfiler(server *HTTPServer, prefix string) http.Handler {
+	obj := &profiler{server: server}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+prefix+"/{$}", handleProfiles)
+	mux.HandleFunc("GET "+prefix+"/profile", obj.handleCPUProfile)
+	mux.HandleFunc("GET "+prefix+"/trace", obj.handleTrace)
+	mux.HandleFunc("GET "+prefix+"/{name}", obj.handleProfile)
+
+	return mux
+}
+
+// handleCPUProfile records a CPU profile for the given seconds.
+func (p *profiler) handleCPUProfile(resp http.ResponseWriter, req *http.Request) {
+	duration, ok := p.profileDuration(resp, req, DefaultCPUProfileDuration)
+	if !ok {
+		return
+	}
+
+	setProfileHeaders(resp, "profile", 0)
+
+	err := pprof.StartCPUProfile(resp)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	sleepContext(req.Context(), duration)
+	pprof.StopCPUProfile()
+}
+
+// handleProfile writes the named runtime profile, e.g. heap or goroutine, in text form if debug is positive.
+func (p *profiler) handleProfile(resp http.ResponseWriter, req *http.Request) {
+	name := req.PathValue("name")
+
+	profile := pprof.Lookup(name)
+	if profile == nil {
+		http.NotFound(resp, req)
+
+		return
+	}
+
+	debug, _ := strconv.Atoi(req.FormValue("debug"))
+	if name == "heap" && req.FormValue("gc") != "" {
+		runtime.GC()
+	}
+
+	setProfileHeaders(resp, name, debug)
+
+	err := profile.WriteTo(resp, debug)
+	if err != nil {
+		p.server.Log.Error("failed to write profile", "profile", name, "error", err)
+	}
+}
+
+// handleTrace records an execution trace for the given seconds.
+func (p *profiler) handleTrace(resp http.ResponseWriter, req *http.Request) {
+	duration, ok := p.profileDuration(resp, req, DefaultTraceDuration)
+	if !ok {
+		return
+	}
+
+	setProfileHeaders(resp, "trace", 0)
+
+	err := trace.Start(resp)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	sleepContext(req.Context(), duration)
+	trace.Stop()
+}
+
+// profileDuration parses the seconds of a recording, which must end before the write timeout of the server.
+// It reports whether the duration is valid and writes an error otherwise.
+func (p *profiler) profileDuration(
+	resp http.ResponseWriter,
+	req *http.Request,
+	fallback time.Duration,
+) (time.Duration, bool) {
+	duration := fallback
+
+	if value := req.FormValue("seconds"); value != "" {
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil || seconds <= 0 {
+			http.Error(resp, "seconds must be a positive number", http.StatusBadRequest)
+
+			return 0, false
+		}
+
+		duration = time.Duration(seconds * float64(time.Second))
+	}
+
+	if p.server.Server.WriteTimeout > 0 && duration >= p.server.Server.WriteTimeout {
+		http.Error(resp, "seconds exceed the write timeout of the server", http.StatusBadRequest)
+
+		return 0, false
+	}
+
+	return duration, true
+}
+
+// handleProfiles lists the available runtime profiles.
+func handleProfiles(resp http.ResponseWriter, _ *http.Request) {
+	resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	for _, profile := range pprof.Profiles() {
+		_, _ = fmt.Fprintf(resp, "%s\t%d\n", profile.Name(), profile.Count())
+	}
+
+	_, _ = io.WriteString(resp, "profile\ntrace\n")
+}
+
+// setProfileHeaders marks the response as text for debug output and as a download otherwise.
+func setProfileHeaders(resp http.ResponseWriter, name string, debug int) {
+	if debug > 0 {
+		resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/octet-stream")
+	resp.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+}
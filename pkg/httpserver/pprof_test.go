@@ -0,0 +1,63 @@
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPprof(t *testing.T) {
+	t.Parallel()
+
+	guard := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Debug-Token") != "secret" {
+				w.WriteHeader(http.StatusForbidden)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	server := httpserver.New(
+		&httpserver.Config{BasePath: "/api"},
+		httpserver.WithPprof("/ops/pprof/", guard),
+		httpserver.WithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})),
+	)
+
+	tests := []struct {
+		name       string
+		target     string
+		token      string
+		wantBody   string
+		wantStatus int
+	}{
+		{name: "index", target: "/ops/pprof/", token: "secret", wantStatus: http.StatusOK, wantBody: "goroutine"},
+		{name: "heap profile", target: "/ops/pprof/heap?debug=1", token: "secret", wantStatus: http.StatusOK},
+		{name: "guarded", target: "/ops/pprof/heap", wantStatus: http.StatusForbidden},
+		{name: "other routes", target: "/api/users", wantStatus: http.StatusTeapot},
+		{name: "default prefix is not mounted", target: "/debug/pprof/", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, http.NoBody)
+			req.Header.Set("X-Debug-Token", tt.token)
+
+			rec := httptest.NewRecorder()
+			server.Server.Handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/config"
+	"github.com/spacecafe/go-parts/pkg/log"
+)
+
+const (
+	DefaultProxyDialTimeout           = time.Second * 10
+	DefaultProxyResponseHeaderTimeout = time.Second * 30
+	DefaultProxyIdleConnTimeout       = time.Second * 90
+)
+
+var (
+	_ config.Defaultable = (*ProxyConfig)(nil)
+	_ config.Validatable = (*ProxyConfig)(nil)
+	_ http.Handler       = (*Proxy)(nil)
+
+	ErrMissingUpstreams    = errors.New("httpserver proxy upstreams must not be empty")
+	ErrInvalidUpstream     = errors.New("httpserver proxy upstreams must be absolute http or https URLs")
+	ErrInvalidProxyTimeout = errors.New("httpserver proxy timeouts must not be negative")
+	ErrInvalidRetries      = errors.New("httpserver proxy retries must not be negative")
+)
+
+// ProxyConfig defines the upstreams of a Proxy and how requests are forwarded to them.
+type ProxyConfig struct {
+	// Upstreams lists the base URLs of interchangeable replicas of the upstream service, e.g.
+	// "http://10.0.0.1:8080/api". Requests are distributed round-robin; their path is appended to that of the URL.
+	Upstreams []string `json:"upstreams" yaml:"upstreams"`
+
+	// RequestHeaders rewrites the headers of requests sent to the upstreams.
+	RequestHeaders HeaderRules `json:"requestHeaders" yaml:"requestHeaders"`
+
+	// ResponseHeaders rewrites the headers of responses returned to the clients.
+	ResponseHeaders HeaderRules `json:"responseHeaders" yaml:"responseHeaders"`
+
+	// DialTimeout represents the maximum duration of connecting to an upstream. Zero means no timeout.
+	DialTimeout time.Duration `json:"dialTimeout" yaml:"dialTimeout"`
+
+	// ResponseHeaderTimeout represents the maximum duration of waiting for the response headers of an upstream.
+	// Zero means no timeout.
+	ResponseHeaderTimeout time.Duration `json:"responseHeaderTimeout" yaml:"responseHeaderTimeout"`
+
+	// IdleConnTimeout represents how long idle connections to the upstreams are kept. Zero means no limit.
+	IdleConnTimeout time.Duration `json:"idleConnTimeout" yaml:"idleConnTimeout"`
+
+	// Retries represents how many further upstreams are tried if an upstream cannot be reached.
+	// Only requests without body and with the methods GET, HEAD and OPTIONS are retried.
+	Retries int `json:"retries" yaml:"retries"`
+
+	// PreserveHost indicates whether the Host header of the client is sent to the upstreams
+	// instead of the host of the upstream URL.
+	PreserveHost bool `json:"preserveHost" yaml:"preserveHost"`
+}
+
+// HeaderRules defines how the headers of a proxied message are rewritten. Removals are applied first.
+type HeaderRules struct {
+	// Set maps headers to the value replacing all of their values in the message.
+	Set map[string]string `json:"set" yaml:"set"`
+
+	// Remove lists the headers removed from the message.
+	Remove []string `json:"remove" yaml:"remove"`
+}
+
+// SetDefaults initializes the default values for the relevant fields in the struct.
+func (c *ProxyConfig) SetDefaults() {
+	c.DialTimeout = DefaultProxyDialTimeout
+	c.ResponseHeaderTimeout = DefaultProxyResponseHeaderTimeout
+	c.IdleConnTimeout = DefaultProxyIdleConnTimeout
+}
+
+// Validate ensures the all necessary configurations are filled and within valid confines.
+func (c *ProxyConfig) Validate() error {
+	if len(c.Upstreams) == 0 {
+		return ErrMissingUpstreams
+	}
+
+	for _, upstream := range c.Upstreams {
+		target, err := url.Parse(upstream)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidUpstream, err)
+		}
+
+		if (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return fmt.Errorf("%w: %s", ErrInvalidUpstream, upstream)
+		}
+	}
+
+	if c.DialTimeout < 0 || c.ResponseHeaderTimeout < 0 || c.IdleConnTimeout < 0 {
+		return ErrInvalidProxyTimeout
+	}
+
+	if c.Retries < 0 {
+		return ErrInvalidRetries
+	}
+
+	return nil
+}
+
+// apply rewrites the header according to the rules.
+func (h *HeaderRules) apply(header http.Header) {
+	for _, name := range h.Remove {
+		header.Del(name)
+	}
+
+	for name, value := range h.Set {
+		header.Set(name, value)
+	}
+}
+
+// Proxy forwards requests to upstream services, built on httputil.ReverseProxy. As an http.Handler it is
+// mounted on a Router like any other handler and runs behind its middleware, e.g.
+// router.Handle("/api/", http.StripPrefix("/api", proxy)). X-Forwarded headers are set for the upstreams.
+// Upstreams that cannot be reached are answered with 502, timeouts with 504.
+type Proxy struct {
+	Log log.Logger
+
+	cfg     *ProxyConfig
+	proxy   *httputil.ReverseProxy
+	targets []*url.URL
+
+	// next is the index of the upstream serving the next request.
+	next atomic.Uint64
+}
+
+// NewProxy creates a Proxy for the upstreams of the validated configuration.
+func NewProxy(cfg *ProxyConfig) *Proxy {
+	obj := &Proxy{
+		Log: slog.Default(),
+		cfg: cfg,
+	}
+
+	for _, upstream := range cfg.Upstreams {
+		if target, err := url.Parse(upstream); err == nil {
+			obj.targets = append(obj.targets, target)
+		}
+	}
+
+	transport, _ := http.DefaultTransport.(*http.Transport)
+	transport = transport.Clone()
+	transport.DialContext = (&net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+	transport.IdleConnTimeout = cfg.IdleConnTimeout
+
+	obj.proxy = &httputil.ReverseProxy{
+		Rewrite:        obj.rewrite,
+		Transport:      &retryTransport{proxy: obj, transport: transport},
+		ModifyResponse: obj.modifyResponse,
+		ErrorHandler:   obj.handleError,
+	}
+
+	return obj
+}
+
+func (p *Proxy) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if len(p.targets) == 0 {
+		http.Error(resp, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+
+		return
+	}
+
+	p.proxy.ServeHTTP(resp, req)
+}
+
+// handleError answers requests that could not be forwarded.
+func (p *Proxy) handleError(resp http.ResponseWriter, req *http.Request, err error) {
+	status := http.StatusBadGateway
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		status = http.StatusGatewayTimeout
+	}
+
+	if !errors.Is(err, context.Canceled) {
+		p.Log.Warn("failed to proxy request", "method", req.Method, "path", req.URL.Path, "error", err)
+	}
+
+	resp.WriteHeader(status)
+}
+
+func (p *Proxy) modifyResponse(resp *http.Response) error {
+	p.cfg.ResponseHeaders.apply(resp.Header)
+
+	return nil
+}
+
+// pick returns the upstream of the next request.
+func (p *Proxy) pick() *url.URL {
+	return p.targets[(p.next.Add(1)-1)%uint64(len(p.targets))] //nolint:gosec // The length is never negative.
+}
+
+func (p *Proxy) rewrite(proxyReq *httputil.ProxyRequest) {
+	proxyReq.Out.URL = targetURL(p.pick(), proxyReq.In.URL)
+	proxyReq.SetXForwarded()
+
+	if p.cfg.PreserveHost {
+		proxyReq.Out.Host = proxyReq.In.Host
+	} else {
+		proxyReq.Out.Host = ""
+	}
+
+	p.cfg.RequestHeaders.apply(proxyReq.Out.Header)
+}
+
+// retryTransport sends requests that could not reach their upstream to the next ones.
+type retryTransport struct {
+	proxy     *Proxy
+	transport http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.transport.RoundTrip(req)
+
+	retryable := (req.Body == nil || req.Body == http.NoBody) &&
+		(req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions)
+
+	for attempt := 0; err != nil && retryable && attempt < t.proxy.cfg.Retries; attempt++ {
+		if req.Context().Err() != nil {
+			break
+		}
+
+		t.proxy.Log.Debug("retrying proxied request", "upstream", req.URL.Host, "error", err)
+
+		retry := req.Clone(req.Context())
+		retry.URL = retargetURL(t.proxy.pick(), req.URL)
+
+		resp, err = t.transport.RoundTrip(retry)
+	}
+
+	return resp, err //nolint:wrapcheck // The errors are handled by Proxy.handleError.
+}
+
+// retargetURL moves a URL produced by targetURL to the same path below another upstream. Upstreams are
+// replicas, so their paths are expected to match; only the scheme, host and user are replaced.
+func retargetURL(target, out *url.URL) *url.URL {
+	retargeted := *out
+	retargeted.Scheme, retargeted.Host, retargeted.User = target.Scheme, target.Host, target.User
+
+	return &retargeted
+}
+
+// targetURL joins the path and query of the incoming URL to those of the upstream.
+func targetURL(target, in *url.URL) *url.URL {
+	out := *in
+	out.Scheme, out.Host, out.User = target.Scheme, target.Host, target.User
+	out.Path = strings.TrimSuffix(target.Path, "/") + "/" + strings.TrimPrefix(in.Path, "/")
+	out.RawPath = ""
+
+	if target.RawQuery != "" && in.RawQuery != "" {
+		out.RawQuery = target.RawQuery + "&" + in.RawQuery
+	} else if target.RawQuery != "" {
+		out.RawQuery = target.RawQuery
+	}
+
+	return &out
+}
@@ -0,0 +1,120 @@
+package httpserver_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "upstream")
+		w.Header().Set("X-Upstream", "1")
+		_, _ = io.WriteString(w, r.Host+" "+r.URL.RequestURI()+" "+r.Header.Get("X-Tenant")+" "+
+			r.Header.Get("Cookie")+" "+r.Header.Get("X-Forwarded-Host"))
+	}))
+	t.Cleanup(upstream.Close)
+
+	// The first upstream refuses connections, so requests are retried on the second.
+	refused := httptest.NewServer(http.NotFoundHandler())
+	refused.Close()
+
+	cfg := &httpserver.ProxyConfig{}
+	cfg.SetDefaults()
+	cfg.Upstreams = []string{refused.URL + "/v1", upstream.URL + "/v1"}
+	cfg.Retries = 1
+	cfg.RequestHeaders = httpserver.HeaderRules{Remove: []string{"Cookie"}, Set: map[string]string{"X-Tenant": "a"}}
+	cfg.ResponseHeaders = httpserver.HeaderRules{Remove: []string{"Server"}}
+	require.NoError(t, cfg.Validate())
+
+	proxy := httpserver.NewProxy(cfg)
+	proxy.Log = &mockLogger{}
+
+	router := httpserver.NewRouter()
+	router.Handle("/api/", http.StripPrefix("/api", proxy))
+
+	tests := []struct {
+		name       string
+		method     string
+		wantBody   string
+		wantStatus int
+	}{
+		{
+			name:       "retried on next upstream",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantBody:   upstream.Listener.Addr().String() + " /v1/users?page=2 a  example.com",
+		},
+		{
+			name:       "not retried with unsafe method",
+			method:     http.MethodPost,
+			wantStatus: http.StatusBadGateway,
+		},
+	}
+
+	// The subtests run in order, as they rely on the round-robin order of the upstreams.
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) { //nolint:paralleltest // See above.
+			req := httptest.NewRequest(tt.method, "http://example.com/api/users?page=2", http.NoBody)
+			req.Header.Set("Cookie", "session=1")
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+				assert.Equal(t, "1", rec.Header().Get("X-Upstream"))
+				assert.Empty(t, rec.Header().Get("Server"))
+			}
+		})
+	}
+}
+
+func TestProxyConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr   error
+		name      string
+		upstreams []string
+		retries   int
+	}{
+		{name: "valid upstreams", upstreams: []string{"http://10.0.0.1:8080", "https://example.com/api"}},
+		{name: "missing upstreams", wantErr: httpserver.ErrMissingUpstreams},
+		{name: "relative upstream", upstreams: []string{"/api"}, wantErr: httpserver.ErrInvalidUpstream},
+		{name: "unsupported scheme", upstreams: []string{"ftp://example.com"}, wantErr: httpserver.ErrInvalidUpstream},
+		{
+			name:      "negative retries",
+			upstreams: []string{"http://example.com"},
+			retries:   -1,
+			wantErr:   httpserver.ErrInvalidRetries,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &httpserver.ProxyConfig{}
+			cfg.SetDefaults()
+			cfg.Upstreams = tt.upstreams
+			cfg.Retries = tt.retries
+
+			err := cfg.Validate()
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package httpserver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultProxyHeaderTimeout is the time a trusted proxy has to send the PROXY protocol header
+// if ListenerConfig.ProxyHeaderTimeout is not set.
+const DefaultProxyHeaderTimeout = 5 * time.Second
+
+const (
+	// proxyV1Prefix starts the human-readable header of version 1, which is at most 107 bytes long.
+	proxyV1Prefix    = "PROXY "
+	proxyV1MaxLength = 107
+
+	// proxyV2HeaderLength is the length of the fixed part of the binary header of version 2.
+	proxyV2HeaderLength = 16
+)
+
+var (
+	_ net.Listener = (*proxyListener)(nil)
+	_ net.Conn     = (*proxyConn)(nil)
+
+	ErrInvalidProxyHeader = errors.New("httpserver: invalid PROXY protocol header")
+
+	// proxyV2Signature starts the binary header of version 2.
+	proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+// proxyListener reads the PROXY protocol header of connections from trusted proxies.
+type proxyListener struct {
+	net.Listener
+
+	trusted []netip.Prefix
+	timeout time.Duration
+}
+
+// proxyConn reads the PROXY protocol header on its first use, so Accept itself is never blocked by a slow peer.
+// Reads and RemoteAddr wait for the header, so hooks http.Server calls from its accept loop, like ConnContext,
+// must not call them. A connection without valid header fails all reads.
+type proxyConn struct {
+	net.Conn
+
+	reader *bufio.Reader
+
+	// remote is the source address named by the header, or nil for connections of the proxy itself.
+	remote  net.Addr
+	err     error
+	timeout time.Duration
+	once    sync.Once
+}
+
+// newProxyListener wraps the listener, trusting the peers of the CIDRs. Invalid CIDRs are ignored,
+// as they are rejected by Config.Validate.
+func newProxyListener(listener net.Listener, cidrs []string, timeout time.Duration) *proxyListener {
+	obj := &proxyListener{Listener: listener, timeout: timeout}
+	if obj.timeout <= 0 {
+		obj.timeout = DefaultProxyHeaderTimeout
+	}
+
+	for _, cidr := range cidrs {
+		if prefix, err := netip.ParsePrefix(cidr); err == nil {
+			obj.trusted = append(obj.trusted, prefix.Masked())
+		}
+	}
+
+	return obj
+}
+
+//nolint:wrapcheck // Errors of the listener are passed through, so http.Server recognizes them.
+func (l *proxyListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	if !l.trusts(conn.RemoteAddr()) {
+		return conn, nil
+	}
+
+	return &proxyConn{Conn: conn, reader: bufio.NewReader(conn), timeout: l.timeout}, nil
+}
+
+// trusts reports whether the peer is a trusted proxy.
+func (l *proxyListener) trusts(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	ip := tcpAddr.AddrPort().Addr().Unmap()
+
+	for _, prefix := range l.trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *proxyConn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	return c.reader.Read(b) //nolint:wrapcheck // Errors of the connection are passed through unchanged.
+}
+
+// RemoteAddr returns the source address of the client named by the header, or that of the proxy
+// for health checks of the proxy itself. The first call waits up to the header timeout for the header.
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+
+	if c.remote != nil {
+		return c.remote
+	}
+
+	return c.Conn.RemoteAddr()
+}
+
+// readHeader reads the header within the timeout and records the source address it names.
+func (c *proxyConn) readHeader() {
+	_ = c.SetReadDeadline(time.Now().Add(c.timeout))
+	defer func() {
+		_ = c.SetReadDeadline(time.Time{})
+	}()
+
+	source, err := readProxyHeader(c.reader)
+	if err != nil {
+		c.err = fmt.Errorf("httpserver: failed to read PROXY protocol header from %s: %w", c.Conn.RemoteAddr(), err)
+
+		return
+	}
+
+	if source.IsValid() {
+		c.remote = net.TCPAddrFromAddrPort(source)
+	}
+}
+
+// readProxyHeader reads a header of version 1 or 2 and returns the source address it names. The address is
+// invalid for connections of the proxy itself, i.e. the LOCAL command of version 2 and UNKNOWN protocol of version 1.
+func readProxyHeader(reader *bufio.Reader) (netip.AddrPort, error) {
+	prefix, err := reader.Peek(len(proxyV1Prefix))
+	if err != nil {
+		return netip.AddrPort{}, err //nolint:wrapcheck // The error is wrapped by the caller.
+	}
+
+	if string(prefix) == proxyV1Prefix {
+		return readProxyV1Header(reader)
+	}
+
+	return readProxyV2Header(reader)
+}
+
+// readProxyV1Header reads a header like "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n".
+func readProxyV1Header(reader *bufio.Reader) (netip.AddrPort, error) {
+	var line []byte
+
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		char, err := reader.ReadByte()
+		if err != nil {
+			return netip.AddrPort{}, err //nolint:wrapcheck // The error is wrapped by the caller.
+		}
+
+		line = append(line, char)
+		if len(line) > proxyV1MaxLength {
+			return netip.AddrPort{}, fmt.Errorf("%w: header too long", ErrInvalidProxyHeader)
+		}
+	}
+
+	fields := strings.Fields(string(line))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return netip.AddrPort{}, nil
+	}
+
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return netip.AddrPort{}, fmt.Errorf("%w: malformed header %q", ErrInvalidProxyHeader, line)
+	}
+
+	ip, err := netip.ParseAddr(fields[2])
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("%w: %w", ErrInvalidProxyHeader, err)
+	}
+
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("%w: %w", ErrInvalidProxyHeader, err)
+	}
+
+	return netip.AddrPortFrom(ip, uint16(port)), nil
+}
+
+// readProxyV2Header reads a binary header. Only the addresses of TCP over IPv4 and IPv6 are taken,
+// the remaining address families and type-length-value fields are skipped.
+func readProxyV2Header(reader *bufio.Reader) (netip.AddrPort, error) {
+	header := make([]byte, proxyV2HeaderLength)
+
+	_, err := io.ReadFull(reader, header)
+	if err != nil {
+		return netip.AddrPort{}, err //nolint:wrapcheck // The error is wrapped by the caller.
+	}
+
+	if !bytes.Equal(header[:len(proxyV2Signature)], proxyV2Signature) || header[12]>>4 != 2 {
+		return netip.AddrPort{}, fmt.Errorf("%w: missing header", ErrInvalidProxyHeader)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+
+	_, err = io.ReadFull(reader, payload)
+	if err != nil {
+		return netip.AddrPort{}, err //nolint:wrapcheck // The error is wrapped by the caller.
+	}
+
+	// The LOCAL command is sent by the proxy itself, e.g. for health checks.
+	if header[12]&0x0f == 0 {
+		return netip.AddrPort{}, nil
+	}
+
+	switch header[13] {
+	case 0x11: // TCP over IPv4
+		if len(payload) < 12 {
+			return netip.AddrPort{}, fmt.Errorf("%w: short IPv4 addresses", ErrInvalidProxyHeader)
+		}
+
+		ip := netip.AddrFrom4([4]byte(payload[0:4]))
+
+		return netip.AddrPortFrom(ip, binary.BigEndian.Uint16(payload[8:10])), nil
+	case 0x21: // TCP over IPv6
+		if len(payload) < 36 {
+			return netip.AddrPort{}, fmt.Errorf("%w: short IPv6 addresses", ErrInvalidProxyHeader)
+		}
+
+		ip := netip.AddrFrom16([16]byte(payload[0:16]))
+
+		return netip.AddrPortFrom(ip, binary.BigEndian.Uint16(payload[32:34])), nil
+	default:
+		return netip.AddrPort{}, nil
+	}
+}
@@ -0,0 +1,110 @@
+package httpserver_test
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPServer_Start_ProxyProtocol(t *testing.T) {
+	t.Parallel()
+
+	v2Header := func(command byte, payload []byte) string {
+		header := []byte("\r\n\r\n\x00\r\nQUIT\n")
+		header = append(header, 0x20|command, 0x11)
+		header = binary.BigEndian.AppendUint16(header, uint16(len(payload)))
+
+		return string(append(header, payload...))
+	}
+
+	tests := []struct {
+		name       string
+		trusted    string
+		header     string
+		wantRemote string
+	}{
+		{
+			name:       "version 1",
+			trusted:    "127.0.0.0/8",
+			header:     "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n",
+			wantRemote: "192.0.2.1:56324",
+		},
+		{
+			name:       "version 1 IPv6",
+			trusted:    "127.0.0.0/8",
+			header:     "PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n",
+			wantRemote: "[2001:db8::1]:56324",
+		},
+		{
+			name:       "version 2",
+			trusted:    "127.0.0.0/8",
+			header:     v2Header(1, []byte{192, 0, 2, 1, 198, 51, 100, 1, 0xdc, 0x04, 0x01, 0xbb}),
+			wantRemote: "192.0.2.1:56324",
+		},
+		{
+			name:       "version 2 local command",
+			trusted:    "127.0.0.0/8",
+			header:     v2Header(0, nil),
+			wantRemote: "127.0.0.1:",
+		},
+		{
+			name:       "untrusted peer",
+			trusted:    "10.0.0.0/8",
+			wantRemote: "127.0.0.1:",
+		},
+		{
+			name:    "missing header",
+			trusted: "127.0.0.0/8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httpserver.New(
+				&httpserver.Config{
+					Host:     "127.0.0.1",
+					Listener: httpserver.ListenerConfig{ProxyProtocol: true, TrustedProxies: []string{tt.trusted}},
+				},
+				httpserver.WithLogger(&mockLogger{}),
+				httpserver.WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					_, _ = io.WriteString(w, "remote="+r.RemoteAddr)
+				})),
+			)
+
+			require.NoError(t, server.Start(context.Background()))
+			t.Cleanup(func() {
+				_ = server.Stop(context.Background())
+			})
+
+			conn, err := (&net.Dialer{}).DialContext(context.Background(), "tcp", server.Addr().String())
+			require.NoError(t, err)
+
+			_, err = io.WriteString(conn, tt.header+"GET / HTTP/1.1\r\nHost: localhost\r\nConnection: close\r\n\r\n")
+			require.NoError(t, err)
+
+			response, _ := io.ReadAll(conn)
+			require.NoError(t, conn.Close())
+
+			// Requests of trusted proxies without valid header are rejected before they reach the handler.
+			if tt.wantRemote == "" {
+				assert.True(t, strings.HasPrefix(string(response), "HTTP/1.1 400 "), string(response))
+				assert.NotContains(t, string(response), "remote=")
+
+				return
+			}
+
+			_, body, _ := strings.Cut(string(response), "remote=")
+			assert.True(t, strings.HasPrefix(body, tt.wantRemote), body)
+		})
+	}
+}
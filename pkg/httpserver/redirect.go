@@ -0,0 +1,54 @@
+package httpserver
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redirectHandler answers all requests with a permanent redirect to the same host, path and query over HTTPS
+// on the port returned by tlsPort, which is left out if it is the default port 443.
+func redirectHandler(tlsPort func() int) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		hostname := strings.Trim(req.Host, "[]")
+		if name, _, err := net.SplitHostPort(req.Host); err == nil {
+			hostname = name
+		}
+
+		host := hostname
+		if port := tlsPort(); port != 0 && port != 443 {
+			host = net.JoinHostPort(hostname, strconv.Itoa(port))
+		} else if strings.Contains(hostname, ":") {
+			host = "[" + hostname + "]"
+		}
+
+		target := "https://" + host + req.URL.RequestURI()
+
+		// Closing the connection keeps clients from sending further plain requests on it.
+		resp.Header().Set("Connection", "close")
+		http.Redirect(resp, req, target, http.StatusMovedPermanently)
+	})
+}
+
+// strictTransportSecurity adds the Strict-Transport-Security header of the configuration to responses over TLS.
+// Browsers ignore it on plain connections, which could be intercepted.
+func strictTransportSecurity(cfg *RedirectConfig, handler http.Handler) http.Handler {
+	value := "max-age=" + strconv.FormatInt(int64(cfg.HSTSMaxAge/time.Second), 10)
+	if cfg.HSTSIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+
+	if cfg.HSTSPreload {
+		value += "; preload"
+	}
+
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.TLS != nil {
+			resp.Header().Set("Strict-Transport-Security", value)
+		}
+
+		handler.ServeHTTP(resp, req)
+	})
+}
@@ -0,0 +1,186 @@
+package httpserver
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+)
+
+var (
+	_ http.ResponseWriter = (*ResponseWriter)(nil)
+	_ http.Flusher        = (*ResponseWriter)(nil)
+	_ http.Hijacker       = (*ResponseWriter)(nil)
+	_ http.Pusher         = (*ResponseWriter)(nil)
+	_ io.ReaderFrom       = (*ResponseWriter)(nil)
+)
+
+// ResponseWriter wraps an http.ResponseWriter and records the status code and the number of body bytes written.
+// It passes http.Flusher, http.Hijacker, http.Pusher and io.ReaderFrom through to the wrapped writer,
+// so streaming responses and protocol upgrades keep working through a middleware chain.
+// In buffered mode, the response is held back until Commit is called.
+type ResponseWriter struct {
+	http.ResponseWriter
+
+	// buffer holds the response body in buffered mode until it is committed.
+	buffer *bytes.Buffer
+
+	size        int64
+	status      int
+	wroteHeader bool
+}
+
+// NewResponseWriter wraps the given writer and records the status and size of the response.
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	return &ResponseWriter{ResponseWriter: w}
+}
+
+// NewBufferedResponseWriter wraps the given writer and holds back the status and body until Commit is called.
+// This allows middlewares to inspect or replace a response, e.g. to compute an ETag.
+func NewBufferedResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	return &ResponseWriter{ResponseWriter: w, buffer: &bytes.Buffer{}}
+}
+
+// Body returns the response body held back in buffered mode, or nil if the writer is not buffering.
+func (w *ResponseWriter) Body() []byte {
+	if w.buffer == nil {
+		return nil
+	}
+
+	return w.buffer.Bytes()
+}
+
+// Commit sends the buffered status and body to the wrapped writer and ends buffered mode.
+// It is a no-op if the writer is not buffering.
+func (w *ResponseWriter) Commit() error {
+	if w.buffer == nil {
+		return nil
+	}
+
+	buffer := w.buffer
+	w.buffer = nil
+
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+
+	_, err := buffer.WriteTo(w.ResponseWriter)
+	if err != nil {
+		return fmt.Errorf("httpserver: failed to commit buffered response: %w", err)
+	}
+
+	return nil
+}
+
+// Flush commits a buffered response and flushes the wrapped writer if it supports flushing.
+func (w *ResponseWriter) Flush() {
+	if w.Commit() != nil {
+		return
+	}
+
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack lets the caller take over the connection of the wrapped writer.
+// It returns an error wrapping http.ErrNotSupported if the wrapped writer cannot be hijacked.
+func (w *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	//nolint:wrapcheck // The caller expects the errors of the underlying connection.
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Push initiates an HTTP/2 server push if the wrapped writer supports it.
+func (w *ResponseWriter) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := w.ResponseWriter.(http.Pusher); ok {
+		//nolint:wrapcheck // The caller expects the errors of the underlying connection.
+		return pusher.Push(target, opts)
+	}
+
+	return http.ErrNotSupported
+}
+
+// ReadFrom copies the reader into the response, using the wrapped writer's io.ReaderFrom if available.
+func (w *ResponseWriter) ReadFrom(src io.Reader) (int64, error) {
+	dst, ok := w.ResponseWriter.(io.ReaderFrom)
+	if !ok || w.buffer != nil {
+		// Hide ReadFrom of the wrapper from io.Copy to avoid infinite recursion; Write records the size.
+		//nolint:wrapcheck // The caller expects the errors of the underlying connection.
+		return io.Copy(struct{ io.Writer }{w}, src)
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	n, err := dst.ReadFrom(src)
+	w.size += n
+
+	//nolint:wrapcheck // The caller expects the errors of the underlying connection.
+	return n, err
+}
+
+// Size returns the number of body bytes written so far.
+func (w *ResponseWriter) Size() int64 {
+	return w.size
+}
+
+// Status returns the status code of the response, which is http.StatusOK if the body was written without a status.
+// It returns 0 if neither a status nor a body has been written yet.
+func (w *ResponseWriter) Status() int {
+	return w.status
+}
+
+// Unwrap returns the wrapped writer, so http.ResponseController can reach its optional interfaces.
+func (w *ResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Write writes the body and records the number of bytes written.
+func (w *ResponseWriter) Write(data []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+
+	var (
+		n   int
+		err error
+	)
+
+	if w.buffer != nil {
+		n, err = w.buffer.Write(data)
+	} else {
+		n, err = w.ResponseWriter.Write(data)
+	}
+
+	w.size += int64(n)
+
+	//nolint:wrapcheck // The caller expects the errors of the underlying connection.
+	return n, err
+}
+
+// WriteHeader records and sends the status code. Only the first final status code is recorded;
+// informational 1xx responses are passed through unless buffering.
+func (w *ResponseWriter) WriteHeader(code int) {
+	if code >= 100 && code <= 199 && code != http.StatusSwitchingProtocols {
+		if w.buffer == nil {
+			w.ResponseWriter.WriteHeader(code)
+		}
+
+		return
+	}
+
+	if w.wroteHeader {
+		return
+	}
+
+	w.status = code
+	w.wroteHeader = true
+
+	if w.buffer == nil {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+// Written reports whether the status code has been written.
+func (w *ResponseWriter) Written() bool {
+	return w.wroteHeader
+}
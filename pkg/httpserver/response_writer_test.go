@@ -0,0 +1,129 @@
+package httpserver_test
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		write      func(w *httpserver.ResponseWriter)
+		name       string
+		wantBody   string
+		wantStatus int
+		wantSize   int64
+	}{
+		{
+			name:       "implicit status",
+			write:      func(w *httpserver.ResponseWriter) { _, _ = w.Write([]byte("hello")) },
+			wantStatus: http.StatusOK,
+			wantSize:   5,
+			wantBody:   "hello",
+		},
+		{
+			name: "explicit status is recorded once",
+			write: func(w *httpserver.ResponseWriter) {
+				w.WriteHeader(http.StatusEarlyHints)
+				w.WriteHeader(http.StatusNotFound)
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "read from",
+			write: func(w *httpserver.ResponseWriter) {
+				_, _ = w.ReadFrom(strings.NewReader("streamed"))
+			},
+			wantStatus: http.StatusOK,
+			wantSize:   8,
+			wantBody:   "streamed",
+		},
+		{
+			name:  "nothing written",
+			write: func(_ *httpserver.ResponseWriter) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			w := httpserver.NewResponseWriter(rec)
+			tt.write(w)
+
+			assert.Equal(t, tt.wantStatus, w.Status())
+			assert.Equal(t, tt.wantSize, w.Size())
+			assert.Equal(t, tt.wantBody, rec.Body.String())
+			assert.Nil(t, w.Body())
+		})
+	}
+}
+
+func TestResponseWriter_Buffered(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+	w := httpserver.NewBufferedResponseWriter(rec)
+
+	w.WriteHeader(http.StatusCreated)
+	_, err := w.Write([]byte("held "))
+	require.NoError(t, err)
+	_, err = w.ReadFrom(strings.NewReader("back"))
+	require.NoError(t, err)
+
+	assert.False(t, rec.Flushed)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, "held back", string(w.Body()))
+	assert.Equal(t, int64(9), w.Size())
+
+	w.Header().Set("ETag", `"abc"`)
+	w.Flush()
+
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, `"abc"`, rec.Header().Get("ETag"))
+	assert.Equal(t, "held back", rec.Body.String())
+	require.NoError(t, w.Commit())
+
+	empty := httptest.NewRecorder()
+	require.NoError(t, httpserver.NewBufferedResponseWriter(empty).Commit())
+	assert.Equal(t, http.StatusOK, empty.Code)
+}
+
+func TestResponseWriter_Passthrough(t *testing.T) {
+	t.Parallel()
+
+	w := httpserver.NewResponseWriter(httptest.NewRecorder())
+
+	_, _, err := w.Hijack()
+	require.ErrorIs(t, err, http.ErrNotSupported)
+	require.ErrorIs(t, w.Push("/", nil), http.ErrNotSupported)
+
+	hijacker := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	_, _, err = httpserver.NewResponseWriter(httpserver.NewResponseWriter(hijacker)).Hijack()
+	require.NoError(t, err)
+	assert.True(t, hijacker.hijacked)
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+
+	hijacked bool
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.hijacked = true
+
+	return nil, nil, nil
+}
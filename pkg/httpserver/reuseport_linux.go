@@ -0,0 +1,4 @@
+package httpserver
+
+// soReusePort is the value of SO_REUSEPORT on Linux, which package syscall does not define.
+const soReusePort = 0xf
@@ -0,0 +1,10 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package httpserver
+
+import "syscall"
+
+// reusePort fails, as SO_REUSEPORT is not supported on this platform.
+func reusePort(_ syscall.RawConn) error {
+	return ErrReusePortUnsupported
+}
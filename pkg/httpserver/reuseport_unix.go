@@ -0,0 +1,27 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package httpserver
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// reusePort sets SO_REUSEPORT on the socket, so several processes can listen on the same address.
+func reusePort(conn syscall.RawConn) error {
+	var sockErr error
+
+	err := conn.Control(func(fd uintptr) {
+		descriptor := int(fd) //nolint:gosec // File descriptors fit into int.
+		sockErr = syscall.SetsockoptInt(descriptor, syscall.SOL_SOCKET, soReusePort, 1)
+	})
+	if err != nil {
+		return fmt.Errorf("httpserver: failed to access socket: %w", err)
+	}
+
+	if sockErr != nil {
+		return fmt.Errorf("httpserver: failed to set SO_REUSEPORT: %w", sockErr)
+	}
+
+	return nil
+}
@@ -1,8 +1,12 @@
 package httpserver
 
 import (
+	"io/fs"
 	"net/http"
 	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 type Middleware func(http.Handler) http.Handler
@@ -10,50 +14,241 @@ type Middleware func(http.Handler) http.Handler
 type Router struct {
 	*http.ServeMux
 
+	// handler is the ServeMux wrapped by the global middleware chain.
+	// It is rebuilt on every call to Use and swapped atomically, so ServeHTTP never composes the chain itself.
+	handler atomic.Pointer[http.Handler]
+
+	// routes is shared by the router and its groups, like the ServeMux.
+	routes *routeRegistry
+
+	// prefix is prepended to the paths of all patterns registered on a router created by Route.
+	prefix string
+
 	globalChain []Middleware
 	routeChain  []Middleware
+
+	// mutex serializes modifications of the global middleware chain.
+	mutex sync.Mutex
+
 	isSubRouter bool
 }
 
 func NewRouter() *Router {
-	return &Router{ServeMux: http.NewServeMux()}
+	return &Router{ServeMux: http.NewServeMux(), routes: newRouteRegistry()}
+}
+
+// Delete registers the handler for DELETE requests matching the pattern, see Get.
+func (r *Router) Delete(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	r.handleMethod(http.MethodDelete, pattern, handler, middlewares)
+}
+
+// Get registers the handler for GET requests matching the pattern, which must not name a method itself.
+// Like with ServeMux, GET patterns match HEAD requests as well. The middlewares apply like with Handle.
+func (r *Router) Get(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	r.handleMethod(http.MethodGet, pattern, handler, middlewares)
 }
 
 func (r *Router) Group(configure func(r *Router)) {
 	subRouter := &Router{
 		routeChain:  slices.Clone(r.routeChain),
+		prefix:      r.prefix,
 		isSubRouter: true,
 		ServeMux:    r.ServeMux,
+		routes:      r.routes,
 	}
 	configure(subRouter)
 }
 
-func (r *Router) Handle(pattern string, handler http.Handler) {
-	for _, middleware := range slices.Backward(r.routeChain) {
+// Handle registers the handler for the pattern behind the middlewares of the router. The trailing middlewares
+// apply to this route only, innermost, e.g. r.Handle("POST /admin", handler, basicAuth).
+// Wildcards may be constrained by a regular expression matching the whole value, e.g. {id:[0-9]+}.
+// Requests whose values do not match are answered with 404 before any middleware of the route runs.
+// Like with ServeMux, patterns differing only in their constraints conflict. Like ServeMux, Handle panics on
+// conflicting or invalid patterns, but with an error wrapping ErrRouteConflict or ErrInvalidRoute that names the
+// locations of the calls registering the routes involved.
+func (r *Router) Handle(pattern string, handler http.Handler, middlewares ...Middleware) {
+	for _, middleware := range slices.Backward(slices.Concat(r.routeChain, middlewares)) {
 		handler = middleware(handler)
 	}
 
-	r.ServeMux.Handle(pattern, handler)
+	r.register(registration{pattern: pattern, site: callSite()}, handler)
+}
+
+func (r *Router) HandleFunc(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	r.Handle(pattern, handler, middlewares...)
+}
+
+// Head registers the handler for HEAD requests matching the pattern, see Get.
+func (r *Router) Head(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	r.handleMethod(http.MethodHead, pattern, handler, middlewares)
+}
+
+// Mount delegates all requests below the path prefix to the handler with the prefix stripped, e.g. another
+// Router or a third-party handler. The middlewares of the router apply; requests for the prefix itself are
+// redirected to the prefix with trailing slash.
+func (r *Router) Mount(prefix string, handler http.Handler) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	r.Handle(prefix+"/", http.StripPrefix(r.prefix+prefix, handler))
+}
+
+// Patch registers the handler for PATCH requests matching the pattern, see Get.
+func (r *Router) Patch(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	r.handleMethod(http.MethodPatch, pattern, handler, middlewares)
+}
+
+// Post registers the handler for POST requests matching the pattern, see Get.
+func (r *Router) Post(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	r.handleMethod(http.MethodPost, pattern, handler, middlewares)
+}
+
+// Put registers the handler for PUT requests matching the pattern, see Get.
+func (r *Router) Put(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	r.handleMethod(http.MethodPut, pattern, handler, middlewares)
 }
 
-func (r *Router) HandleFunc(pattern string, handler http.HandlerFunc) {
-	r.Handle(pattern, handler)
+// Route creates a group like Group whose patterns are registered below the path prefix, e.g.
+// r.Route("/api/v1", ...) registers "GET /users" as "GET /api/v1/users". Routes nest, combining their prefixes
+// and middlewares. The pattern "/" of the group matches the whole subtree below the prefix.
+func (r *Router) Route(prefix string, configure func(r *Router)) {
+	r.Group(func(subRouter *Router) {
+		subRouter.prefix += strings.TrimSuffix(prefix, "/")
+		configure(subRouter)
+	})
 }
 
 func (r *Router) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
-	var handler http.Handler = r.ServeMux
+	if handler := r.handler.Load(); handler != nil {
+		(*handler).ServeHTTP(resp, req)
 
-	for _, middleware := range slices.Backward(r.globalChain) {
-		handler = middleware(handler)
+		return
 	}
 
-	handler.ServeHTTP(resp, req)
+	r.serveMux(resp, req)
+}
+
+// Static serves the files of the file system below the path prefix for GET and HEAD requests, e.g. the embedded
+// assets of a frontend, see StaticOptions.
+func (r *Router) Static(prefix string, fsys fs.FS, opts StaticOptions) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	r.Handle("GET "+prefix+"/", http.StripPrefix(r.prefix+prefix, newStaticHandler(fsys, opts)))
 }
 
 func (r *Router) Use(middlewares ...Middleware) {
 	if r.isSubRouter {
 		r.routeChain = append(r.routeChain, middlewares...)
+
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Copy on write, so a chain captured by a concurrent request is never modified.
+	r.globalChain = append(slices.Clip(r.globalChain), middlewares...)
+
+	var handler http.Handler = http.HandlerFunc(r.serveMux)
+	for _, middleware := range slices.Backward(r.globalChain) {
+		handler = middleware(handler)
+	}
+
+	r.handler.Store(&handler)
+}
+
+// handleMethod registers the handler for the method by prefixing the pattern with it.
+func (r *Router) handleMethod(method, pattern string, handler http.HandlerFunc, middlewares []Middleware) {
+	r.Handle(method+" "+pattern, handler, middlewares...)
+}
+
+// register registers the handler for the constrained and prefixed pattern of the route on the ServeMux. Only the
+// panics of the registration itself are explained, those of middlewares propagate unchanged.
+func (r *Router) register(route registration, handler http.Handler) {
+	defer r.routes.explainPanic(route)
+
+	pattern, constrain := constrainPattern(prefixPattern(r.prefix, route.pattern))
+	if constrain != nil {
+		handler = constrain(handler)
+	}
+
+	r.ServeMux.Handle(pattern, handler)
+	r.routes.add(pattern, route)
+}
+
+// serveMux dispatches the request to the ServeMux. Requests matching a path but none of its methods are answered
+// with 405 and an Allow header listing OPTIONS as well, since OPTIONS requests for paths without an OPTIONS route
+// are answered with 204 and the same Allow header.
+func (r *Router) serveMux(resp http.ResponseWriter, req *http.Request) {
+	handler, pattern := r.ServeMux.Handler(req)
+	if pattern != "" {
+		r.ServeMux.ServeHTTP(resp, req)
+
+		return
+	}
+
+	// Without pattern, the handler answers with 404, 405 or a redirect. Only 405 carries the allowed methods.
+	capture := &headerCapture{header: http.Header{}}
+	handler.ServeHTTP(capture, req)
+
+	if capture.status != http.StatusMethodNotAllowed {
+		r.ServeMux.ServeHTTP(resp, req)
+
+		return
+	}
+
+	methods := strings.Split(capture.header.Get("Allow"), ", ")
+	if !slices.Contains(methods, http.MethodOptions) {
+		methods = append(methods, http.MethodOptions)
+	}
+
+	resp.Header().Set("Allow", strings.Join(methods, ", "))
+
+	if req.Method == http.MethodOptions {
+		resp.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	http.Error(resp, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+// prefixPattern inserts the prefix in front of the path of the ServeMux pattern, keeping its method and host.
+func prefixPattern(prefix, pattern string) string {
+	if prefix == "" {
+		return pattern
+	}
+
+	method, rest, found := strings.Cut(pattern, " ")
+	if !found {
+		method, rest = "", pattern
 	} else {
-		r.globalChain = append(r.globalChain, middlewares...)
+		method += " "
+		rest = strings.TrimLeft(rest, " \t")
+	}
+
+	host, path, _ := strings.Cut(rest, "/")
+
+	return method + host + prefix + "/" + path
+}
+
+// headerCapture records the header and status of a response, discarding its body.
+type headerCapture struct {
+	header http.Header
+	status int
+}
+
+func (c *headerCapture) Header() http.Header {
+	return c.header
+}
+
+func (c *headerCapture) Write(data []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+
+	return len(data), nil
+}
+
+func (c *headerCapture) WriteHeader(code int) {
+	if c.status == 0 {
+		c.status = code
 	}
 }
@@ -0,0 +1,420 @@
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_Use(t *testing.T) {
+	t.Parallel()
+
+	router := httpserver.NewRouter()
+	router.Use(tagMiddleware("global1"))
+	router.Group(func(r *httpserver.Router) {
+		r.Use(tagMiddleware("route"))
+		r.HandleFunc("GET /grouped", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("handler"))
+		})
+	})
+	router.HandleFunc("GET /plain", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("handler"))
+	})
+
+	// Middlewares added after registration still apply globally.
+	router.Use(tagMiddleware("global2"))
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "grouped route", path: "/grouped", want: "global1,global2,route"},
+		{name: "plain route", path: "/plain", want: "global1,global2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, http.NoBody))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.want, strings.Join(rec.Header().Values("X-Chain"), ","))
+		})
+	}
+}
+
+func TestRouter_Handle(t *testing.T) {
+	t.Parallel()
+
+	router := httpserver.NewRouter()
+	router.Use(tagMiddleware("global"))
+	router.Group(func(r *httpserver.Router) {
+		r.Use(tagMiddleware("group"))
+		r.HandleFunc("GET /guarded", func(http.ResponseWriter, *http.Request) {}, tagMiddleware("a"), tagMiddleware("b"))
+		r.Post("/posted", func(http.ResponseWriter, *http.Request) {}, tagMiddleware("c"))
+		r.HandleFunc("GET /open", func(http.ResponseWriter, *http.Request) {})
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   string
+	}{
+		{name: "route middlewares", method: http.MethodGet, path: "/guarded", want: "global,group,a,b"},
+		{name: "method helper", method: http.MethodPost, path: "/posted", want: "global,group,c"},
+		{name: "without route middlewares", method: http.MethodGet, path: "/open", want: "global,group"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, http.NoBody))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.want, strings.Join(rec.Header().Values("X-Chain"), ","))
+		})
+	}
+}
+
+func TestRouter_Methods(t *testing.T) {
+	t.Parallel()
+
+	router := httpserver.NewRouter()
+	register := map[string]func(string, http.HandlerFunc, ...httpserver.Middleware){
+		http.MethodDelete: router.Delete,
+		http.MethodGet:    router.Get,
+		http.MethodHead:   router.Head,
+		http.MethodPatch:  router.Patch,
+		http.MethodPost:   router.Post,
+		http.MethodPut:    router.Put,
+	}
+
+	for method, handle := range register {
+		handle("/"+strings.ToLower(method), func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Method", r.Method)
+		})
+	}
+
+	for method := range register {
+		t.Run(method, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(method, "/"+strings.ToLower(method), http.NoBody))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, method, rec.Header().Get("X-Method"))
+
+			rec = httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodConnect, "/"+strings.ToLower(method), http.NoBody))
+			assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		})
+	}
+}
+
+func TestRouter_Options(t *testing.T) {
+	t.Parallel()
+
+	router := httpserver.NewRouter()
+	router.Get("/items", func(http.ResponseWriter, *http.Request) {})
+	router.Post("/items", func(http.ResponseWriter, *http.Request) {})
+	router.HandleFunc("OPTIONS /custom", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	router.Put("/custom", func(http.ResponseWriter, *http.Request) {})
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantAllow  string
+		wantStatus int
+	}{
+		{
+			name:       "automatic OPTIONS",
+			method:     http.MethodOptions,
+			path:       "/items",
+			wantStatus: http.StatusNoContent,
+			wantAllow:  "GET, HEAD, POST, OPTIONS",
+		},
+		{
+			name:       "method not allowed",
+			method:     http.MethodDelete,
+			path:       "/items",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantAllow:  "GET, HEAD, POST, OPTIONS",
+		},
+		{name: "OPTIONS route", method: http.MethodOptions, path: "/custom", wantStatus: http.StatusOK},
+		{
+			name:       "method not allowed with OPTIONS route",
+			method:     http.MethodGet,
+			path:       "/custom",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantAllow:  "OPTIONS, PUT",
+		},
+		{name: "unknown path", method: http.MethodOptions, path: "/missing", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, http.NoBody))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantAllow, rec.Header().Get("Allow"))
+		})
+	}
+}
+
+func TestRouter_Route(t *testing.T) {
+	t.Parallel()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Pattern))
+	}
+
+	router := httpserver.NewRouter()
+	router.Route("/api/v1/", func(r *httpserver.Router) {
+		r.Use(tagMiddleware("api"))
+		r.Get("/users/{id}", handler)
+		r.HandleFunc("/", handler)
+
+		r.Route("/admin", func(r *httpserver.Router) {
+			r.Use(tagMiddleware("admin"))
+			r.HandleFunc("POST /stats", handler)
+		})
+	})
+	router.Get("/users/{id}", handler)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		wantPattern string
+		wantChain   string
+	}{
+		{
+			name:        "prefixed route",
+			method:      http.MethodGet,
+			path:        "/api/v1/users/1",
+			wantPattern: "GET /api/v1/users/{id}",
+			wantChain:   "api",
+		},
+		{
+			name:        "subtree of prefix",
+			method:      http.MethodGet,
+			path:        "/api/v1/unknown",
+			wantPattern: "/api/v1/",
+			wantChain:   "api",
+		},
+		{
+			name:        "nested route",
+			method:      http.MethodPost,
+			path:        "/api/v1/admin/stats",
+			wantPattern: "POST /api/v1/admin/stats",
+			wantChain:   "api,admin",
+		},
+		{name: "route outside prefix", method: http.MethodGet, path: "/users/1", wantPattern: "GET /users/{id}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, http.NoBody))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.wantPattern, rec.Body.String())
+			assert.Equal(t, tt.wantChain, strings.Join(rec.Header().Values("X-Chain"), ","))
+		})
+	}
+}
+
+func TestRouter_Mount(t *testing.T) {
+	t.Parallel()
+
+	module := httpserver.NewRouter()
+	module.Use(tagMiddleware("module"))
+	module.Get("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	})
+
+	router := httpserver.NewRouter()
+	router.Use(tagMiddleware("global"))
+	router.Route("/api", func(r *httpserver.Router) {
+		r.Use(tagMiddleware("api"))
+		r.Mount("/shop/", module)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/shop/items/1", http.NoBody))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "/items/1", rec.Body.String())
+	assert.Equal(t, "global,api,module", strings.Join(rec.Header().Values("X-Chain"), ","))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/shop", http.NoBody))
+	assert.Equal(t, http.StatusTemporaryRedirect, rec.Code)
+	assert.Equal(t, "/api/shop/", rec.Header().Get("Location"))
+}
+
+func TestRouter_Handle_Panics(t *testing.T) {
+	t.Parallel()
+
+	handler := func(http.ResponseWriter, *http.Request) {}
+
+	tests := []struct {
+		configure func(r *httpserver.Router)
+		wantErr   error
+		name      string
+	}{
+		{
+			name: "distinct routes",
+			configure: func(r *httpserver.Router) {
+				r.Get("/users/{id}", handler)
+				r.Post("/users/{id}", handler)
+			},
+		},
+		{
+			name: "conflicting routes",
+			configure: func(r *httpserver.Router) {
+				r.Get("/users/{id}", handler)
+				r.Get("/users/{name}", handler)
+			},
+			wantErr: httpserver.ErrRouteConflict,
+		},
+		{
+			name: "conflicting routes of groups",
+			configure: func(r *httpserver.Router) {
+				r.Get("/api/users", handler)
+				r.Route("/api", func(r *httpserver.Router) {
+					r.Get("/users", handler)
+				})
+			},
+			wantErr: httpserver.ErrRouteConflict,
+		},
+		{
+			name: "invalid pattern",
+			configure: func(r *httpserver.Router) {
+				r.Get("/users/{id", handler)
+			},
+			wantErr: httpserver.ErrInvalidRoute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := registrationPanic(func() { tt.configure(httpserver.NewRouter()) })
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Contains(t, err.Error(), "router_test.go:")
+			assert.NotContains(t, err.Error(), "router.go:")
+		})
+	}
+
+	t.Run("both locations", func(t *testing.T) {
+		t.Parallel()
+
+		router := httpserver.NewRouter()
+		router.Get("/users/{id}", handler)
+
+		err := registrationPanic(func() { router.Get("/users/{name}", handler) })
+		require.ErrorIs(t, err, httpserver.ErrRouteConflict)
+		assert.Contains(t, err.Error(), `"GET /users/{name}" registered at `)
+		assert.Contains(t, err.Error(), `"GET /users/{id}" registered at `)
+		assert.Equal(t, 2, strings.Count(err.Error(), "router_test.go:"))
+	})
+
+	t.Run("unknown conflict message", func(t *testing.T) {
+		t.Parallel()
+
+		err := httpserver.ExplainRoutePanic("GET /users/{id}", "GET /users/{name}",
+			`pattern "GET /users/{name}" overlaps "GET /users/{id}"`)
+		require.ErrorIs(t, err, httpserver.ErrRouteConflict)
+		assert.Contains(t, err.Error(), `"GET /users/{name}" registered at new.go:1`)
+		assert.Contains(t, err.Error(), `"GET /users/{id}" registered at existing.go:1`)
+		assert.Contains(t, err.Error(), `overlaps "GET /users/{id}"`)
+	})
+
+	t.Run("unknown message", func(t *testing.T) {
+		t.Parallel()
+
+		err := httpserver.ExplainRoutePanic("GET /users/{id}", "GET /users/{name}", "registration failed")
+		require.ErrorIs(t, err, httpserver.ErrInvalidRoute)
+		assert.Contains(t, err.Error(), `"GET /users/{name}" registered at new.go:1: registration failed`)
+	})
+
+	t.Run("middleware panics propagate", func(t *testing.T) {
+		t.Parallel()
+
+		failing := func(http.Handler) http.Handler { panic("middleware") }
+
+		assert.PanicsWithValue(t, "middleware", func() {
+			httpserver.NewRouter().Get("/users", handler, failing)
+		})
+	})
+}
+
+// registrationPanic returns the error the function panicked with, or nil if it did not panic.
+func registrationPanic(register func()) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = recovered.(error) //nolint:forcetypeassert // Router panics with errors only.
+		}
+	}()
+
+	register()
+
+	return nil
+}
+
+func BenchmarkRouter_ServeHTTP(b *testing.B) {
+	router := httpserver.NewRouter()
+	for range 5 {
+		router.Use(tagMiddleware(""))
+	}
+
+	router.HandleFunc("GET /", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+func tagMiddleware(tag string) httpserver.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if tag != "" {
+				w.Header().Add("X-Chain", tag)
+			}
+
+			next.ServeHTTP(w, req)
+		})
+	}
+}
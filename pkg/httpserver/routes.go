@@ -0,0 +1,111 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrRouteConflict = errors.New("httpserver: conflicting routes")
+	ErrInvalidRoute  = errors.New("httpserver: invalid route")
+
+	// conflictPattern extracts the registered pattern from the panic of ServeMux on conflicting patterns.
+	conflictPattern = regexp.MustCompile(`conflicts with pattern ("(?:[^"\\]|\\.)*")`)
+)
+
+// registration is a route as registered by the application.
+type registration struct {
+	pattern string
+
+	// site is the location of the call registering the route outside of this package, e.g. "main.go:42".
+	site string
+}
+
+// routeRegistry records the routes of a router and its groups, so failed registrations are reported with the
+// locations of the calls involved instead of those inside the router.
+type routeRegistry struct {
+	// routes maps the patterns registered on the ServeMux to their registration.
+	routes map[string]registration
+	mutex  sync.Mutex
+}
+
+func newRouteRegistry() *routeRegistry {
+	return &routeRegistry{routes: map[string]registration{}}
+}
+
+// add records the successful registration of the ServeMux pattern.
+func (r *routeRegistry) add(pattern string, route registration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.routes[pattern] = route
+}
+
+// explainPanic panics again with the location of the route if registering it on the ServeMux panicked, and for
+// conflicts with the location of the route it conflicts with. It must be deferred directly.
+func (r *routeRegistry) explainPanic(route registration) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	message := fmt.Sprint(recovered)
+
+	existing, reason, found := r.conflict(message)
+	if found {
+		panic(fmt.Errorf("%w: pattern %q registered at %s conflicts with pattern %q registered at %s: %s",
+			ErrRouteConflict, route.pattern, route.site, existing.pattern, existing.site, reason))
+	}
+
+	panic(fmt.Errorf("%w: pattern %q registered at %s: %s", ErrInvalidRoute, route.pattern, route.site, message))
+}
+
+// conflict returns the registered route the panic message of ServeMux reports a conflict with, and the reason
+// given. The wording of these messages is not guaranteed, so if conflictPattern does not match, the route is the
+// one whose pattern the message quotes and the reason is the whole message. The mutex must be held.
+func (r *routeRegistry) conflict(message string) (registration, string, bool) {
+	if match := conflictPattern.FindStringSubmatch(message); match != nil {
+		registered, err := strconv.Unquote(match[1])
+		if existing, found := r.routes[registered]; err == nil && found {
+			_, reason, _ := strings.Cut(message, "\n")
+
+			return existing, strings.TrimSpace(reason), true
+		}
+	}
+
+	for pattern, existing := range r.routes {
+		if strings.Contains(message, strconv.Quote(pattern)) {
+			return existing, message, true
+		}
+	}
+
+	return registration{}, "", false
+}
+
+// callSite returns the location of the first caller outside of this package.
+func callSite() string {
+	prefix := reflect.TypeFor[Router]().PkgPath() + "."
+
+	callers := make([]uintptr, 32)
+	frames := runtime.CallersFrames(callers[:runtime.Callers(2, callers)])
+
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, prefix) {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+
+		if !more {
+			return "unknown location"
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package httpserver
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultStaticMaxAge is how long clients cache assets served by Router.Static if StaticOptions.MaxAge is not set.
+const DefaultStaticMaxAge = time.Hour
+
+// indexFile is served for directories and as fallback of single-page applications.
+const indexFile = "index.html"
+
+// StaticOptions defines how Router.Static serves files. The zero value serves files and index.html of
+// directories, caches assets for DefaultStaticMaxAge and answers everything else with 404.
+type StaticOptions struct {
+	// MaxAge represents how long clients cache assets other than HTML, which is always revalidated,
+	// so deployments take effect immediately. Zero means DefaultStaticMaxAge, negative values disable caching.
+	MaxAge time.Duration
+
+	// Immutable marks cached assets as immutable, e.g. if their names contain a hash of their content.
+	Immutable bool
+
+	// Browse lists the files of directories without index.html.
+	Browse bool
+
+	// Fallback serves index.html of the root for paths matching no file, so the routes of single-page
+	// applications are handled by the client. Paths with an extension, e.g. of missing assets, are still
+	// answered with 404.
+	Fallback bool
+}
+
+// staticHandler serves the files of a file system, e.g. an embed.FS, with the paths relative to its root.
+type staticHandler struct {
+	fsys  fs.FS
+	files http.Handler
+	opts  StaticOptions
+}
+
+func newStaticHandler(fsys fs.FS, opts StaticOptions) *staticHandler {
+	return &staticHandler{fsys: fsys, files: http.FileServerFS(fsys), opts: opts}
+}
+
+func (h *staticHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	name := strings.TrimPrefix(path.Clean("/"+req.URL.Path), "/")
+	if name == "" {
+		name = "."
+	}
+
+	info, err := fs.Stat(h.fsys, name)
+
+	switch {
+	case err != nil:
+		if h.opts.Fallback && path.Ext(name) == "" {
+			h.serveFallback(resp, req)
+
+			return
+		}
+	case info.IsDir():
+		if _, err = fs.Stat(h.fsys, path.Join(name, indexFile)); err != nil && !h.opts.Browse {
+			if h.opts.Fallback {
+				h.serveFallback(resp, req)
+			} else {
+				http.NotFound(resp, req)
+			}
+
+			return
+		}
+
+		resp.Header().Set("Cache-Control", "no-cache")
+	default:
+		resp.Header().Set("Cache-Control", h.cacheControl(name))
+	}
+
+	h.files.ServeHTTP(resp, req)
+}
+
+// cacheControl returns the Cache-Control header of the file.
+func (h *staticHandler) cacheControl(name string) string {
+	if h.opts.MaxAge < 0 || path.Ext(name) == ".html" {
+		return "no-cache"
+	}
+
+	maxAge := h.opts.MaxAge
+	if maxAge == 0 {
+		maxAge = DefaultStaticMaxAge
+	}
+
+	value := "public, max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+	if h.opts.Immutable {
+		value += ", immutable"
+	}
+
+	return value
+}
+
+// serveFallback serves index.html of the root in place of the requested path.
+func (h *staticHandler) serveFallback(resp http.ResponseWriter, req *http.Request) {
+	resp.Header().Set("Cache-Control", "no-cache")
+	http.ServeFileFS(resp, req, h.fsys, indexFile)
+}
@@ -0,0 +1,117 @@
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter_Static(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"index.html":         {Data: []byte("app")},
+		"assets/app.js":      {Data: []byte("script")},
+		"docs/index.html":    {Data: []byte("docs")},
+		"downloads/file.txt": {Data: []byte("file")},
+	}
+
+	tests := []struct {
+		name             string
+		path             string
+		wantBody         string
+		wantCacheControl string
+		opts             httpserver.StaticOptions
+		wantStatus       int
+	}{
+		{
+			name:             "asset",
+			path:             "/static/assets/app.js",
+			wantStatus:       http.StatusOK,
+			wantBody:         "script",
+			wantCacheControl: "public, max-age=3600",
+		},
+		{
+			name:             "immutable asset",
+			path:             "/static/assets/app.js",
+			opts:             httpserver.StaticOptions{MaxAge: 24 * time.Hour, Immutable: true},
+			wantStatus:       http.StatusOK,
+			wantBody:         "script",
+			wantCacheControl: "public, max-age=86400, immutable",
+		},
+		{
+			name:             "caching disabled",
+			path:             "/static/assets/app.js",
+			opts:             httpserver.StaticOptions{MaxAge: -1},
+			wantStatus:       http.StatusOK,
+			wantBody:         "script",
+			wantCacheControl: "no-cache",
+		},
+		{
+			name:             "directory index",
+			path:             "/static/docs/",
+			wantStatus:       http.StatusOK,
+			wantBody:         "docs",
+			wantCacheControl: "no-cache",
+		},
+		{name: "directory listing disabled", path: "/static/downloads/", wantStatus: http.StatusNotFound},
+		{
+			name:             "directory listing",
+			path:             "/static/downloads/",
+			opts:             httpserver.StaticOptions{Browse: true},
+			wantStatus:       http.StatusOK,
+			wantBody:         `<a href="file.txt">file.txt</a>`,
+			wantCacheControl: "no-cache",
+		},
+		{name: "missing file", path: "/static/users/42", wantStatus: http.StatusNotFound},
+		{
+			name:             "fallback",
+			path:             "/static/users/42",
+			opts:             httpserver.StaticOptions{Fallback: true},
+			wantStatus:       http.StatusOK,
+			wantBody:         "app",
+			wantCacheControl: "no-cache",
+		},
+		{
+			name:       "missing asset with fallback",
+			path:       "/static/assets/missing.js",
+			opts:       httpserver.StaticOptions{Fallback: true},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			router := httpserver.NewRouter()
+			router.Static("/static/", fsys, tt.opts)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, http.NoBody))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.wantStatus == http.StatusOK {
+				assert.Contains(t, rec.Body.String(), tt.wantBody)
+				assert.Equal(t, tt.wantCacheControl, rec.Header().Get("Cache-Control"))
+			}
+		})
+	}
+
+	t.Run("method not allowed", func(t *testing.T) {
+		t.Parallel()
+
+		router := httpserver.NewRouter()
+		router.Static("/static", fsys, httpserver.StaticOptions{})
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/static/assets/app.js", http.NoBody))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
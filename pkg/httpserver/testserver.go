@@ -0,0 +1,121 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"time"
+)
+
+// TestServer is an HTTPServer started on an ephemeral port of the loopback interface for integration tests,
+// e.g. of middleware stacks, together with a client trusting it. It is the counterpart of httptest.Server
+// running the full server with its options.
+type TestServer struct {
+	*HTTPServer
+
+	// Client sends requests to the server. It trusts the certificate of servers created by NewTLSTestServer.
+	Client *http.Client
+
+	// URL is the base URL of the server, e.g. "http://127.0.0.1:40123", without trailing slash.
+	URL string
+}
+
+// NewTestServer starts a server serving the handler over plain HTTP. The options are applied after the handler
+// and a logger discarding all messages, so they can replace both. It panics if the server cannot be started.
+func NewTestServer(handler http.Handler, opts ...Option) *TestServer {
+	return newTestServer(handler, nil, opts)
+}
+
+// NewTLSTestServer starts a server like NewTestServer serving TLS with a self-signed certificate
+// for localhost, 127.0.0.1 and ::1, which Client trusts. HTTP/2 is negotiated by Client.
+func NewTLSTestServer(handler http.Handler, opts ...Option) *TestServer {
+	certificate, err := newTestCertificate()
+	if err != nil {
+		panic(fmt.Sprintf("httpserver: failed to create test certificate: %v", err))
+	}
+
+	return newTestServer(handler, &certificate, opts)
+}
+
+// Close closes idle connections of Client and stops the server. Errors are logged.
+func (s *TestServer) Close() {
+	s.Client.CloseIdleConnections()
+
+	err := s.Stop(context.Background())
+	if err != nil {
+		s.Log.Error("failed to stop test server", "error", err)
+	}
+}
+
+func newTestServer(handler http.Handler, certificate *tls.Certificate, opts []Option) *TestServer {
+	cfg := &Config{}
+	cfg.SetDefaults()
+	cfg.Port = 0
+
+	opts = append([]Option{WithLogger(slog.New(slog.DiscardHandler)), WithHandler(handler)}, opts...)
+
+	transport := &http.Transport{ForceAttemptHTTP2: true}
+	scheme := "http"
+
+	if certificate != nil {
+		scheme = "https"
+		opts = append(opts, WithTLSConfig(&tls.Config{Certificates: []tls.Certificate{*certificate}}))
+
+		roots := x509.NewCertPool()
+		roots.AddCert(certificate.Leaf)
+		transport.TLSClientConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	}
+
+	server := New(cfg, opts...)
+
+	err := server.Start(context.Background())
+	if err != nil {
+		panic(fmt.Sprintf("httpserver: failed to start test server: %v", err))
+	}
+
+	return &TestServer{
+		HTTPServer: server,
+		Client:     &http.Client{Transport: transport},
+		URL:        scheme + "://" + server.Addr().String(),
+	}
+}
+
+// newTestCertificate creates a self-signed certificate for the loopback interface valid for a day.
+func newTestCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("httpserver: failed to generate key: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("httpserver: failed to create certificate: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("httpserver: failed to parse certificate: %w", err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, nil
+}
@@ -0,0 +1,73 @@
+package httpserver_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTestServer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		newServer  func(http.Handler, ...httpserver.Option) *httpserver.TestServer
+		name       string
+		wantScheme string
+		wantProto  string
+		opts       []httpserver.Option
+	}{
+		{name: "plain HTTP", newServer: httpserver.NewTestServer, wantScheme: "http://", wantProto: "HTTP/1.1"},
+		{name: "TLS", newServer: httpserver.NewTLSTestServer, wantScheme: "https://", wantProto: "HTTP/2.0"},
+		{
+			name:       "TLS with HTTP/1.1 only",
+			newServer:  httpserver.NewTLSTestServer,
+			opts:       []httpserver.Option{httpserver.WithProtocols(http1Only())},
+			wantScheme: "https://",
+			wantProto:  "HTTP/1.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			router := httpserver.NewRouter()
+			router.Use(tagMiddleware("outer"))
+			router.HandleFunc("GET /hello", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.WriteString(w, r.Proto)
+			})
+
+			server := tt.newServer(router, tt.opts...)
+			t.Cleanup(server.Close)
+
+			assert.True(t, strings.HasPrefix(server.URL, tt.wantScheme))
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+"/hello", nil)
+			require.NoError(t, err)
+
+			resp, err := server.Client.Do(req)
+			require.NoError(t, err)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, tt.wantProto, string(body))
+			assert.Equal(t, "outer", resp.Header.Get("X-Chain"))
+		})
+	}
+}
+
+func http1Only() *http.Protocols {
+	protocols := &http.Protocols{}
+	protocols.SetHTTP1(true)
+
+	return protocols
+}
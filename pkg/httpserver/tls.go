@@ -0,0 +1,84 @@
+package httpserver
+
+import "crypto/tls"
+
+// TLS profiles of Config.TLSProfile, following the server side TLS recommendations of Mozilla.
+const (
+	// TLSProfileModern accepts TLS 1.3 only, for clients released since 2019.
+	TLSProfileModern = "modern"
+
+	// TLSProfileIntermediate accepts TLS 1.2 with forward-secret AEAD cipher suites and TLS 1.3,
+	// which suits most servers.
+	TLSProfileIntermediate = "intermediate"
+
+	// TLSProfileOld additionally accepts TLS 1.0 and 1.1 and legacy cipher suites for very old clients.
+	// Use it only if such clients must be served.
+	TLSProfileOld = "old"
+)
+
+// tlsProfile holds the settings of a TLS profile.
+type tlsProfile struct {
+	cipherSuites []uint16
+	curves       []tls.CurveID
+	minVersion   uint16
+}
+
+// applyTLSProfile sets the minimum version, cipher suites and curves of the named profile.
+// Without a profile, only TLS 1.2 is required and Go picks the cipher suites and curves.
+func applyTLSProfile(config *tls.Config, name string) {
+	profile, ok := tlsProfiles()[name]
+	if !ok {
+		config.MinVersion = tls.VersionTLS12
+
+		return
+	}
+
+	config.MinVersion = profile.minVersion
+	config.CipherSuites = profile.cipherSuites
+	config.CurvePreferences = profile.curves
+}
+
+// tlsProfiles returns the settings of all TLS profiles by name.
+// Cipher suites of TLS 1.3 are not configurable in Go and thus omitted.
+func tlsProfiles() map[string]tlsProfile {
+	curves := []tls.CurveID{tls.X25519MLKEM768, tls.X25519, tls.CurveP256, tls.CurveP384}
+	intermediate := []uint16{
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+	}
+
+	return map[string]tlsProfile{
+		TLSProfileModern: {
+			minVersion: tls.VersionTLS13,
+			curves:     curves,
+		},
+		TLSProfileIntermediate: {
+			minVersion:   tls.VersionTLS12,
+			cipherSuites: intermediate,
+			curves:       curves,
+		},
+		TLSProfileOld: {
+			minVersion: tls.VersionTLS10,
+			//nolint:gosec // Legacy cipher suites are the purpose of this profile.
+			cipherSuites: append(intermediate,
+				tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256,
+				tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256,
+				tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+				tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+				tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+				tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+				tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+				tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+				tls.TLS_RSA_WITH_AES_128_CBC_SHA256,
+				tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+				tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+				tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA,
+			),
+			curves: curves,
+		},
+	}
+}
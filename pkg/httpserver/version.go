@@ -0,0 +1,133 @@
+package httpserver
+
+import (
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	_ http.Handler = (*Versions)(nil)
+
+	// vendorVersionPattern matches the version of vendor media types like application/vnd.example.v2+json.
+	vendorVersionPattern = regexp.MustCompile(`\.v(\d+)(?:\+|$)`)
+)
+
+// VersionOptions defines how the routes of an API version are announced to clients.
+type VersionOptions struct {
+	// Sunset is the time the version is removed, announced with the Sunset header. It implies Deprecated.
+	Sunset time.Time
+
+	// Deprecated announces the version as deprecated with the header "Deprecation: true", so clients can warn
+	// about their use of it.
+	Deprecated bool
+}
+
+// Versions routes requests to the router of the API version they select, either by a path prefix like
+// /v2/users or by the Accept header, e.g. "application/vnd.example.v2+json" or "application/json; version=2".
+// Requests selecting none are served by the default version. Like routes, versions are registered during setup.
+type Versions struct {
+	parent   *Router
+	versions map[int]*versionRoute
+
+	// fallback is the default version, or zero for the latest one.
+	fallback int
+	latest   int
+}
+
+// versionRoute serves the routes of a version.
+type versionRoute struct {
+	router *Router
+	opts   VersionOptions
+}
+
+// Versioned creates Versions serving the whole subtree of the router, e.g. one created by Route, behind its
+// middlewares. The router must not have a route for the pattern "/" itself.
+func Versioned(router *Router) *Versions {
+	obj := &Versions{parent: router, versions: map[int]*versionRoute{}}
+	router.Mount("/", obj)
+
+	return obj
+}
+
+// Default selects the version serving requests that select none. Default is the latest registered version.
+func (v *Versions) Default(version int) {
+	v.fallback = version
+}
+
+func (v *Versions) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	version, selected := acceptVersion(req.Header.Values("Accept"))
+	if !selected {
+		version = v.fallback
+		if version == 0 {
+			version = v.latest
+		}
+	}
+
+	route, found := v.versions[version]
+
+	switch {
+	case found:
+		route.ServeHTTP(resp, req)
+	case selected:
+		http.Error(resp, "unsupported API version "+strconv.Itoa(version), http.StatusNotAcceptable)
+	default:
+		http.NotFound(resp, req)
+	}
+}
+
+// Version registers the routes of the version on a router of their own, which are served below /v{version}/
+// and for requests selecting the version by Accept header, e.g.
+//
+//	versions.Version(1, httpserver.VersionOptions{Deprecated: true}, func(r *httpserver.Router) {
+//		r.Get("/users", listUsersV1)
+//	})
+func (v *Versions) Version(version int, opts VersionOptions, configure func(r *Router)) {
+	route := &versionRoute{router: NewRouter(), opts: opts}
+	configure(route.router)
+
+	v.versions[version] = route
+	v.latest = max(v.latest, version)
+	v.parent.Mount("/v"+strconv.Itoa(version), route)
+}
+
+func (r *versionRoute) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if r.opts.Deprecated || !r.opts.Sunset.IsZero() {
+		resp.Header().Set("Deprecation", "true")
+	}
+
+	if !r.opts.Sunset.IsZero() {
+		resp.Header().Set("Sunset", r.opts.Sunset.UTC().Format(http.TimeFormat))
+	}
+
+	r.router.ServeHTTP(resp, req)
+}
+
+// acceptVersion returns the version selected by the first media range of the Accept header naming one.
+func acceptVersion(accept []string) (int, bool) {
+	for _, value := range accept {
+		for mediaRange := range strings.SplitSeq(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			}
+
+			raw, found := params["version"]
+			if !found {
+				if match := vendorVersionPattern.FindStringSubmatch(mediaType); match != nil {
+					raw = match[1]
+				}
+			}
+
+			version, err := strconv.Atoi(raw)
+			if err == nil {
+				return version, true
+			}
+		}
+	}
+
+	return 0, false
+}
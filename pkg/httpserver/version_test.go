@@ -0,0 +1,105 @@
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/httpserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersioned(t *testing.T) {
+	t.Parallel()
+
+	sunset := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	router := httpserver.NewRouter()
+	router.Route("/api", func(r *httpserver.Router) {
+		r.Use(tagMiddleware("api"))
+
+		versions := httpserver.Versioned(r)
+		versions.Version(1, httpserver.VersionOptions{Sunset: sunset}, func(r *httpserver.Router) {
+			r.Get("/users", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("v1"))
+			})
+		})
+		versions.Version(2, httpserver.VersionOptions{}, func(r *httpserver.Router) {
+			r.Get("/users", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("v2"))
+			})
+		})
+		versions.Version(3, httpserver.VersionOptions{}, func(r *httpserver.Router) {
+			r.Get("/users", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("v3"))
+			})
+		})
+		versions.Default(2)
+	})
+
+	tests := []struct {
+		name            string
+		path            string
+		accept          string
+		want            string
+		wantDeprecation string
+		wantSunset      string
+		wantStatus      int
+	}{
+		{name: "default version", path: "/api/users", wantStatus: http.StatusOK, want: "v2"},
+		{name: "path prefix", path: "/api/v3/users", wantStatus: http.StatusOK, want: "v3"},
+		{
+			name:            "deprecated version",
+			path:            "/api/v1/users",
+			wantStatus:      http.StatusOK,
+			want:            "v1",
+			wantDeprecation: "true",
+			wantSunset:      "Tue, 01 Jan 2030 00:00:00 GMT",
+		},
+		{
+			name:       "vendor media type",
+			path:       "/api/users",
+			accept:     "text/html, application/vnd.example.v3+json",
+			wantStatus: http.StatusOK,
+			want:       "v3",
+		},
+		{
+			name:       "version parameter",
+			path:       "/api/users",
+			accept:     "application/json; version=3",
+			wantStatus: http.StatusOK,
+			want:       "v3",
+		},
+		{
+			name:       "unsupported version",
+			path:       "/api/users",
+			accept:     "application/json; version=9",
+			wantStatus: http.StatusNotAcceptable,
+		},
+		{name: "unknown route", path: "/api/v2/missing", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, http.NoBody)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, "api", rec.Header().Get("X-Chain"))
+
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.want, rec.Body.String())
+				assert.Equal(t, tt.wantDeprecation, rec.Header().Get("Deprecation"))
+				assert.Equal(t, tt.wantSunset, rec.Header().Get("Sunset"))
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// contextKey is the key under which a Logger is stored in a context.
+type contextKey struct{}
+
+var _ Logger = (*childLogger)(nil)
+
+// childLogger adds attributes to every record of a Logger that cannot derive child loggers itself.
+type childLogger struct {
+	parent Logger
+	args   []any
+}
+
+// From returns the Logger stored in the context by Into, or slog.Default if there is none.
+//
+//nolint:ireturn // Loggers are passed around by interface.
+func From(ctx context.Context) Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(Logger); ok {
+			return logger
+		}
+	}
+
+	return slog.Default()
+}
+
+// Into returns a copy of the context carrying the given Logger.
+func Into(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// With returns a child Logger that adds the given key-value pairs to every record.
+// It uses the With method of *slog.Logger if available.
+//
+//nolint:ireturn // Loggers are passed around by interface.
+func With(logger Logger, args ...any) Logger {
+	if len(args) == 0 {
+		return logger
+	}
+
+	switch parent := logger.(type) {
+	case *slog.Logger:
+		return parent.With(args...)
+	case *childLogger:
+		return &childLogger{parent: parent.parent, args: slices.Concat(parent.args, args)}
+	default:
+		return &childLogger{parent: logger, args: args}
+	}
+}
+
+func (l *childLogger) Debug(msg string, args ...any) {
+	l.parent.Debug(msg, slices.Concat(l.args, args)...)
+}
+
+func (l *childLogger) Error(msg string, args ...any) {
+	l.parent.Error(msg, slices.Concat(l.args, args)...)
+}
+
+func (l *childLogger) Info(msg string, args ...any) {
+	l.parent.Info(msg, slices.Concat(l.args, args)...)
+}
+
+func (l *childLogger) Warn(msg string, args ...any) {
+	l.parent.Warn(msg, slices.Concat(l.args, args)...)
+}
@@ -0,0 +1,136 @@
+package shutdown
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// EnvInheritedFiles lists the names of the files a process inherited from the process it replaced on Restart.
+// The files are passed as descriptors 3 and onwards in the order of the names, separated by commas.
+const EnvInheritedFiles = "SHUTDOWN_INHERITED_FILES"
+
+var (
+	ErrRestartFailed = errors.New("shutdown: failed to restart")
+
+	// inheritedFiles parses EnvInheritedFiles once, as the descriptors can only be claimed once.
+	//
+	//nolint:gochecknoglobals // The inherited descriptors belong to the process.
+	inheritedFiles = sync.OnceValue(parseInheritedFiles)
+
+	// inheritedMutex guards the files of inheritedFiles while they are claimed.
+	//
+	//nolint:gochecknoglobals // The inherited descriptors belong to the process.
+	inheritedMutex sync.Mutex
+)
+
+// Inheritable is implemented by services handing files to the process replacing them on Restart,
+// typically their listening sockets, so no connection is refused during the restart.
+type Inheritable interface {
+	// InheritFiles returns the files the new process inherits by their name.
+	// The new process claims them with InheritedFile. Names must not contain commas.
+	InheritFiles() (map[string]*os.File, error)
+}
+
+// InheritedFile returns the file of the given name handed over by the process this one replaced,
+// or nil if there is none. Every file can only be claimed once.
+func InheritedFile(name string) *os.File {
+	files := inheritedFiles()
+
+	inheritedMutex.Lock()
+	defer inheritedMutex.Unlock()
+
+	file := files[name]
+	delete(files, name)
+
+	return file
+}
+
+// Restart replaces the process without downtime: it starts the executable again with the same arguments,
+// hands the files of all tracked Inheritable services over to it and shuts down gracefully, so requests in flight
+// are finished while the new process takes over. If the new process cannot be started, this one keeps running.
+// A Restart is also triggered by SIGUSR2.
+func (s *Shutdown) Restart() error {
+	err := s.startSuccessor()
+	if err != nil {
+		return err
+	}
+
+	s.Shutdown()
+
+	return nil
+}
+
+// startSuccessor starts the process replacing this one with the files of all tracked Inheritable services.
+func (s *Shutdown) startSuccessor() error {
+	s.mutex.Lock()
+	inheritables := slices.Clone(s.inheritables)
+	s.mutex.Unlock()
+
+	var (
+		names []string
+		files []*os.File
+	)
+
+	defer func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}()
+
+	for _, inheritable := range inheritables {
+		inherited, err := inheritable.InheritFiles()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrRestartFailed, err)
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(inherited)) {
+			names = append(names, name)
+			files = append(files, inherited[name])
+		}
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRestartFailed, err)
+	}
+
+	//nolint:gosec // The process starts itself again.
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(os.Environ(), EnvInheritedFiles+"="+strings.Join(names, ","))
+
+	s.Log.Info("shutdown: starting new process", "files", names)
+
+	err = s.StartFn(cmd)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRestartFailed, err)
+	}
+
+	return nil
+}
+
+// parseInheritedFiles opens the files listed in EnvInheritedFiles and removes the variable,
+// so processes started by this one do not claim descriptors they do not have.
+func parseInheritedFiles() map[string]*os.File {
+	files := map[string]*os.File{}
+
+	value := os.Getenv(EnvInheritedFiles)
+	if value == "" {
+		return files
+	}
+
+	_ = os.Unsetenv(EnvInheritedFiles)
+
+	for i, name := range strings.Split(value, ",") {
+		files[name] = os.NewFile(uintptr(3+i), name) //nolint:gosec // Index is never negative.
+	}
+
+	return files
+}
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"os/exec"
 	"os/signal"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -31,6 +33,13 @@ type Trackable interface {
 	Stop(ctx context.Context) error
 }
 
+// Failable is implemented by tracked services that can fail after Start returned, e.g. a server whose
+// listener broke. Track shuts down once the channel delivers an error.
+type Failable interface {
+	// Err returns a channel receiving the error the service failed with.
+	Err() <-chan error
+}
+
 // Shutdown is a struct that manages context cancellation and synchronization.
 type Shutdown struct {
 	// runtimeCtx is the context for managing cancellation.
@@ -50,6 +59,12 @@ type Shutdown struct {
 	// ExitFn allows overriding os.Exit for testing
 	ExitFn func(int)
 
+	// StartFn starts the new process on Restart and allows overriding (*exec.Cmd).Start for testing.
+	StartFn func(cmd *exec.Cmd) error
+
+	// Publisher is optionally notified about every state transition.
+	Publisher StatePublisher
+
 	// cancelRuntimeFn is the function to cancel the runtime context.
 	cancelRuntimeFn context.CancelFunc
 
@@ -60,8 +75,17 @@ type Shutdown struct {
 	// for handling graceful shutdowns or specific behaviors.
 	signalCh chan os.Signal
 
+	// inheritables are the tracked services handing files to the new process on Restart.
+	inheritables []Inheritable
+
+	// mutex guards inheritables.
+	mutex sync.Mutex
+
 	// waitGroup is used to synchronize and wait for the completion of multiple goroutines.
 	waitGroup sync.WaitGroup
+
+	// state holds the current lifecycle state.
+	state atomic.Int32
 }
 
 // New creates a new Shutdown instance with the provided configuration.
@@ -74,23 +98,31 @@ func New(cfg *Config) *Shutdown {
 		Log:              slog.Default(),
 		cfg:              cfg,
 		ExitFn:           os.Exit,
+		StartFn:          (*exec.Cmd).Start,
 		cancelRuntimeFn:  cancelRuntimeFn,
 		cancelShutdownFn: cancelShutdownFn,
 		signalCh:         make(chan os.Signal, 1),
 	}
 
 	// Listen to interrupt, termination, and user signals.
-	signal.Notify(obj.signalCh, os.Interrupt, syscall.SIGTERM, syscall.SIGUSR1)
+	signal.Notify(obj.signalCh, os.Interrupt, syscall.SIGTERM, syscall.SIGUSR1, syscall.SIGUSR2)
 
 	go func() {
 		defer obj.Shutdown()
 
 		for {
-			sig := <-obj.signalCh
-			if sig == syscall.SIGUSR1 {
+			switch <-obj.signalCh {
+			case syscall.SIGUSR1:
 				obj.Drain()
-			} else {
-				break
+			case syscall.SIGUSR2:
+				err := obj.startSuccessor()
+				if err == nil {
+					return
+				}
+
+				obj.Log.Error("shutdown: failed to restart", "error", err)
+			default:
+				return
 			}
 		}
 	}()
@@ -116,6 +148,7 @@ func (s *Shutdown) Done() <-chan struct{} {
 // Use this to stop accepting new connections or long-running tasks.
 func (s *Shutdown) Drain() {
 	s.Log.Info("shutdown: initializing drain")
+	s.setState(StateDraining)
 	s.cancelRuntimeFn()
 
 	go s.observeShutdown(nil)
@@ -145,6 +178,7 @@ func (s *Shutdown) Go(task func(context.Context)) error {
 // This is useful for programmatic shutdown scenarios.
 func (s *Shutdown) Shutdown() {
 	s.Log.Info("shutdown: initializing shutdown")
+	s.setState(StateStopping)
 	s.cancelRuntimeFn()
 
 	go s.observeShutdown(s.cancelShutdownFn)
@@ -157,12 +191,19 @@ func (s *Shutdown) Shutdown() {
 		s.Log.Error("shutdown: shutdown timed out")
 	}
 
+	s.setState(StateStopped)
+
 	if s.cfg.Force {
 		s.Log.Info("shutdown: shutting down forcefully")
 		s.ExitFn(ExitCodeSigTerm)
 	}
 }
 
+// State returns the current lifecycle state.
+func (s *Shutdown) State() State {
+	return State(s.state.Load())
+}
+
 // Track initiates a trackable entity, adding it to the wait group and invoking its Start method with the given context.
 func (s *Shutdown) Track(service any) error {
 	if s.runtimeCtx.Err() != nil {
@@ -175,6 +216,12 @@ func (s *Shutdown) Track(service any) error {
 		return nil
 	}
 
+	if inheritable, ok := service.(Inheritable); ok {
+		s.mutex.Lock()
+		s.inheritables = append(s.inheritables, inheritable)
+		s.mutex.Unlock()
+	}
+
 	if trackable, ok := service.(Trackable); ok {
 		go func() {
 			defer s.waitGroup.Done()
@@ -192,6 +239,10 @@ func (s *Shutdown) Track(service any) error {
 			return fmt.Errorf("shutdown: starting service service: %w", err)
 		}
 
+		if failable, ok := service.(Failable); ok {
+			go s.observeFailure(failable)
+		}
+
 		s.Log.Debug("shutdown: starting service")
 	}
 
@@ -205,6 +256,16 @@ func (s *Shutdown) Wait() {
 	<-s.shutdownCtx.Done()
 }
 
+// observeFailure shuts down once the service fails, unless the runtime context is canceled before.
+func (s *Shutdown) observeFailure(failable Failable) {
+	select {
+	case err := <-failable.Err():
+		s.Log.Error("shutdown: service failed", "error", err)
+		s.Shutdown()
+	case <-s.runtimeCtx.Done():
+	}
+}
+
 func (s *Shutdown) observeShutdown(callback func()) {
 	s.waitGroup.Wait()
 	s.Log.Info("shutdown: all tasks completed")
@@ -213,3 +274,24 @@ func (s *Shutdown) observeShutdown(callback func()) {
 		callback()
 	}
 }
+
+// setState advances the lifecycle state and notifies the Publisher. Transitions to
+// an earlier or the current state are ignored.
+func (s *Shutdown) setState(state State) {
+	for {
+		current := s.state.Load()
+		if State(current) >= state {
+			return
+		}
+
+		if s.state.CompareAndSwap(current, int32(state)) {
+			break
+		}
+	}
+
+	s.Log.Debug("shutdown: state changed", "state", state)
+
+	if s.Publisher != nil {
+		s.Publisher.PublishState(state)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
@@ -132,6 +135,110 @@ func TestShutdown_Integration(t *testing.T) {
 	assert.Lessf(t, elapsed, time.Second, "shutdown took too long: %obj", elapsed)
 }
 
+//nolint:paralleltest // This test is not safe to run in parallel.
+func TestShutdown_Track_Failable(t *testing.T) {
+	obj := shutdown.New(&shutdown.Config{Timeout: time.Second, Force: false})
+
+	service := &mockFailable{errCh: make(chan error, 1)}
+	require.NoError(t, obj.Track(service))
+
+	service.errCh <- errMock
+
+	select {
+	case <-obj.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("failed service did not shut down")
+	}
+
+	assert.True(t, <-service.StopCalled)
+	assert.Eventually(t, func() bool {
+		return obj.State() == shutdown.StateStopped
+	}, time.Second, 10*time.Millisecond)
+}
+
+//nolint:paralleltest // This test is not safe to run in parallel.
+func TestShutdown_Publisher(t *testing.T) {
+	obj := shutdown.New(&shutdown.Config{Timeout: time.Second, Force: false})
+
+	var (
+		mutex  sync.Mutex
+		states []shutdown.State
+	)
+
+	obj.Publisher = shutdown.StatePublisherFunc(func(state shutdown.State) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		states = append(states, state)
+	})
+
+	assert.Equal(t, shutdown.StateRunning, obj.State())
+
+	obj.Drain()
+	assert.Equal(t, shutdown.StateDraining, obj.State())
+
+	obj.Shutdown()
+	obj.Drain()
+	assert.Equal(t, shutdown.StateStopped, obj.State())
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	assert.Equal(t, []shutdown.State{
+		shutdown.StateDraining,
+		shutdown.StateStopping,
+		shutdown.StateStopped,
+	}, states)
+	assert.Equal(t, "stopping", shutdown.StateStopping.String())
+}
+
+//nolint:paralleltest // This test is not safe to run in parallel.
+func TestShutdown_Restart(t *testing.T) {
+	tests := []struct {
+		startErr error
+		wantErr  error
+		name     string
+		want     shutdown.State
+	}{
+		{name: "new process started", want: shutdown.StateStopped},
+		{name: "new process fails", startErr: errMock, wantErr: shutdown.ErrRestartFailed, want: shutdown.StateRunning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := shutdown.New(&shutdown.Config{Timeout: time.Second, Force: false})
+
+			var started *exec.Cmd
+
+			obj.StartFn = func(cmd *exec.Cmd) error {
+				started = cmd
+
+				return tt.startErr
+			}
+
+			service := &mockInheritable{path: filepath.Join(t.TempDir(), "socket")}
+			require.NoError(t, obj.Track(service))
+
+			err := obj.Restart()
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+
+			assert.Equal(t, tt.want, obj.State())
+			require.NotNil(t, started)
+			require.Len(t, started.ExtraFiles, 2)
+			assert.Equal(t, service.path+".a", started.ExtraFiles[0].Name())
+			assert.Contains(t, started.Env, shutdown.EnvInheritedFiles+"=a,b")
+
+			if tt.wantErr != nil {
+				obj.Shutdown()
+			}
+		})
+	}
+}
+
 var errMock = errors.New("stop error")
 
 type mockService struct {
@@ -154,6 +261,35 @@ func (m *mockService) Stop(_ context.Context) error {
 	return m.ReturnError
 }
 
+type mockFailable struct {
+	errCh chan error
+
+	mockService
+}
+
+func (m *mockFailable) Err() <-chan error {
+	return m.errCh
+}
+
+type mockInheritable struct {
+	path string
+}
+
+func (m *mockInheritable) InheritFiles() (map[string]*os.File, error) {
+	files := map[string]*os.File{}
+
+	for _, name := range []string{"b", "a"} {
+		file, err := os.Create(m.path + "." + name)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // Required for testing.
+		}
+
+		files[name] = file
+	}
+
+	return files, nil
+}
+
 func sendSignal(t *testing.T, signal os.Signal) {
 	t.Helper()
 
@@ -0,0 +1,52 @@
+package shutdown
+
+// State represents a lifecycle phase of a Shutdown. States only ever advance in the order
+// StateRunning, StateDraining, StateStopping, StateStopped.
+type State int32
+
+const (
+	// StateRunning indicates that the application is running and accepting work.
+	StateRunning State = iota
+
+	// StateDraining indicates that workers are being stopped while the process stays alive.
+	StateDraining
+
+	// StateStopping indicates that a shutdown has been initiated.
+	StateStopping
+
+	// StateStopped indicates that the shutdown completed or timed out.
+	StateStopped
+)
+
+var _ StatePublisher = (StatePublisherFunc)(nil)
+
+// StatePublisher receives state transitions of a Shutdown, e.g. to flip a readiness probe
+// as soon as a termination signal arrives.
+type StatePublisher interface {
+	// PublishState is called once for every state transition.
+	PublishState(state State)
+}
+
+// StatePublisherFunc adapts an ordinary function to the StatePublisher interface.
+type StatePublisherFunc func(state State)
+
+// PublishState calls f(state).
+func (f StatePublisherFunc) PublishState(state State) {
+	f(state)
+}
+
+// String returns the lower-case name of the state.
+func (s State) String() string {
+	switch s {
+	case StateRunning:
+		return "running"
+	case StateDraining:
+		return "draining"
+	case StateStopping:
+		return "stopping"
+	case StateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
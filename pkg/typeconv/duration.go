@@ -0,0 +1,135 @@
+package typeconv
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseExtendedDuration parses a duration string like time.ParseDuration but additionally
+// accepts the "d" (day) and "w" (week) units, e.g. "2w3d12h". A bare number is multiplied
+// by unit; if unit is zero, only "0" is accepted as a bare number. Bare numbers must be decimal
+// literals such as "7" or "-1.5"; exponents, hexadecimal floats, "NaN" and "Inf" are rejected.
+func ParseExtendedDuration(value string, unit time.Duration) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+
+	if isDecimalLiteral(value) {
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, value)
+		}
+
+		if number != 0 && unit == 0 {
+			return 0, fmt.Errorf("%w: missing unit in %q", ErrInvalidDuration, value)
+		}
+
+		return scaleDuration(number, unit, value)
+	}
+
+	negative := strings.HasPrefix(value, "-")
+	rest := strings.TrimLeft(value, "+-")
+
+	if rest == "" || len(value)-len(rest) > 1 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, value)
+	}
+
+	var total time.Duration
+
+	for rest != "" {
+		numberEnd := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if numberEnd <= 0 {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, value)
+		}
+
+		unitEnd := strings.IndexFunc(rest[numberEnd:], func(r rune) bool { return (r >= '0' && r <= '9') || r == '.' })
+		if unitEnd < 0 {
+			unitEnd = len(rest) - numberEnd
+		}
+
+		number, suffix := rest[:numberEnd], rest[numberEnd:numberEnd+unitEnd]
+		rest = rest[numberEnd+unitEnd:]
+
+		part, err := parseDurationPart(number, suffix, value)
+		if err != nil {
+			return 0, err
+		}
+
+		if total > math.MaxInt64-part {
+			return 0, fmt.Errorf("%w: %q overflows", ErrInvalidDuration, value)
+		}
+
+		total += part
+	}
+
+	if negative {
+		total = -total
+	}
+
+	return total, nil
+}
+
+// parseDurationPart converts a single number and unit pair into a duration.
+func parseDurationPart(number, suffix, value string) (time.Duration, error) {
+	switch suffix {
+	case "d":
+		return scaleFloat(number, Day, value)
+	case "w":
+		return scaleFloat(number, Week, value)
+	case "":
+		return 0, fmt.Errorf("%w: missing unit in %q", ErrInvalidDuration, value)
+	}
+
+	part, err := time.ParseDuration(number + suffix)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidDuration, err)
+	}
+
+	return part, nil
+}
+
+func scaleFloat(number string, unit time.Duration, value string) (time.Duration, error) {
+	floatVal, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, value)
+	}
+
+	return scaleDuration(floatVal, unit, value)
+}
+
+func scaleDuration(number float64, unit time.Duration, value string) (time.Duration, error) {
+	result := number * float64(unit)
+
+	// float64(math.MaxInt64) rounds up to 2^63, which no longer fits into a time.Duration.
+	if math.IsNaN(result) || math.IsInf(result, 0) || result >= math.MaxInt64 || result < math.MinInt64 {
+		return 0, fmt.Errorf("%w: %q overflows", ErrInvalidDuration, value)
+	}
+
+	return time.Duration(result), nil
+}
+
+// isDecimalLiteral reports whether the value is a decimal number of the form [+-]?\d+(\.\d+)?.
+func isDecimalLiteral(value string) bool {
+	if strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-") {
+		value = value[1:]
+	}
+
+	integer, fraction, hasFraction := strings.Cut(value, ".")
+
+	return isDigits(integer) && (!hasFraction || isDigits(fraction))
+}
+
+func isDigits(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	for i := range len(value) {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
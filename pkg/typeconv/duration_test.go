@@ -0,0 +1,81 @@
+package typeconv_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/typeconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExtendedDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		value   string
+		unit    time.Duration
+		want    time.Duration
+		wantErr bool
+	}{
+		{"days", "2d", 0, 2 * typeconv.Day, false},
+		{"weeks", "1w", 0, typeconv.Week, false},
+		{"fractional days", "1.5d", 0, 36 * time.Hour, false},
+		{"combined", "1w2d3h4m", 0, typeconv.Week + 2*typeconv.Day + 3*time.Hour + 4*time.Minute, false},
+		{"negative", "-1d12h", 0, -36 * time.Hour, false},
+		{"standard units", "1h30m", 0, 90 * time.Minute, false},
+		{"bare number with unit", "7", typeconv.Day, 7 * typeconv.Day, false},
+		{"bare zero without unit", "0", 0, 0, false},
+		{"bare number without unit", "7", 0, 0, true},
+		{"missing unit after number", "1d7", 0, 0, true},
+		{"unknown unit", "3y", 0, 0, true},
+		{"double sign", "--1d", 0, 0, true},
+		{"empty", "", 0, 0, true},
+		{"overflow", "100000000w", 0, 0, true},
+		{"bare number overflow", "9223372036854775807", time.Nanosecond, 0, true},
+		{"bare NaN", "NaN", time.Second, 0, true},
+		{"bare Inf", "Inf", time.Second, 0, true},
+		{"bare negative Inf", "-Inf", time.Second, 0, true},
+		{"bare exponent", "1e3", time.Second, 0, true},
+		{"bare hexadecimal float", "0x1p-2", time.Second, 0, true},
+		{"bare fraction with unit", "1.5", time.Second, 1500 * time.Millisecond, false},
+		{"bare signed number with unit", "+2", time.Second, 2 * time.Second, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := typeconv.ParseExtendedDuration(tt.value, tt.unit)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, typeconv.ErrInvalidDuration)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_ExtendedDuration(t *testing.T) {
+	t.Parallel()
+
+	var result time.Duration
+
+	target := reflect.ValueOf(&result).Elem()
+
+	c := typeconv.New()
+	require.ErrorIs(t, c.Convert(target, "2d"), typeconv.ErrInvalidValue)
+
+	c.ExtendedDuration = true
+	c.DurationUnit = time.Second
+
+	require.NoError(t, c.Convert(target, "2d"))
+	assert.Equal(t, 48*time.Hour, result)
+
+	require.NoError(t, c.Convert(target, "30"))
+	assert.Equal(t, 30*time.Second, result)
+}
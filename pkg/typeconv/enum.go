@@ -0,0 +1,62 @@
+package typeconv
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ConvertEnum returns value as T if it is one of the allowed values.
+// Otherwise, the error lists the valid options.
+//
+//nolint:ireturn // Generic function must return type parameter T.
+func ConvertEnum[T ~string](value string, allowed ...T) (T, error) {
+	result := T(value)
+	if !slices.Contains(allowed, result) {
+		return "", notAllowedError(value, allowed)
+	}
+
+	return result, nil
+}
+
+// RegisterEnum restricts all conversions into T performed by the converter to the allowed values,
+// so fields like "mode" or "level" need no separate validation. Register enums before the converter
+// is used concurrently; registering the same type again replaces its allowed values.
+func RegisterEnum[T ~string](c *Converter, allowed ...T) {
+	if c.enums == nil {
+		c.enums = map[reflect.Type][]string{}
+	}
+
+	values := make([]string, len(allowed))
+	for i := range allowed {
+		values[i] = string(allowed[i])
+	}
+
+	c.enums[reflect.TypeFor[T]()] = values
+}
+
+// setEnum sets the string field if its type has no registered allow-list or the value is allowed.
+func (c *Converter) setEnum(field reflect.Value, value string) error {
+	if allowed, ok := c.enums[field.Type()]; ok && !slices.Contains(allowed, value) {
+		return notAllowedError(value, allowed)
+	}
+
+	field.SetString(value)
+
+	return nil
+}
+
+func notAllowedError[T ~string](value string, allowed []T) error {
+	options := make([]string, len(allowed))
+	for i := range allowed {
+		options[i] = fmt.Sprintf("'%s'", allowed[i])
+	}
+
+	return fmt.Errorf(
+		"%w: '%s' is not one of %s",
+		ErrInvalidValue,
+		value,
+		strings.Join(options, ", "),
+	)
+}
@@ -0,0 +1,55 @@
+package typeconv_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/typeconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mode string
+
+const (
+	modeFast mode = "fast"
+	modeSafe mode = "safe"
+)
+
+func TestConvertEnum(t *testing.T) {
+	t.Parallel()
+
+	result, err := typeconv.ConvertEnum("safe", modeFast, modeSafe)
+	require.NoError(t, err)
+	assert.Equal(t, modeSafe, result)
+
+	_, err = typeconv.ConvertEnum("slow", modeFast, modeSafe)
+	require.ErrorIs(t, err, typeconv.ErrInvalidValue)
+	assert.ErrorContains(t, err, "'slow' is not one of 'fast', 'safe'")
+}
+
+func TestRegisterEnum(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Mode  mode
+		Name  string
+		Modes []mode
+	}
+
+	c := typeconv.New()
+	typeconv.RegisterEnum(c, modeFast, modeSafe)
+
+	var result Config
+
+	target := reflect.ValueOf(&result).Elem()
+
+	require.NoError(t, c.Convert(target, "mode=fast,name=anything"))
+	assert.Equal(t, Config{Mode: modeFast, Name: "anything"}, result)
+
+	require.ErrorIs(t, c.Convert(target, "mode=slow"), typeconv.ErrInvalidValue)
+	require.ErrorIs(t, c.Convert(target.Field(2), "fast,slow"), typeconv.ErrInvalidValue)
+
+	// Other converters are not restricted.
+	require.NoError(t, typeconv.New().Convert(target.Field(0), "slow"))
+}
@@ -0,0 +1,8 @@
+package typeconv
+
+import "reflect"
+
+// ConvertUncached converts without consulting the plan cache, as a baseline for benchmarks.
+func (c *Converter) ConvertUncached(target reflect.Value, value string) error {
+	return buildPlan(target.Type())(c, target, value)
+}
@@ -0,0 +1,57 @@
+package typeconv
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Hook transforms a raw value before it is converted into a value of targetType,
+// e.g. to trim, decrypt or expand it. Returning an error aborts the conversion.
+type Hook func(targetType reflect.Type, raw string) (string, error)
+
+// PostHook is called after a value was converted and set, e.g. to validate or audit it.
+// It receives the converted target and the raw value after all hooks ran.
+type PostHook func(target reflect.Value, raw string) error
+
+// AddHook appends a hook that runs before every conversion, including every element of
+// slices, arrays and struct fields. Hooks run in the order they were added. Add hooks before
+// the converter is used concurrently.
+func (c *Converter) AddHook(hook Hook) {
+	c.hooks = append(c.hooks, hook)
+}
+
+// AddPostHook appends a hook that runs after every conversion, including every element of
+// slices, arrays and struct fields. Add hooks before the converter is used concurrently.
+func (c *Converter) AddPostHook(hook PostHook) {
+	c.postHooks = append(c.postHooks, hook)
+}
+
+// apply runs the hooks around the setter. Pointer indirection does not run the hooks again.
+func (c *Converter) apply(set setter, field reflect.Value, value string) error {
+	if len(c.hooks) == 0 && len(c.postHooks) == 0 {
+		return set(c, field, value)
+	}
+
+	var err error
+
+	for _, hook := range c.hooks {
+		value, err = hook(field.Type(), value)
+		if err != nil {
+			return fmt.Errorf("typeconv: hook for %s: %w", field.Type(), err)
+		}
+	}
+
+	err = set(c, field, value)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range c.postHooks {
+		err = hook(field, value)
+		if err != nil {
+			return fmt.Errorf("typeconv: post hook for %s: %w", field.Type(), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package typeconv_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/typeconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errHook = errors.New("hook error")
+
+func TestConverter_AddHook(t *testing.T) {
+	t.Parallel()
+
+	var (
+		result    []*int
+		preTypes  []string
+		postTypes []string
+	)
+
+	c := typeconv.New()
+	c.AddHook(func(targetType reflect.Type, raw string) (string, error) {
+		preTypes = append(preTypes, targetType.String())
+
+		return strings.ReplaceAll(raw, "x", ""), nil
+	})
+	c.AddHook(func(_ reflect.Type, raw string) (string, error) {
+		return strings.TrimSuffix(raw, "!"), nil
+	})
+	c.AddPostHook(func(target reflect.Value, _ string) error {
+		postTypes = append(postTypes, target.Type().String())
+
+		return nil
+	})
+
+	err := c.Convert(reflect.ValueOf(&result).Elem(), "1x,x2!")
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, 1, *result[0])
+	assert.Equal(t, 2, *result[1])
+	assert.Equal(t, []string{"[]*int", "*int", "*int"}, preTypes)
+	assert.Equal(t, []string{"*int", "*int", "[]*int"}, postTypes)
+}
+
+func TestConverter_AddHook_Error(t *testing.T) {
+	t.Parallel()
+
+	var result int
+
+	target := reflect.ValueOf(&result).Elem()
+
+	c := typeconv.New()
+	c.AddHook(func(_ reflect.Type, raw string) (string, error) {
+		if raw == "fail" {
+			return "", errHook
+		}
+
+		return raw, nil
+	})
+	require.ErrorIs(t, c.Convert(target, "fail"), errHook)
+
+	c.AddPostHook(func(target reflect.Value, _ string) error {
+		if target.Int() > 10 {
+			return errHook
+		}
+
+		return nil
+	})
+	require.NoError(t, c.Convert(target, "5"))
+	require.ErrorIs(t, c.Convert(target, "50"), errHook)
+}
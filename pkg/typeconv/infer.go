@@ -0,0 +1,86 @@
+package typeconv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InferValue converts the value into the first of the hinted types it can be parsed as.
+// Without hints, it infers bool ("true" or "false" in any case), int64, float64 (decimal literals only, so
+// "NaN", "Inf" or "1e3" remain strings), time.Duration, time.Time (using TimeLayout) and finally falls back
+// to string, trimmed unless the Converter is Strict. Hooks do not run for inference attempts.
+func (c *Converter) InferValue(value string, hints ...reflect.Type) (any, error) {
+	if len(hints) == 0 {
+		return c.inferDefault(value), nil
+	}
+
+	for _, hint := range hints {
+		result := reflect.New(hint).Elem()
+		if planFor(hint)(c, result, value) == nil {
+			return result.Interface(), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: cannot infer type of '%s' from %v", ErrInvalidValue, value, hints)
+}
+
+// inferDefault infers the best matching type of the value using the built-in candidates.
+func (c *Converter) inferDefault(value string) any {
+	trimmed := c.trim(value)
+
+	switch {
+	case strings.EqualFold(trimmed, "true"):
+		return true
+	case strings.EqualFold(trimmed, "false"):
+		return false
+	}
+
+	if intVal, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+		return intVal
+	}
+
+	if isDecimalLiteral(trimmed) {
+		if floatVal, err := strconv.ParseFloat(trimmed, 64); err == nil {
+			return floatVal
+		}
+	}
+
+	var durationVal time.Duration
+
+	if planFor(reflect.TypeFor[time.Duration]())(c, reflect.ValueOf(&durationVal).Elem(), trimmed) == nil {
+		return durationVal
+	}
+
+	if c.TimeLayout != "" {
+		if timeVal, err := time.Parse(c.TimeLayout, trimmed); err == nil {
+			return timeVal
+		}
+	}
+
+	return trimmed
+}
+
+// setInterface stores the inferred value in an empty interface field, guided by InferTypes.
+func (c *Converter) setInterface(field reflect.Value, value string) error {
+	if field.NumMethod() > 0 {
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, field.Type())
+	}
+
+	if c.isNilValue(value) {
+		field.SetZero()
+
+		return nil
+	}
+
+	result, err := c.InferValue(value, c.InferTypes...)
+	if err != nil {
+		return err
+	}
+
+	field.Set(reflect.ValueOf(result))
+
+	return nil
+}
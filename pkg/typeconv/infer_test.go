@@ -0,0 +1,88 @@
+package typeconv_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/typeconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConverter_InferValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		want  any
+		name  string
+		value string
+		hints []reflect.Type
+	}{
+		{name: "bool", value: "TRUE", want: true},
+		{name: "int", value: "42", want: int64(42)},
+		{name: "one is not a bool", value: "1", want: int64(1)},
+		{name: "float", value: "3.5", want: 3.5},
+		{name: "negative float", value: "-0.25", want: -0.25},
+		{name: "nan is not a float", value: "nan", want: "nan"},
+		{name: "inf is not a float", value: "inf", want: "inf"},
+		{name: "infinity is not a float", value: "infinity", want: "infinity"},
+		{name: "exponent is not a float", value: "1e3", want: "1e3"},
+		{name: "hexadecimal float is not a float", value: "0x1p-2", want: "0x1p-2"},
+		{name: "duration", value: "1m30s", want: 90 * time.Second},
+		{name: "time", value: "2023-01-15T10:30:00Z", want: time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC)},
+		{name: "string", value: "yes", want: "yes"},
+		{name: "trimmed string", value: "  yes\t", want: "yes"},
+		{name: "trimmed int", value: " 42 ", want: int64(42)},
+		{name: "hint", value: "42", hints: []reflect.Type{reflect.TypeFor[uint8]()}, want: uint8(42)},
+		{
+			name:  "second hint",
+			value: "300",
+			hints: []reflect.Type{reflect.TypeFor[uint8](), reflect.TypeFor[int16]()},
+			want:  int16(300),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := typeconv.New().InferValue(tt.value, tt.hints...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	strict := typeconv.New()
+	strict.Strict = true
+
+	got, err := strict.InferValue(" yes ")
+	require.NoError(t, err)
+	assert.Equal(t, " yes ", got)
+
+	_, err = typeconv.New().InferValue("x", reflect.TypeFor[int]())
+	assert.ErrorIs(t, err, typeconv.ErrInvalidValue)
+}
+
+func TestConverter_Convert_Interface(t *testing.T) {
+	t.Parallel()
+
+	var payload map[string]any
+
+	c := typeconv.New()
+	c.NilValues = []string{"null"}
+
+	require.NoError(t, c.Convert(reflect.ValueOf(&payload).Elem(), "enabled=true,retries=3,name=plugin,extra=null"))
+	assert.Equal(t, map[string]any{"enabled": true, "retries": int64(3), "name": "plugin", "extra": nil}, payload)
+
+	var stringer interface{ String() string }
+
+	require.ErrorIs(t, c.Convert(reflect.ValueOf(&stringer).Elem(), "x"), typeconv.ErrUnsupportedType)
+
+	c.InferTypes = []reflect.Type{reflect.TypeFor[string]()}
+
+	var value any
+
+	require.NoError(t, c.Convert(reflect.ValueOf(&value).Elem(), "42"))
+	assert.Equal(t, "42", value)
+}
@@ -0,0 +1,165 @@
+package typeconv
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// setter converts a string and stores the result in field. Setters are resolved once per type
+// and read the Converter settings on every call, so a single plan serves all Converters.
+type setter func(c *Converter, field reflect.Value, value string) error
+
+// plans caches the resolved setter for every reflect.Type seen so far.
+//
+//nolint:gochecknoglobals // Process-wide cache of immutable conversion plans.
+var plans sync.Map
+
+// planFor returns the cached setter for the type, building it on first use.
+func planFor(typeOf reflect.Type) setter {
+	if cached, ok := plans.Load(typeOf); ok {
+		return cached.(setter) //nolint:forcetypeassert // Only setters are stored.
+	}
+
+	actual, _ := plans.LoadOrStore(typeOf, buildPlan(typeOf))
+
+	return actual.(setter) //nolint:forcetypeassert // Only setters are stored.
+}
+
+// buildPlan resolves the setter for a type by dispatching on its kind once.
+// Element setters of composite types are resolved lazily to support recursive types.
+func buildPlan(typeOf reflect.Type) setter {
+	switch typeOf {
+	case reflect.TypeFor[time.Duration]():
+		return func(c *Converter, field reflect.Value, value string) error {
+			if c.ExtendedDuration {
+				if c.Strict && value != strings.TrimSpace(value) {
+					return fmt.Errorf("%w: cannot parse '%s' as duration", ErrInvalidValue, value)
+				}
+
+				return setExtendedDuration(field, value, c.DurationUnit)
+			}
+
+			return setDuration(field, value)
+		}
+	case reflect.TypeFor[time.Time]():
+		return func(c *Converter, field reflect.Value, value string) error {
+			return setTime(field, value, c.TimeLayout)
+		}
+	case reflect.TypeFor[big.Int]():
+		return func(c *Converter, field reflect.Value, value string) error {
+			return setBigInt(field, c.trim(value))
+		}
+	case reflect.TypeFor[big.Float]():
+		return func(c *Converter, field reflect.Value, value string) error {
+			return setBigFloat(field, c.trim(value), c.BigFloatPrecision)
+		}
+	case reflect.TypeFor[big.Rat]():
+		return func(c *Converter, field reflect.Value, value string) error {
+			return setBigRat(field, c.trim(value))
+		}
+	case reflect.TypeFor[json.Number]():
+		return func(c *Converter, field reflect.Value, value string) error {
+			return setJSONNumber(field, c.trim(value))
+		}
+	}
+
+	//nolint:exhaustive // Only handling supported reflect.Kind types; unsupported types handled by default case.
+	switch typeOf.Kind() {
+	case reflect.String:
+		return func(c *Converter, field reflect.Value, value string) error {
+			return c.setEnum(field, value)
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return numberPlan(setInt)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return numberPlan(setUint)
+
+	case reflect.Float32, reflect.Float64:
+		return numberPlan(setFloat)
+
+	case reflect.Bool:
+		return func(c *Converter, field reflect.Value, value string) error {
+			return setBool(field, value, c.Strict)
+		}
+
+	case reflect.Ptr:
+		elem := lazyPlan(typeOf.Elem())
+
+		return func(c *Converter, field reflect.Value, value string) error {
+			if c.isNilValue(value) {
+				field.SetZero()
+
+				return nil
+			}
+
+			if field.IsNil() {
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+
+			return elem()(c, field.Elem(), value)
+		}
+
+	case reflect.Slice:
+		elem := lazyPlan(typeOf.Elem())
+
+		return func(c *Converter, field reflect.Value, value string) error {
+			return c.setSlice(field, value, elem())
+		}
+
+	case reflect.Array:
+		elem := lazyPlan(typeOf.Elem())
+
+		return func(c *Converter, field reflect.Value, value string) error {
+			return c.setArray(field, value, elem())
+		}
+
+	case reflect.Map:
+		key := lazyPlan(typeOf.Key())
+		elem := lazyPlan(typeOf.Elem())
+
+		return func(c *Converter, field reflect.Value, value string) error {
+			return c.setMap(field, value, key(), elem())
+		}
+
+	case reflect.Struct:
+		return buildStructPlan()
+
+	case reflect.Interface:
+		return func(c *Converter, field reflect.Value, value string) error {
+			return c.setInterface(field, value)
+		}
+
+	default:
+		kind := typeOf.Kind()
+
+		return func(_ *Converter, _ reflect.Value, _ string) error {
+			return fmt.Errorf("%w: %s", ErrUnsupportedType, kind)
+		}
+	}
+}
+
+// numberPlan wraps a numeric setter, normalizing the value to the Go number format first.
+func numberPlan(set func(field reflect.Value, value string) error) setter {
+	return func(c *Converter, field reflect.Value, value string) error {
+		value, err := c.normalizeNumber(value)
+		if err != nil {
+			return err
+		}
+
+		return set(field, value)
+	}
+}
+
+// lazyPlan defers resolving the setter of an element type until it is first needed.
+func lazyPlan(typeOf reflect.Type) func() setter {
+	return sync.OnceValue(func() setter {
+		return planFor(typeOf)
+	})
+}
@@ -0,0 +1,78 @@
+package typeconv_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/typeconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConverter_Convert_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	c := typeconv.New()
+
+	var waitGroup sync.WaitGroup
+
+	for range 16 {
+		waitGroup.Go(func() {
+			var result []*time.Duration
+
+			err := c.Convert(reflect.ValueOf(&result).Elem(), "1s,2m")
+			assert.NoError(t, err)
+			assert.Len(t, result, 2)
+		})
+	}
+
+	waitGroup.Wait()
+}
+
+func TestConverter_Convert_RecursiveType(t *testing.T) {
+	t.Parallel()
+
+	type recursive []recursive
+
+	var result recursive
+
+	err := typeconv.New().Convert(reflect.ValueOf(&result).Elem(), "")
+	require.NoError(t, err)
+	assert.Empty(t, result)
+}
+
+func BenchmarkConverter_Convert(b *testing.B) {
+	benchmarks := []struct {
+		target any
+		name   string
+		value  string
+	}{
+		{name: "int", target: new(int), value: "42"},
+		{name: "duration", target: new(time.Duration), value: "1h30m"},
+		{name: "pointer slice", target: new([]*int), value: "1,2,3,4,5,6,7,8"},
+	}
+
+	c := typeconv.New()
+
+	for _, bm := range benchmarks {
+		target := reflect.ValueOf(bm.target).Elem()
+
+		b.Run(bm.name+"/cached", func(b *testing.B) {
+			b.ReportAllocs()
+
+			for b.Loop() {
+				_ = c.Convert(target, bm.value)
+			}
+		})
+
+		b.Run(bm.name+"/uncached", func(b *testing.B) {
+			b.ReportAllocs()
+
+			for b.Loop() {
+				_ = c.ConvertUncached(target, bm.value)
+			}
+		})
+	}
+}
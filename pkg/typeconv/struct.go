@@ -0,0 +1,178 @@
+package typeconv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// fieldsKey identifies the field metadata of a struct type for a given tag.
+type fieldsKey struct {
+	typeOf reflect.Type
+	tag    string
+}
+
+// structField describes a struct field addressable by a key in a key=value list.
+type structField struct {
+	elem  func() setter
+	index int
+}
+
+// fieldCache caches the field metadata of struct types per tag.
+//
+//nolint:gochecknoglobals // Process-wide cache of immutable field metadata.
+var fieldCache sync.Map
+
+// ConvertFields converts every value into the struct field addressed by its key. Keys are matched
+// case-insensitively against the FieldTag or the field name; nested struct fields are addressed
+// by dotted paths like "database.host". Target must be a non-nil pointer to a struct.
+// All failing fields are reported together, and the remaining fields are still set.
+func (c *Converter) ConvertFields(target any, values map[string]string) error {
+	valueOf := reflect.ValueOf(target)
+	if valueOf.Kind() != reflect.Ptr || valueOf.IsNil() || valueOf.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: target must be a non-nil pointer to struct, got %T", ErrUnsupportedType, target)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	var errs []error
+
+	for _, key := range keys {
+		err := c.convertPath(valueOf.Elem(), key, values[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("typeconv: field %s: %w", key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// convertPath resolves the dotted path below the struct and converts the value into the addressed field.
+func (c *Converter) convertPath(valueOf reflect.Value, path, value string) error {
+	name, rest, nested := strings.Cut(path, ".")
+
+	target, ok := c.fieldsOf(valueOf.Type())[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return fmt.Errorf("%w: unknown field '%s' in %s", ErrInvalidValue, name, valueOf.Type())
+	}
+
+	field := valueOf.Field(target.index)
+	if !nested {
+		return c.setField(field, value)
+	}
+
+	if field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.Struct {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+
+		field = field.Elem()
+	}
+
+	if field.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: field '%s' in %s is not a struct", ErrInvalidValue, name, valueOf.Type())
+	}
+
+	return c.convertPath(field, rest, value)
+}
+
+// fieldsOf returns the cached field metadata of the struct type for the converter's FieldTag.
+func (c *Converter) fieldsOf(typeOf reflect.Type) map[string]structField {
+	key := fieldsKey{typeOf: typeOf, tag: c.FieldTag}
+	if cached, ok := fieldCache.Load(key); ok {
+		return cached.(map[string]structField) //nolint:forcetypeassert // Only field maps are stored.
+	}
+
+	actual, _ := fieldCache.LoadOrStore(key, structFields(typeOf, c.FieldTag))
+
+	return actual.(map[string]structField) //nolint:forcetypeassert // Only field maps are stored.
+}
+
+// buildStructPlan resolves a setter for struct types. The value is either a JSON object,
+// decoded with encoding/json, or a list of key=value pairs split by SliceSeparator whose keys
+// are matched case-insensitively against the FieldTag or field name.
+func buildStructPlan() setter {
+	return func(c *Converter, field reflect.Value, value string) error {
+		value = c.trim(value)
+		if strings.HasPrefix(value, "{") {
+			err := json.Unmarshal([]byte(value), field.Addr().Interface())
+			if err != nil {
+				return fmt.Errorf("%w: cannot parse '%s' as %s: %w", ErrInvalidValue, value, field.Type(), err)
+			}
+
+			return nil
+		}
+
+		if value == "" {
+			return nil
+		}
+
+		pairs, err := c.split(value)
+		if err != nil {
+			return err
+		}
+
+		fields := c.fieldsOf(field.Type())
+		inner := c.nested()
+
+		for _, pair := range pairs {
+			key, fieldValue, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("%w: expected key=value, got '%s'", ErrInvalidValue, pair)
+			}
+
+			key = strings.ToLower(c.trim(key))
+
+			target, ok := fields[key]
+			if !ok {
+				return fmt.Errorf("%w: unknown key '%s' for %s", ErrInvalidValue, key, field.Type())
+			}
+
+			err = inner.apply(target.elem(), field.Field(target.index), c.trim(fieldValue))
+			if err != nil {
+				return fmt.Errorf("typeconv: struct field %s: %w", key, err)
+			}
+		}
+
+		return nil
+	}
+}
+
+// structFields maps the lower-case key of every exported field to its index and setter.
+// The key is taken from the given tag if present, otherwise from the field name.
+func structFields(typeOf reflect.Type, tag string) map[string]structField {
+	fields := make(map[string]structField, typeOf.NumField())
+
+	for i := range typeOf.NumField() {
+		fieldType := typeOf.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		var name string
+		if tag != "" {
+			name, _, _ = strings.Cut(fieldType.Tag.Get(tag), ",")
+		}
+
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = fieldType.Name
+		}
+
+		fields[strings.ToLower(name)] = structField{elem: lazyPlan(fieldType.Type), index: i}
+	}
+
+	return fields
+}
@@ -0,0 +1,110 @@
+package typeconv_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spacecafe/go-parts/pkg/typeconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConverter_Convert_Struct(t *testing.T) {
+	t.Parallel()
+
+	type Upstream struct {
+		Host    string `json:"host"`
+		Tags    []string
+		Port    int  `json:"port"`
+		Ignored bool `json:"-"`
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    Upstream
+		wantErr bool
+	}{
+		{
+			name:  "key value list",
+			value: "host=db, PORT=5432",
+			want:  Upstream{Host: "db", Port: 5432},
+		},
+		{
+			name:  "json object",
+			value: `{"host":"db","port":5432,"Tags":["a","b"]}`,
+			want:  Upstream{Host: "db", Port: 5432, Tags: []string{"a", "b"}},
+		},
+		{
+			name:  "empty",
+			value: "",
+			want:  Upstream{},
+		},
+		{name: "missing equals sign", value: "host", wantErr: true},
+		{name: "unknown key", value: "ignored=true", wantErr: true},
+		{name: "invalid field value", value: "port=x", wantErr: true},
+		{name: "invalid json", value: `{"port":"x"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var result *Upstream
+
+			err := typeconv.New().Convert(reflect.ValueOf(&result).Elem(), tt.value)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, typeconv.ErrInvalidValue)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, &tt.want, result)
+			}
+		})
+	}
+}
+
+func TestConverter_ConvertFields(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string `cfg:"hostname"`
+		Port int
+	}
+
+	type Config struct {
+		Database *Database
+		Name     string `cfg:"app_name"`
+		Tags     []string
+	}
+
+	c := typeconv.New()
+	c.FieldTag = "cfg"
+
+	target := &Config{}
+	err := c.ConvertFields(target, map[string]string{
+		"app_name":          "test-app",
+		"Tags":              "a,b",
+		"database.hostname": "db",
+		"DATABASE.PORT":     "5432",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Config{
+		Database: &Database{Host: "db", Port: 5432},
+		Name:     "test-app",
+		Tags:     []string{"a", "b"},
+	}, target)
+
+	err = c.ConvertFields(target, map[string]string{
+		"unknown":       "x",
+		"database.port": "x",
+		"name.part":     "x",
+		"app_name":      "changed",
+	})
+	require.ErrorIs(t, err, typeconv.ErrInvalidValue)
+	assert.ErrorContains(t, err, "field unknown")
+	assert.ErrorContains(t, err, "field database.port")
+	assert.ErrorContains(t, err, "field name.part")
+	assert.Equal(t, "changed", target.Name)
+
+	require.ErrorIs(t, c.ConvertFields(Config{}, nil), typeconv.ErrUnsupportedType)
+}
@@ -0,0 +1,89 @@
+package typeconv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Struct tags honored by ConvertWithTag.
+const (
+	// TagUnit is the unit applied to bare numbers converted into a time.Duration, e.g. `unit:"seconds"`.
+	TagUnit = "unit"
+
+	// TagLayout is the layout used for time.Time conversion, e.g. `layout:"2006-01-02"`.
+	TagLayout = "layout"
+
+	// TagSeparator is the separator used for slice, array and map values, e.g. `sep:";"`.
+	TagSeparator = "sep"
+)
+
+// ConvertWithTag converts a string value like Convert, but lets the struct tag of the field
+// override the converter settings for this conversion: the unit tag enables ExtendedDuration
+// with the given DurationUnit, the layout tag sets TimeLayout and the sep tag sets SliceSeparator.
+func (c *Converter) ConvertWithTag(field reflect.Value, value string, tag reflect.StructTag) error {
+	derived, err := c.withTag(tag)
+	if err != nil {
+		return err
+	}
+
+	return derived.Convert(field, value)
+}
+
+// withTag returns the converter itself if the tag overrides nothing, or a copy with the overrides applied.
+func (c *Converter) withTag(tag reflect.StructTag) (*Converter, error) {
+	unitName, hasUnit := tag.Lookup(TagUnit)
+	layout, hasLayout := tag.Lookup(TagLayout)
+	separator, hasSeparator := tag.Lookup(TagSeparator)
+
+	if !hasUnit && !hasLayout && !hasSeparator {
+		return c, nil
+	}
+
+	derived := *c
+
+	if hasUnit {
+		unit, err := parseUnit(unitName)
+		if err != nil {
+			return nil, err
+		}
+
+		derived.ExtendedDuration = true
+		derived.DurationUnit = unit
+	}
+
+	if hasLayout {
+		derived.TimeLayout = layout
+	}
+
+	if hasSeparator {
+		derived.SliceSeparator = separator
+	}
+
+	return &derived, nil
+}
+
+// parseUnit returns the duration of a unit given by its name or abbreviation.
+func parseUnit(name string) (time.Duration, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "ns", "nanosecond", "nanoseconds":
+		return time.Nanosecond, nil
+	case "us", "µs", "microsecond", "microseconds":
+		return time.Microsecond, nil
+	case "ms", "millisecond", "milliseconds":
+		return time.Millisecond, nil
+	case "s", "second", "seconds":
+		return time.Second, nil
+	case "m", "minute", "minutes":
+		return time.Minute, nil
+	case "h", "hour", "hours":
+		return time.Hour, nil
+	case "d", "day", "days":
+		return Day, nil
+	case "w", "week", "weeks":
+		return Week, nil
+	default:
+		return 0, fmt.Errorf("%w: unknown duration unit '%s'", ErrUnsupportedType, name)
+	}
+}
@@ -0,0 +1,53 @@
+package typeconv_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spacecafe/go-parts/pkg/typeconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConverter_ConvertWithTag(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Birthday time.Time     `layout:"2006-01-02"`
+		Hosts    []string      `sep:";"`
+		Timeout  time.Duration `unit:"seconds"`
+		Interval time.Duration
+		Invalid  time.Duration `unit:"fortnights"`
+	}
+
+	var result Config
+
+	target := reflect.ValueOf(&result).Elem()
+	typeOf := target.Type()
+	c := typeconv.New()
+
+	convert := func(name, value string) error {
+		field, _ := typeOf.FieldByName(name)
+
+		return c.ConvertWithTag(target.FieldByName(name), value, field.Tag)
+	}
+
+	require.NoError(t, convert("Birthday", "2020-02-29"))
+	require.NoError(t, convert("Hosts", "a,b;c"))
+	require.NoError(t, convert("Timeout", "30"))
+	require.NoError(t, convert("Interval", "1m"))
+	require.Error(t, convert("Interval", "30"))
+	require.ErrorIs(t, convert("Invalid", "1"), typeconv.ErrUnsupportedType)
+
+	assert.Equal(t, Config{
+		Birthday: time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC),
+		Hosts:    []string{"a,b", "c"},
+		Timeout:  30 * time.Second,
+		Interval: time.Minute,
+	}, result)
+
+	// The converter itself is not modified.
+	assert.Equal(t, ",", c.SliceSeparator)
+	assert.False(t, c.ExtendedDuration)
+}
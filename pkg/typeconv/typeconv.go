@@ -4,33 +4,111 @@
 package typeconv
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math/big"
 	"reflect"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultBigFloatPrecision is the big.Float precision in bits used if none is configured.
+	DefaultBigFloatPrecision = 256
+
+	// Day is the duration of a calendar day without regard to daylight saving time.
+	Day = 24 * time.Hour
+
+	// Week is the duration of seven days.
+	Week = 7 * Day
 )
 
 var (
 	ErrUnsupportedType = errors.New("typeconv: unsupported type")
 	ErrInvalidValue    = errors.New("typeconv: invalid value")
+	ErrInvalidDuration = errors.New("typeconv: invalid duration")
 )
 
 // Converter handles conversion of string values to various Go types.
 type Converter struct {
-	// SliceSeparator is the string used to split slice values. Default is ",".
+	// SliceSeparator is the string used to split slice, array and map values. Default is ",".
 	SliceSeparator string
 
+	// NestedSeparator is the string used to split the elements of a collection that are collections
+	// themselves, e.g. "a|b,c|d" into [][]string or "web=a|b,db=c" into map[string][]string.
+	// Only two levels of nesting are supported. Default is "|".
+	NestedSeparator string
+
+	// FieldTag is the struct tag whose name matches keys to struct fields in key=value lists and
+	// ConvertFields. If empty, or if a field has no such tag, the field name is matched. Default is "json".
+	FieldTag string
+
 	// TimeLayout is the layout used for time.Time conversion. Default is time.RFC3339.
 	TimeLayout string
+
+	// InferTypes lists the candidate types tried in order when converting into an empty interface.
+	// If empty, the built-in inference of InferValue is used. Default is nil.
+	InferTypes []reflect.Type
+
+	// NilValues lists the values that set a pointer target to nil instead of allocating a zero value,
+	// e.g. []string{"", "null", "nil"} to express an explicitly unset *int or *bool. Values are matched
+	// case-insensitively unless Strict is enabled. Default is nil, which always allocates.
+	NilValues []string
+
+	// enums maps string types to their allowed values, see RegisterEnum.
+	enums map[reflect.Type][]string
+
+	// hooks run before every conversion, see AddHook.
+	hooks []Hook
+
+	// postHooks run after every conversion, see AddPostHook.
+	postHooks []PostHook
+
+	// BigFloatPrecision is the mantissa precision in bits used for big.Float conversion.
+	// If zero, DefaultBigFloatPrecision is used.
+	BigFloatPrecision uint
+
+	// DurationUnit is the unit applied to bare numbers when ExtendedDuration is enabled.
+	// If zero, bare numbers other than "0" are rejected. Default is 0.
+	DurationUnit time.Duration
+
+	// DecimalComma accepts numbers in the format common in continental Europe, with "," as the
+	// decimal separator and "." as the optional thousands separator, e.g. "1.000.000,5".
+	// Choose a SliceSeparator other than "," when enabled. Default is false.
+	DecimalComma bool
+
+	// QuotedElements splits slice, array and key=value list values with encoding/csv semantics,
+	// so elements may contain the separator when enclosed in double quotes, e.g. `"a,b",c`.
+	// SliceSeparator must then be a single character. Default is false.
+	QuotedElements bool
+
+	// PadArrays allows array values with fewer elements than the array length; the remaining
+	// elements are left at their zero value. More elements than the length is always an error.
+	// Default is false.
+	PadArrays bool
+
+	// ExtendedDuration enables the "d" (day) and "w" (week) suffixes for time.Duration
+	// conversion in addition to the units understood by time.ParseDuration. Default is false.
+	ExtendedDuration bool
+
+	// Strict disables lenient parsing: surrounding whitespace is not trimmed, bools only accept
+	// "true" and "false", and an empty value is an error for slices instead of an empty slice.
+	// Default is false.
+	Strict bool
 }
 
 // New creates a new Converter with default settings.
 func New() *Converter {
 	return &Converter{
-		SliceSeparator: ",",
-		TimeLayout:     time.RFC3339,
+		SliceSeparator:  ",",
+		NestedSeparator: "|",
+		FieldTag:        "json",
+		TimeLayout:      time.RFC3339,
 	}
 }
 
@@ -67,6 +145,16 @@ func ConvertTo[T any](value string) (T, error) {
 	return result, nil
 }
 
+// ConvertSliceTo converts a separated list into a slice of T using the Default converter.
+func ConvertSliceTo[T any](value string) ([]T, error) {
+	return ConvertTo[[]T](value)
+}
+
+// ConvertMapTo converts a list of key=value pairs into a map of K to V using the Default converter.
+func ConvertMapTo[K comparable, V any](value string) (map[K]V, error) {
+	return ConvertTo[map[K]V](value)
+}
+
 // MustConvertTo is like ConvertTo but panics on error.
 //
 //nolint:ireturn // Generic function must return type parameter T.
@@ -79,76 +167,205 @@ func MustConvertTo[T any](value string) T {
 	return result
 }
 
-// setField sets the field value from the string.
-func (c *Converter) setField(field reflect.Value, value string) error {
-	if field.Type() == reflect.TypeFor[time.Duration]() {
-		return setDuration(field, value)
+// trim removes surrounding whitespace unless the converter is strict.
+func (c *Converter) trim(value string) string {
+	if c.Strict {
+		return value
 	}
 
-	if field.Type() == reflect.TypeFor[time.Time]() {
-		return setTime(field, value, c.TimeLayout)
+	return strings.TrimSpace(value)
+}
+
+// isNilValue reports whether the value is one of the configured NilValues.
+func (c *Converter) isNilValue(value string) bool {
+	value = c.trim(value)
+
+	for _, nilValue := range c.NilValues {
+		if value == nilValue || (!c.Strict && strings.EqualFold(value, nilValue)) {
+			return true
+		}
 	}
 
-	//nolint:exhaustive // Only handling supported reflect.Kind types; unsupported types handled by default case.
-	switch field.Kind() {
-	case reflect.String:
-		field.SetString(value)
+	return false
+}
 
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return setInt(field, value)
+// nested returns a copy of the converter that splits collections with the NestedSeparator.
+// The copy has no NestedSeparator itself, so a third level of nesting is rejected.
+func (c *Converter) nested() *Converter {
+	inner := *c
+	inner.SliceSeparator = c.NestedSeparator
+	inner.NestedSeparator = ""
 
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return setUint(field, value)
+	return &inner
+}
 
-	case reflect.Float32, reflect.Float64:
-		return setFloat(field, value)
+// normalizeNumber converts a number in the configured format into the format understood by strconv.
+func (c *Converter) normalizeNumber(value string) (string, error) {
+	if !c.DecimalComma {
+		return value, nil
+	}
 
-	case reflect.Bool:
-		return setBool(field, value)
+	value = c.trim(value)
+	integer, fraction, hasFraction := strings.Cut(value, ",")
 
-	case reflect.Ptr:
-		if field.IsNil() {
-			field.Set(reflect.New(field.Type().Elem()))
+	groups := strings.Split(strings.TrimLeft(integer, "+-"), ".")
+	for i, group := range groups {
+		if (i == 0 && len(groups) > 1 && (group == "" || len(group) > 3)) || (i > 0 && len(group) != 3) {
+			return "", fmt.Errorf("%w: invalid digit grouping in '%s'", ErrInvalidValue, value)
 		}
+	}
+
+	if strings.ContainsAny(fraction, ",.") {
+		return "", fmt.Errorf("%w: invalid decimal separator in '%s'", ErrInvalidValue, value)
+	}
+
+	result := strings.ReplaceAll(integer, ".", "")
+	if hasFraction {
+		result += "." + fraction
+	}
+
+	return result, nil
+}
+
+// setArray handles fixed-size array conversion by splitting the value and converting each element.
+// The number of elements must match the array length unless PadArrays is enabled.
+func (c *Converter) setArray(field reflect.Value, value string, elem setter) error {
+	var parts []string
+
+	if value != "" {
+		var err error
+
+		parts, err = c.split(value)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(parts) > field.Len() || (!c.PadArrays && len(parts) != field.Len()) {
+		return fmt.Errorf(
+			"%w: got %d elements for %s",
+			ErrInvalidValue,
+			len(parts),
+			field.Type(),
+		)
+	}
+
+	array := reflect.New(field.Type()).Elem()
+
+	err := c.setElements(array, parts, "array", elem)
+	if err != nil {
+		return err
+	}
+
+	field.Set(array)
+
+	return nil
+}
+
+// setElements converts each part into the corresponding element of the slice or array.
+// All failing elements are reported together.
+func (c *Converter) setElements(list reflect.Value, parts []string, kind string, elem setter) error {
+	inner := c.nested()
+
+	var errs []error
+
+	for i, part := range parts {
+		// Pointer element types are allocated by their setter.
+		err := inner.apply(elem, list.Index(i), c.trim(part))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("typeconv: %s element %d: %w", kind, i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// setField sets the field value from the string using the cached plan of its type.
+func (c *Converter) setField(field reflect.Value, value string) error {
+	return c.apply(planFor(field.Type()), field, value)
+}
+
+// setMap handles map conversion by splitting the value into key=value pairs and converting each key and value.
+// All failing entries are reported together.
+func (c *Converter) setMap(field reflect.Value, value string, key, elem setter) error {
+	result := reflect.MakeMap(field.Type())
+
+	if value != "" || c.Strict {
+		pairs, err := c.split(value)
+		if err != nil {
+			return err
+		}
+
+		inner := c.nested()
+
+		var errs []error
+
+		for _, pair := range pairs {
+			err = c.setMapEntry(result, pair, inner, key, elem)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+	}
+
+	field.Set(result)
+
+	return nil
+}
 
-		return c.setField(field.Elem(), value)
+// setMapEntry converts a single key=value pair and stores it in the map.
+func (c *Converter) setMapEntry(result reflect.Value, pair string, inner *Converter, key, elem setter) error {
+	rawKey, rawValue, ok := strings.Cut(pair, "=")
+	if !ok {
+		return fmt.Errorf("%w: expected key=value, got '%s'", ErrInvalidValue, pair)
+	}
+
+	mapKey := reflect.New(result.Type().Key()).Elem()
+
+	err := c.apply(key, mapKey, c.trim(rawKey))
+	if err != nil {
+		return fmt.Errorf("typeconv: map key %s: %w", rawKey, err)
+	}
 
-	case reflect.Slice:
-		return c.setSlice(field, value)
+	mapValue := reflect.New(result.Type().Elem()).Elem()
 
-	default:
-		return fmt.Errorf("%w: %s", ErrUnsupportedType, field.Kind())
+	err = inner.apply(elem, mapValue, c.trim(rawValue))
+	if err != nil {
+		return fmt.Errorf("typeconv: map value %s: %w", rawKey, err)
 	}
 
+	result.SetMapIndex(mapKey, mapValue)
+
 	return nil
 }
 
 // setSlice handles slice conversion by splitting the value and converting each element.
-func (c *Converter) setSlice(field reflect.Value, value string) error {
+func (c *Converter) setSlice(field reflect.Value, value string, elem setter) error {
 	if value == "" {
+		if c.Strict {
+			return fmt.Errorf("%w: empty value for %s", ErrInvalidValue, field.Type())
+		}
+
 		// Empty string creates an empty slice.
 		field.Set(reflect.MakeSlice(field.Type(), 0, 0))
 
 		return nil
 	}
 
-	parts := strings.Split(value, c.SliceSeparator)
-	slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
-
-	for i, part := range parts {
-		part = strings.TrimSpace(part)
-		elem := slice.Index(i)
+	parts, err := c.split(value)
+	if err != nil {
+		return err
+	}
 
-		// For pointer element types, create a new instance.
-		if elem.Kind() == reflect.Ptr {
-			elem.Set(reflect.New(elem.Type().Elem()))
-			elem = elem.Elem()
-		}
+	slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
 
-		err := c.setField(elem, part)
-		if err != nil {
-			return fmt.Errorf("typeconv: slice element %d: %w", i, err)
-		}
+	err = c.setElements(slice, parts, "slice", elem)
+	if err != nil {
+		return err
 	}
 
 	field.Set(slice)
@@ -156,7 +373,47 @@ func (c *Converter) setSlice(field reflect.Value, value string) error {
 	return nil
 }
 
-func setBool(field reflect.Value, value string) error {
+// split splits a list value into its elements, honoring quotes if QuotedElements is enabled.
+func (c *Converter) split(value string) ([]string, error) {
+	if c.SliceSeparator == "" {
+		return nil, fmt.Errorf("%w: no separator configured for '%s'", ErrUnsupportedType, value)
+	}
+
+	if !c.QuotedElements {
+		return strings.Split(value, c.SliceSeparator), nil
+	}
+
+	comma, size := utf8.DecodeRuneInString(c.SliceSeparator)
+	if size == 0 || size != len(c.SliceSeparator) {
+		return nil, fmt.Errorf(
+			"%w: quoted elements require a single character separator, got '%s'",
+			ErrUnsupportedType,
+			c.SliceSeparator,
+		)
+	}
+
+	reader := csv.NewReader(strings.NewReader(value))
+	reader.Comma = comma
+	reader.TrimLeadingSpace = !c.Strict
+
+	parts, err := reader.Read()
+	if err == nil {
+		_, err = reader.Read()
+		if errors.Is(err, io.EOF) {
+			return parts, nil
+		}
+
+		err = fmt.Errorf("%w: more than one record", csv.ErrFieldCount)
+	}
+
+	return nil, fmt.Errorf("%w: cannot split '%s': %w", ErrInvalidValue, value, err)
+}
+
+func setBool(field reflect.Value, value string, strict bool) error {
+	if strict {
+		return setStrictBool(field, value)
+	}
+
 	value = strings.ToLower(strings.TrimSpace(value))
 	switch value {
 	case "1", "t", "y", "true", "yes", "on":
@@ -172,6 +429,21 @@ func setBool(field reflect.Value, value string) error {
 	return fmt.Errorf("%w: cannot parse '%s' as bool", ErrInvalidValue, value)
 }
 
+func setStrictBool(field reflect.Value, value string) error {
+	switch value {
+	case "true":
+		field.SetBool(true)
+
+		return nil
+	case "false":
+		field.SetBool(false)
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: cannot parse '%s' as bool, expected 'true' or 'false'", ErrInvalidValue, value)
+}
+
 func setFloat(field reflect.Value, value string) error {
 	floatVal, err := strconv.ParseFloat(value, field.Type().Bits())
 	if err != nil {
@@ -228,6 +500,17 @@ func setDuration(field reflect.Value, value string) error {
 	return nil
 }
 
+func setExtendedDuration(field reflect.Value, value string, unit time.Duration) error {
+	durationVal, err := ParseExtendedDuration(value, unit)
+	if err != nil {
+		return fmt.Errorf("%w: cannot parse '%s' as duration: %w", ErrInvalidValue, value, err)
+	}
+
+	field.SetInt(int64(durationVal))
+
+	return nil
+}
+
 func setTime(field reflect.Value, value, layout string) error {
 	timeVal, err := time.Parse(layout, value)
 	if err != nil {
@@ -238,3 +521,51 @@ func setTime(field reflect.Value, value, layout string) error {
 
 	return nil
 }
+
+func setBigInt(field reflect.Value, value string) error {
+	intVal, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return fmt.Errorf("%w: cannot parse '%s' as big.Int", ErrInvalidValue, value)
+	}
+
+	field.Set(reflect.ValueOf(intVal).Elem())
+
+	return nil
+}
+
+func setBigFloat(field reflect.Value, value string, precision uint) error {
+	if precision == 0 {
+		precision = DefaultBigFloatPrecision
+	}
+
+	floatVal, _, err := big.ParseFloat(value, 10, precision, big.ToNearestEven)
+	if err != nil {
+		return fmt.Errorf("%w: cannot parse '%s' as big.Float: %w", ErrInvalidValue, value, err)
+	}
+
+	field.Set(reflect.ValueOf(floatVal).Elem())
+
+	return nil
+}
+
+func setBigRat(field reflect.Value, value string) error {
+	ratVal, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return fmt.Errorf("%w: cannot parse '%s' as big.Rat", ErrInvalidValue, value)
+	}
+
+	field.Set(reflect.ValueOf(ratVal).Elem())
+
+	return nil
+}
+
+func setJSONNumber(field reflect.Value, value string) error {
+	// A JSON number starts with a minus sign or digit; this excludes strings, literals and objects.
+	if value == "" || (value[0] != '-' && (value[0] < '0' || value[0] > '9')) || !json.Valid([]byte(value)) {
+		return fmt.Errorf("%w: cannot parse '%s' as json.Number", ErrInvalidValue, value)
+	}
+
+	field.SetString(value)
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package typeconv_test
 
 import (
+	"encoding/json"
+	"math/big"
 	"reflect"
 	"testing"
 	"time"
@@ -525,11 +527,7 @@ func TestConverter_Convert_NotSettable(t *testing.T) {
 func TestConverter_Convert_UnsupportedType(t *testing.T) {
 	t.Parallel()
 
-	type CustomStruct struct {
-		Field string
-	}
-
-	var result CustomStruct
+	var result chan int
 
 	target := reflect.ValueOf(&result).Elem()
 
@@ -582,6 +580,28 @@ func TestConvertTo(t *testing.T) {
 	})
 }
 
+func TestConvertSliceTo(t *testing.T) {
+	t.Parallel()
+
+	result, err := typeconv.ConvertSliceTo[int]("1, 2, 3")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, result)
+
+	_, err = typeconv.ConvertSliceTo[int]("1,x")
+	assert.ErrorIs(t, err, typeconv.ErrInvalidValue)
+}
+
+func TestConvertMapTo(t *testing.T) {
+	t.Parallel()
+
+	result, err := typeconv.ConvertMapTo[string, time.Duration]("read=5s, write=1m")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{"read": 5 * time.Second, "write": time.Minute}, result)
+
+	_, err = typeconv.ConvertMapTo[string, int]("a=x")
+	assert.ErrorIs(t, err, typeconv.ErrInvalidValue)
+}
+
 func TestMustConvertTo(t *testing.T) {
 	t.Parallel()
 
@@ -607,6 +627,8 @@ func TestNew(t *testing.T) {
 	c := typeconv.New()
 	assert.NotNil(t, c)
 	assert.Equal(t, ",", c.SliceSeparator)
+	assert.Equal(t, "|", c.NestedSeparator)
+	assert.Equal(t, "json", c.FieldTag)
 	assert.Equal(t, time.RFC3339, c.TimeLayout)
 }
 
@@ -617,3 +639,330 @@ func TestDefault(t *testing.T) {
 	assert.Equal(t, ",", typeconv.Default.SliceSeparator)
 	assert.Equal(t, time.RFC3339, typeconv.Default.TimeLayout)
 }
+
+func TestConverter_Convert_Array(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		value     string
+		want      [3]int
+		padArrays bool
+		wantErr   bool
+	}{
+		{name: "exact length", value: "1, 2, 3", want: [3]int{1, 2, 3}},
+		{name: "too few elements", value: "1,2", wantErr: true},
+		{name: "too many elements", value: "1,2,3,4", wantErr: true},
+		{name: "too few elements with padding", value: "1,2", want: [3]int{1, 2, 0}, padArrays: true},
+		{name: "too many elements with padding", value: "1,2,3,4", padArrays: true, wantErr: true},
+		{name: "empty with padding", value: "", padArrays: true},
+		{name: "invalid element", value: "1,x,3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var result [3]int
+
+			target := reflect.ValueOf(&result).Elem()
+
+			c := typeconv.New()
+			c.PadArrays = tt.padArrays
+
+			err := c.Convert(target, tt.value)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, typeconv.ErrInvalidValue)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, result)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_Big(t *testing.T) {
+	t.Parallel()
+
+	t.Run("big.Int", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := typeconv.ConvertTo[*big.Int]("123456789012345678901234567890")
+		require.NoError(t, err)
+		assert.Equal(t, "123456789012345678901234567890", result.String())
+
+		_, err = typeconv.ConvertTo[*big.Int]("1.5")
+		assert.ErrorIs(t, err, typeconv.ErrInvalidValue)
+	})
+
+	t.Run("big.Float", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := typeconv.ConvertTo[*big.Float]("0.1000000000000000000001")
+		require.NoError(t, err)
+		assert.Equal(t, uint(typeconv.DefaultBigFloatPrecision), result.Prec())
+		assert.Equal(t, "0.1000000000000000000001", result.Text('f', 22))
+
+		_, err = typeconv.ConvertTo[*big.Float]("abc")
+		assert.ErrorIs(t, err, typeconv.ErrInvalidValue)
+	})
+
+	t.Run("big.Rat", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := typeconv.ConvertTo[big.Rat]("3/4")
+		require.NoError(t, err)
+		assert.Equal(t, "3/4", result.String())
+
+		_, err = typeconv.ConvertTo[big.Rat]("3/x")
+		assert.ErrorIs(t, err, typeconv.ErrInvalidValue)
+	})
+
+	t.Run("json.Number", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := typeconv.ConvertTo[json.Number]("-12.50e3")
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("-12.50e3"), result)
+
+		for _, value := range []string{"", "true", `"1"`, "1.", "0x10"} {
+			_, err = typeconv.ConvertTo[json.Number](value)
+			assert.ErrorIs(t, err, typeconv.ErrInvalidValue, value)
+		}
+	})
+}
+
+func TestConverter_Convert_QuotedElements(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		value     string
+		separator string
+		want      []string
+		wantErr   bool
+	}{
+		{name: "quoted separator", value: `"a,b", c`, separator: ",", want: []string{"a,b", "c"}},
+		{name: "escaped quote", value: `"say ""hi""";x`, separator: ";", want: []string{`say "hi"`, "x"}},
+		{name: "unterminated quote", value: `"a,b`, separator: ",", wantErr: true},
+		{name: "multiple records", value: "a\nb", separator: ",", wantErr: true},
+		{name: "multi character separator", value: "a::b", separator: "::", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var result []string
+
+			c := typeconv.New()
+			c.SliceSeparator = tt.separator
+			c.QuotedElements = true
+
+			err := c.Convert(reflect.ValueOf(&result).Elem(), tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, result)
+			}
+		})
+	}
+
+	t.Run("array", func(t *testing.T) {
+		t.Parallel()
+
+		var result [2]string
+
+		c := typeconv.New()
+		c.QuotedElements = true
+
+		require.NoError(t, c.Convert(reflect.ValueOf(&result).Elem(), `"x,y",z`))
+		assert.Equal(t, [2]string{"x,y", "z"}, result)
+	})
+}
+
+func TestConverter_Convert_DecimalComma(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		target  any
+		want    any
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "decimal comma", value: "3,14", target: new(float64), want: 3.14},
+		{name: "grouped float", value: "-1.000.000,5", target: new(float64), want: -1000000.5},
+		{name: "grouped int", value: "1.234.567", target: new(int), want: 1234567},
+		{name: "plain uint", value: "42", target: new(uint), want: uint(42)},
+		{name: "fraction for int", value: "1,5", target: new(int), wantErr: true},
+		{name: "invalid grouping", value: "1.00", target: new(int), wantErr: true},
+		{name: "leading separator", value: ".100", target: new(int), wantErr: true},
+		{name: "multiple decimal commas", value: "1,2,3", target: new(float64), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := typeconv.New()
+			c.DecimalComma = true
+
+			err := c.Convert(reflect.ValueOf(tt.target).Elem(), tt.value)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, typeconv.ErrInvalidValue)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, reflect.ValueOf(tt.target).Elem().Interface())
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_Strict(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		target  any
+		want    any
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "canonical true", value: "true", target: new(bool), want: true},
+		{name: "canonical false", value: "false", target: new(bool), want: false},
+		{name: "bool synonym", value: "yes", target: new(bool), wantErr: true},
+		{name: "uppercase bool", value: "TRUE", target: new(bool), wantErr: true},
+		{name: "bool with spaces", value: " true", target: new(bool), wantErr: true},
+		{name: "untrimmed string", value: " a ", target: new(string), want: " a "},
+		{name: "slice elements", value: "1,2", target: new([]int), want: []int{1, 2}},
+		{name: "untrimmed slice element", value: "1, 2", target: new([]int), wantErr: true},
+		{name: "empty slice", value: "", target: new([]int), wantErr: true},
+		{name: "untrimmed big int", value: " 1", target: new(big.Int), wantErr: true},
+		{name: "untrimmed struct key", value: " name=x", target: new(struct{ Name string }), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := typeconv.New()
+			c.Strict = true
+
+			err := c.Convert(reflect.ValueOf(tt.target).Elem(), tt.value)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, typeconv.ErrInvalidValue)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, reflect.ValueOf(tt.target).Elem().Interface())
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_NilValues(t *testing.T) {
+	t.Parallel()
+
+	c := typeconv.New()
+	c.NilValues = []string{"", "null", "nil"}
+
+	for _, value := range []string{"", "null", " NIL "} {
+		result := new(int)
+
+		require.NoError(t, c.Convert(reflect.ValueOf(&result).Elem(), value))
+		assert.Nil(t, result, value)
+	}
+
+	var result []*bool
+
+	require.NoError(t, c.Convert(reflect.ValueOf(&result).Elem(), "true,null,false"))
+	require.Len(t, result, 3)
+	assert.True(t, *result[0])
+	assert.Nil(t, result[1])
+	assert.False(t, *result[2])
+
+	var number *int
+
+	require.NoError(t, c.Convert(reflect.ValueOf(&number).Elem(), "0"))
+	require.NotNil(t, number)
+	assert.Equal(t, 0, *number)
+}
+
+func TestConverter_Convert_Nested(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		target  any
+		want    any
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{
+			name:   "slice of slices",
+			value:  "a|b, c|d, e",
+			target: new([][]string),
+			want:   [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+		},
+		{
+			name:   "map of slices",
+			value:  "web=1|2, db=3",
+			target: new(map[string][]int),
+			want:   map[string][]int{"web": {1, 2}, "db": {3}},
+		},
+		{
+			name:   "map of scalars",
+			value:  "a=true,b=false",
+			target: new(map[string]bool),
+			want:   map[string]bool{"a": true, "b": false},
+		},
+		{
+			name:   "empty map",
+			value:  "",
+			target: new(map[int]string),
+			want:   map[int]string{},
+		},
+		{name: "array of slices", value: "1|2,3", target: new([2][]int), want: [2][]int{{1, 2}, {3}}},
+		{name: "invalid map key", value: "x=1", target: new(map[int]int), wantErr: true},
+		{name: "invalid map value", value: "1=x", target: new(map[int]int), wantErr: true},
+		{name: "missing map value", value: "1", target: new(map[int]int), wantErr: true},
+		{name: "invalid nested element", value: "1|x", target: new([][]int), wantErr: true},
+		{name: "third level", value: "a", target: new([][][]string), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := typeconv.New().Convert(reflect.ValueOf(tt.target).Elem(), tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, reflect.ValueOf(tt.target).Elem().Interface())
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_AggregateErrors(t *testing.T) {
+	t.Parallel()
+
+	var slice []int
+
+	err := typeconv.New().Convert(reflect.ValueOf(&slice).Elem(), "1,a,3,b")
+	require.ErrorIs(t, err, typeconv.ErrInvalidValue)
+	assert.ErrorContains(t, err, "slice element 1")
+	assert.ErrorContains(t, err, "slice element 3")
+	assert.Nil(t, slice)
+
+	var mapping map[string]int
+
+	err = typeconv.New().Convert(reflect.ValueOf(&mapping).Elem(), "a=1,b=x,c,d=y")
+	require.ErrorIs(t, err, typeconv.ErrInvalidValue)
+	assert.ErrorContains(t, err, "map value b")
+	assert.ErrorContains(t, err, "expected key=value, got 'c'")
+	assert.ErrorContains(t, err, "map value d")
+	assert.Nil(t, mapping)
+}